@@ -0,0 +1,125 @@
+// Package cache keeps values that are costly to retrieve, such as token metadata and quotes, on disk between runs.
+//
+// Caching is only an optimization: a cache that cannot be read starts out empty, and being unable to cache a value
+// never fails the resolution of that value or the sync as a whole. Values describing contracts are cached by the ID of
+// their chain rather than by its name, as chain names are only labels for RPC configurations.
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// File is a cache of JSON-serializable values that is kept in a file between runs.
+// Each value may expire after a time to live; values stored without one never expire.
+type File struct {
+	path string // the location of the file in which the cache is kept; if blank, the cache is not kept on disk
+
+	entriesMutex sync.Mutex
+	entries      map[string]fileEntry
+}
+
+// LoadFile loads the cache kept in the file at the given location.
+// A file that does not exist or cannot be read results in an empty cache.
+func LoadFile(path string) *File {
+	file := &File{
+		path:    path,
+		entries: make(map[string]fileEntry),
+	}
+
+	if path == "" {
+		return file
+	}
+
+	fileBytes, err := os.ReadFile(path)
+	if err != nil {
+		return file
+	}
+
+	var entries map[string]fileEntry
+	if unmarshalErr := json.Unmarshal(fileBytes, &entries); unmarshalErr != nil {
+		return file
+	}
+
+	for key, entry := range entries {
+		if !entry.isExpired() {
+			file.entries[key] = entry
+		}
+	}
+
+	return file
+}
+
+// Get reads the value cached under the given key into the given pointer.
+// The returned bool is false if no value is cached under the key, or if the cached value has expired or cannot be read into the pointer.
+func (f *File) Get(key string, value any) bool {
+	f.entriesMutex.Lock()
+	entry, hasEntry := f.entries[key]
+	f.entriesMutex.Unlock()
+
+	if !hasEntry || entry.isExpired() {
+		return false
+	}
+
+	return json.Unmarshal(entry.Value, value) == nil
+}
+
+// Set caches the given value under the given key for the given time to live; if the time to live is zero, the value never expires.
+func (f *File) Set(key string, value any, ttl time.Duration) error {
+	valueBytes, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal value for key '%s': %w", key, err)
+	}
+
+	entry := fileEntry{
+		Value: valueBytes,
+	}
+	if ttl > 0 {
+		expiresAt := time.Now().Add(ttl)
+		entry.ExpiresAt = &expiresAt
+	}
+
+	f.entriesMutex.Lock()
+	defer f.entriesMutex.Unlock()
+
+	f.entries[key] = entry
+
+	return nil
+}
+
+// Save writes the cache to its file.
+func (f *File) Save() error {
+	if f.path == "" {
+		return nil
+	}
+
+	f.entriesMutex.Lock()
+	fileBytes, err := json.Marshal(f.entries)
+	f.entriesMutex.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(f.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory for cache file '%s': %w", f.path, err)
+	}
+
+	if err := os.WriteFile(f.path, fileBytes, 0o644); err != nil {
+		return fmt.Errorf("failed to write cache file '%s': %w", f.path, err)
+	}
+
+	return nil
+}
+
+type fileEntry struct {
+	Value     json.RawMessage `json:"value"`
+	ExpiresAt *time.Time      `json:"expires_at,omitempty"` // nil if the value never expires
+}
+
+func (e fileEntry) isExpired() bool {
+	return e.ExpiresAt != nil && time.Now().After(*e.ExpiresAt)
+}
@@ -0,0 +1,65 @@
+package cache_test
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jrh3k5/cryptonabber-sync/v3/cache"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("File", func() {
+	var cacheFile string
+
+	BeforeEach(func() {
+		cacheFile = filepath.Join(GinkgoT().TempDir(), "cache", "cache.json")
+	})
+
+	It("keeps values between loads", func() {
+		file := cache.LoadFile(cacheFile)
+		Expect(file.Set("forever", 18, 0)).To(Succeed(), "caching a value without expiry should not fail")
+		Expect(file.Set("briefly", "0xabcd", time.Hour)).To(Succeed(), "caching a value with expiry should not fail")
+		Expect(file.Save()).To(Succeed(), "saving the cache should not fail")
+
+		reloaded := cache.LoadFile(cacheFile)
+
+		var forever int
+		Expect(reloaded.Get("forever", &forever)).To(BeTrue(), "the value without expiry should be cached")
+		Expect(forever).To(Equal(18), "the value without expiry should be read back")
+
+		var briefly string
+		Expect(reloaded.Get("briefly", &briefly)).To(BeTrue(), "the unexpired value should be cached")
+		Expect(briefly).To(Equal("0xabcd"), "the unexpired value should be read back")
+
+		var missing string
+		Expect(reloaded.Get("missing", &missing)).To(BeFalse(), "no value should be cached under an unknown key")
+	})
+
+	It("does not give expired values", func() {
+		file := cache.LoadFile(cacheFile)
+		Expect(file.Set("expired", "stale", time.Nanosecond)).To(Succeed(), "caching the value should not fail")
+		time.Sleep(time.Millisecond)
+
+		var expired string
+		Expect(file.Get("expired", &expired)).To(BeFalse(), "the expired value should not be given")
+	})
+
+	It("starts out empty if the file cannot be read", func() {
+		Expect(os.MkdirAll(filepath.Dir(cacheFile), 0o755)).To(Succeed(), "the cache directory should be creatable")
+		Expect(os.WriteFile(cacheFile, []byte("not JSON"), 0o644)).To(Succeed(), "the cache file should be writable")
+
+		var value string
+		Expect(cache.LoadFile(cacheFile).Get("anything", &value)).To(BeFalse(), "the malformed file should be ignored")
+	})
+
+	It("is not kept on disk without a file", func() {
+		file := cache.LoadFile("")
+		Expect(file.Set("key", "value", 0)).To(Succeed(), "caching the value should not fail")
+		Expect(file.Save()).To(Succeed(), "saving the cache should do nothing")
+
+		var value string
+		Expect(file.Get("key", &value)).To(BeTrue(), "the value should still be cached in memory")
+	})
+})
@@ -4,33 +4,76 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io/fs"
 	"math"
 	"math/big"
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 	"time"
 
 	"github.com/davidsteinsland/ynab-go/ynab"
+	"github.com/jrh3k5/cryptonabber-sync/v3/cache"
 	"github.com/jrh3k5/cryptonabber-sync/v3/coingecko"
 	"github.com/jrh3k5/cryptonabber-sync/v3/config"
+	priceconfig "github.com/jrh3k5/cryptonabber-sync/v3/config/price"
 	rpcconfig "github.com/jrh3k5/cryptonabber-sync/v3/config/rpc"
 	"github.com/jrh3k5/cryptonabber-sync/v3/evm"
+	"github.com/jrh3k5/cryptonabber-sync/v3/http/json/rpc"
+	"github.com/jrh3k5/cryptonabber-sync/v3/money"
+	"github.com/jrh3k5/cryptonabber-sync/v3/price"
 	"github.com/jrh3k5/cryptonabber-sync/v3/token"
 	"github.com/jrh3k5/cryptonabber-sync/v3/token/balance"
 	"github.com/jrh3k5/oauth-cli/pkg/auth"
 )
 
+const (
+	coingeckoMaxRetries        = 5               // the number of times a rate-limited or failed Coingecko request is retried
+	coingeckoInitialRetryDelay = 2 * time.Second // the delay before the first retry of a Coingecko request, if Coingecko does not specify one
+)
+
+// defaultAssetPlatformCacheTTL is how long the list of Coingecko asset platforms is cached on disk if no other duration is configured.
+const defaultAssetPlatformCacheTTL = 7 * 24 * time.Hour
+
+// defaultQuoteCacheTTL is how long quotes are cached on disk if no other duration is configured.
+const defaultQuoteCacheTTL = 5 * time.Minute
+
+// rateChangeFlagColor is the color of the flag given to an adjustment made despite an unexpected change in the account's rate.
+const rateChangeFlagColor = "red"
+
+// multicallBatchWindow is how long onchain reads are collected before being executed together in a single Multicall3 call.
+const multicallBatchWindow = 50 * time.Millisecond
+
 func main() {
 	ctx := context.Background()
 
-	dryRun := dryRunEnabled()
+	dryRun := flagEnabled("--dry-run")
 	if dryRun {
 		fmt.Println("Dry run is enabled; no writes will be made to YNAB")
 	}
 
+	assetPlatformCacheFile := getCacheFile("coingecko_asset_platforms.json")
+	cacheFile := getCacheFile("cache.json")
+
+	if flagEnabled("--clear-cache") {
+		fmt.Println("Clearing cached token metadata and quotes")
+		for _, fileToClear := range []string{assetPlatformCacheFile, cacheFile} {
+			if err := os.Remove(fileToClear); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				panic(fmt.Sprintf("failed to clear cache file '%s': %v", fileToClear, err))
+			}
+		}
+	}
+
+	useCache := !flagEnabled("--no-cache")
+	if !useCache {
+		fmt.Println("Caching is disabled; token metadata and quotes will not be read from or written to the cache")
+		assetPlatformCacheFile = ""
+	}
+	fileCache := cache.LoadFile(cacheFile)
+
 	oauthToken, err := auth.DefaultGetOAuthToken(ctx,
 		"https://app.ynab.com/oauth/authorize",
 		"https://api.ynab.com/oauth/token")
@@ -61,6 +104,9 @@ func main() {
 		panic(fmt.Sprintf("failed to get budget: %v", err))
 	}
 
+	currency := getCurrency(syncConfig.Currency, budget)
+	fmt.Printf("Valuing balances in %s\n", currency.Code)
+
 	categoryGroups, err := ynabClient.CategoriesService.List(budget.Id)
 	if err != nil {
 		panic(fmt.Sprintf("failed to list categories: %v", err))
@@ -73,185 +119,312 @@ func main() {
 		panic("no accounts found in budget")
 	}
 
-	coingeckoQuoteResolver := coingecko.NewHTTPQuoteResolver(httpClient)
-	assetPlatformIDResolver := coingecko.NewSimpleAssetPlatformIDResolver()
-	rpcConfigurationResolver := rpcconfig.NewDefaultConfigurationResolver(syncConfig.RPCConfigurations)
+	coingeckoPlan, err := syncConfig.Coingecko.Plan()
+	if err != nil {
+		panic(fmt.Sprintf("invalid Coingecko configuration: %v", err))
+	}
 
-	erc20BalanceFetcher := balance.NewERC20Fetcher(rpcConfigurationResolver, httpClient)
-	erc4626BalanceFetcher := balance.NewERC4262Fetcher(rpcConfigurationResolver, httpClient)
-	erc20WrapperBalanceFetcher := balance.NewERC20WrapperFetcher(erc20BalanceFetcher)
+	coingeckoRequestsPerMinute := syncConfig.Coingecko.RequestsPerMinute
+	if coingeckoRequestsPerMinute == 0 {
+		coingeckoRequestsPerMinute = coingecko.DefaultRequestsPerMinute(coingeckoPlan)
+	}
 
-	erc20AssetResolver := token.NewERC20AssetResolver()
-	erc4626AssetResolver := token.NewERC4626AssetResolver(rpcConfigurationResolver, httpClient)
-	erc20WrapperAssetResolver := token.NewERC20WrapperAssetResolver(rpcConfigurationResolver, httpClient)
+	coingeckoDoer := coingecko.NewAPIDoer(httpClient, coingeckoPlan, syncConfig.Coingecko.APIKey, coingeckoRequestsPerMinute, coingeckoMaxRetries, coingeckoInitialRetryDelay)
+	coingeckoQuoteResolver := coingecko.NewBatchedQuoteResolver(coingeckoDoer)
+	assetPlatformCacheTTL := syncConfig.Coingecko.AssetPlatformCacheTTL
+	if assetPlatformCacheTTL == 0 {
+		assetPlatformCacheTTL = defaultAssetPlatformCacheTTL
+	}
+	assetPlatformIDResolver := coingecko.NewHTTPAssetPlatformIDResolver(coingeckoDoer, assetPlatformCacheFile, assetPlatformCacheTTL, syncConfig.Coingecko.AssetPlatforms)
+	rpcConfigurationResolver := rpcconfig.NewDefaultConfigurationResolver(syncConfig.RPCConfigurations)
 
-	chainIDFetcher := evm.NewJSONRPCChainIDFetcher(rpcConfigurationResolver, httpClient)
-	decimalsResolver := token.NewRPCDecimalsResolver(rpcConfigurationResolver, httpClient)
+	rpcDoer := rpc.NewFailoverDoer(httpClient, syncConfig.RPCConfigurations)
+	blockResolver := rpc.NewPinnedBlockResolver(rpcDoer, syncConfig.RPCConfigurations)
+	ethCaller := rpc.NewMulticallEthCaller(rpcDoer, blockResolver, multicallBatchWindow)
+
+	chainIDFetcher := evm.NewJSONRPCChainIDFetcher(rpcConfigurationResolver, rpcDoer)
+
+	var decimalsResolver token.DecimalsResolver = token.NewRPCDecimalsResolver(rpcConfigurationResolver, ethCaller)
+	var erc4626AssetResolver token.AssetResolver[*config.ERC4626Account] = token.NewERC4626AssetResolver(rpcConfigurationResolver, ethCaller)
+	var erc20WrapperAssetResolver token.AssetResolver[*config.ERC20WrapperAccount] = token.NewERC20WrapperAssetResolver(rpcConfigurationResolver, ethCaller)
+	var aaveSupplyAssetResolver token.AssetResolver[*config.AaveSupplyAccount] = token.NewAaveSupplyAssetResolver(rpcConfigurationResolver, ethCaller)
+	var aaveDebtAssetResolver token.AssetResolver[*config.AaveDebtAccount] = token.NewAaveDebtAssetResolver(rpcConfigurationResolver, ethCaller)
+	var cometSupplyAssetResolver token.AssetResolver[*config.CometSupplyAccount] = token.NewCometSupplyAssetResolver(rpcConfigurationResolver, ethCaller)
+	var cometDebtAssetResolver token.AssetResolver[*config.CometDebtAccount] = token.NewCometDebtAssetResolver(rpcConfigurationResolver, ethCaller)
+	if useCache {
+		decimalsResolver = token.NewCachingDecimalsResolver(decimalsResolver, chainIDFetcher, fileCache)
+		erc4626AssetResolver = token.NewCachingAssetResolver(erc4626AssetResolver, chainIDFetcher, fileCache, token.ERC4626AssetCacheKey)
+		erc20WrapperAssetResolver = token.NewCachingAssetResolver(erc20WrapperAssetResolver, chainIDFetcher, fileCache, token.ERC20WrapperAssetCacheKey)
+		aaveSupplyAssetResolver = token.NewCachingAssetResolver(aaveSupplyAssetResolver, chainIDFetcher, fileCache, token.AaveSupplyAssetCacheKey)
+		aaveDebtAssetResolver = token.NewCachingAssetResolver(aaveDebtAssetResolver, chainIDFetcher, fileCache, token.AaveDebtAssetCacheKey)
+		cometSupplyAssetResolver = token.NewCachingAssetResolver(cometSupplyAssetResolver, chainIDFetcher, fileCache, token.CometSupplyAssetCacheKey)
+		cometDebtAssetResolver = token.NewCachingAssetResolver(cometDebtAssetResolver, chainIDFetcher, fileCache, token.CometDebtAssetCacheKey)
+	}
 
-	accountChangeSummaries := make(map[string]*changeSummary)
+	erc20BalanceFetcher := balance.NewERC20Fetcher(rpcConfigurationResolver, ethCaller)
+	positionResolver := &positionResolver{
+		erc20BalanceFetcher:          erc20BalanceFetcher,
+		erc4626BalanceFetcher:        balance.NewERC4262Fetcher(rpcConfigurationResolver, ethCaller, decimalsResolver),
+		erc20WrapperBalanceFetcher:   balance.NewERC20WrapperFetcher(erc20BalanceFetcher),
+		nativeBalanceFetcher:         balance.NewNativeFetcher(rpcConfigurationResolver, rpcDoer, blockResolver),
+		aaveSupplyBalanceFetcher:     balance.NewAaveSupplyFetcher(erc20BalanceFetcher),
+		aaveDebtBalanceFetcher:       balance.NewAaveDebtFetcher(erc20BalanceFetcher),
+		cometSupplyBalanceFetcher:    balance.NewCometSupplyFetcher(erc20BalanceFetcher),
+		cometDebtBalanceFetcher:      balance.NewCometDebtFetcher(rpcConfigurationResolver, ethCaller),
+		liquidStakingBalanceFetcher:  balance.NewLiquidStakingFetcher(erc20BalanceFetcher, rpcConfigurationResolver, ethCaller),
+		virtualPriceLPBalanceFetcher: balance.NewVirtualPriceLPFetcher(erc20BalanceFetcher, decimalsResolver, rpcConfigurationResolver, ethCaller),
+
+		erc20AssetResolver:          token.NewERC20AssetResolver(),
+		erc4626AssetResolver:        erc4626AssetResolver,
+		erc20WrapperAssetResolver:   erc20WrapperAssetResolver,
+		nativeAssetResolver:         token.NewNativeAssetResolver(),
+		aaveSupplyAssetResolver:     aaveSupplyAssetResolver,
+		aaveDebtAssetResolver:       aaveDebtAssetResolver,
+		cometSupplyAssetResolver:    cometSupplyAssetResolver,
+		cometDebtAssetResolver:      cometDebtAssetResolver,
+		liquidStakingAssetResolver:  token.NewLiquidStakingAssetResolver(),
+		virtualPriceLPAssetResolver: token.NewVirtualPriceLPAssetResolver(),
+
+		lpV2Fetcher:      balance.NewLPV2Fetcher(rpcConfigurationResolver, ethCaller),
+		uniswapV3Fetcher: balance.NewUniswapV3Fetcher(rpcConfigurationResolver, ethCaller),
+
+		decimalsResolver: decimalsResolver,
+
+		rpcConfigurationResolver: rpcConfigurationResolver,
+		blockResolver:            blockResolver,
+	}
 
-	for accountIndex, account := range syncConfig.Accounts {
-		addressType, err := account.GetAddressType()
-		if err != nil {
-			panic(fmt.Sprintf("failed to resolve address type for account at index %d: %v", accountIndex, err))
-		}
+	priceProviderOrder, err := syncConfig.Pricing.ProviderOrder()
+	if err != nil {
+		panic(fmt.Sprintf("invalid pricing configuration: %v", err))
+	}
 
-		var tokenAddress *string
-		var tokenBalance *big.Int
-		var syncableAccount config.SyncableAccount
-		var onchainAsset config.OnchainAsset
-		switch addressType {
-		case config.AddressTypeERC20:
-			erc20Account, err := account.AsERC20Account()
-			if err != nil {
-				panic(fmt.Sprintf("failed to resolve ERC20 account at index %d: %v", accountIndex, err))
-			}
+	rateChangeAction, err := syncConfig.Pricing.RateChangeActionOrDefault()
+	if err != nil {
+		panic(fmt.Sprintf("invalid pricing configuration: %v", err))
+	}
 
-			tokenAddress, err = erc20AssetResolver.ResolveAssetAddress(ctx, erc20Account)
-			if err != nil {
-				panic(fmt.Sprintf("failed to resolve token address for ERC20 account '%s': %v", syncableAccount.AccountName, err))
-			}
+	priceProvidersByName := map[priceconfig.ProviderName]price.Provider{
+		priceconfig.ProviderChainlink: price.NewChainlinkProvider(rpcConfigurationResolver, ethCaller, syncConfig.Pricing.ChainlinkFeeds),
+		priceconfig.ProviderCoingecko: price.NewCoingeckoProvider(coingeckoQuoteResolver, assetPlatformIDResolver, assetPlatformIDResolver),
+		priceconfig.ProviderDefiLlama: price.NewDefiLlamaProvider(httpClient, assetPlatformIDResolver, syncConfig.Pricing.DefiLlamaChains),
+	}
 
-			tokenBalance, err = erc20BalanceFetcher.FetchBalance(ctx, erc20Account)
-			if err != nil {
-				panic(fmt.Sprintf("failed to retrieve balance of ERC20 token '%s' for address '%s': %v", erc20Account.TokenAddress, erc20Account.WalletAddress, err))
-			}
+	// The assets against which DEX pools price assets are priced by the other providers, in the same order
+	var dexQuoteProviders []price.Provider
+	for _, providerName := range priceProviderOrder {
+		if provider, hasProvider := priceProvidersByName[providerName]; hasProvider {
+			dexQuoteProviders = append(dexQuoteProviders, provider)
+		}
+	}
+	priceProvidersByName[priceconfig.ProviderDEX] = price.NewDEXProvider(rpcConfigurationResolver, ethCaller, decimalsResolver, price.NewFallbackProvider(syncConfig.Pricing.MaxQuoteAge, dexQuoteProviders...), syncConfig.Pricing.DEXPools)
 
-			syncableAccount = erc20Account.SyncableAccount
-			onchainAsset = erc20Account.OnchainAsset
-		case config.AddressTypeERC4626:
-			erc4626Account, err := account.AsERC4626Account()
-			if err != nil {
-				panic(fmt.Sprintf("failed to resolve ERC4626 account at index %d: %v", accountIndex, err))
-			}
+	priceProviders := make([]price.Provider, len(priceProviderOrder))
+	for providerIndex, providerName := range priceProviderOrder {
+		priceProviders[providerIndex] = priceProvidersByName[providerName]
+	}
+	var priceProvider price.BatchingProvider = price.NewFallbackProvider(syncConfig.Pricing.MaxQuoteAge, priceProviders...)
+	freshPriceProvider := priceProvider
+	if useCache {
+		quoteCacheTTL := syncConfig.Pricing.QuoteCacheTTL
+		if quoteCacheTTL == 0 {
+			quoteCacheTTL = defaultQuoteCacheTTL
+		}
+		cachingProvider := price.NewCachingProvider(priceProvider, fileCache, quoteCacheTTL, syncConfig.Pricing.MaxQuoteAge)
+		priceProvider = cachingProvider
+		freshPriceProvider = cachingProvider.Uncached()
+	}
 
-			tokenAddress, err = erc4626AssetResolver.ResolveAssetAddress(ctx, erc4626Account)
-			if err != nil {
-				panic(fmt.Sprintf("failed to resolve token address for ERC4626 account '%s' with vault address '%s': %v", erc4626Account.AccountName, erc4626Account.VaultAddress, err))
-			}
+	// Verify configured chain IDs before anything is read, so that a misconfigured RPC URL is caught up front
+	for _, rpcConfiguration := range syncConfig.RPCConfigurations {
+		if rpcConfiguration.ChainID == nil {
+			continue
+		}
 
-			tokenBalance, err = erc4626BalanceFetcher.FetchBalance(ctx, erc4626Account)
-			if err != nil {
-				panic(fmt.Sprintf("failed to retrieve balance of ERC4626 vault '%s' for address '%s': %v", erc4626Account.VaultAddress, erc4626Account.WalletAddress, err))
-			}
+		if _, err := chainIDFetcher.GetChainID(ctx, rpcConfiguration.ChainName); err != nil {
+			panic(fmt.Sprintf("failed to verify chain ID for chain '%s': %v", rpcConfiguration.ChainName, err))
+		}
+	}
 
-			syncableAccount = erc4626Account.SyncableAccount
-			onchainAsset = erc4626Account.OnchainAsset
-		case config.AddressTypeERC20Wrapper:
-			erc20WrapperAccount, err := account.AsERC20WrapperAccount()
-			if err != nil {
-				panic(fmt.Sprintf("failed to resolve ERC20Wrapper account at index %d: %v", accountIndex, err))
-			}
+	positions, err := positionResolver.resolvePositions(ctx, syncConfig.Accounts)
+	if err != nil {
+		panic(fmt.Sprintf("failed to resolve onchain positions: %v", err))
+	}
 
-			tokenAddress, err = erc20WrapperAssetResolver.ResolveAssetAddress(ctx, erc20WrapperAccount)
-			if err != nil {
-				panic(fmt.Sprintf("failed to resolve token address for ERC20Wrapper account '%s': %v", syncableAccount.AccountName, err))
-			}
+	rateHistory, err := price.LoadRateHistory(getCacheFile("rates.json"))
+	if err != nil {
+		panic(fmt.Sprintf("failed to load the rates of the previous sync: %v", err))
+	}
 
-			tokenBalance, err = erc20WrapperBalanceFetcher.FetchBalance(ctx, erc20WrapperAccount)
-			if err != nil {
-				panic(fmt.Sprintf("failed to retrieve balance of ERC20Wrapper token '%s' for address '%s': %v", erc20WrapperAccount.TokenAddress, erc20WrapperAccount.WalletAddress, err))
-			}
+	// Resolve the YNAB account and category of every account up front, so that a misconfigured account is caught before any account is updated
+	ynabAccounts := make([]*ynab.Account, len(positions))
+	categoryIDs := make([]string, len(positions))
+	for positionIndex, position := range positions {
+		ynabAccounts[positionIndex], categoryIDs[positionIndex], err = resolveYNABAccount(position, accounts, categoryGroups)
+		if err != nil {
+			panic(fmt.Sprintf("invalid configuration of account '%s': %v", position.syncableAccount.AccountName, err))
+		}
+	}
 
-			syncableAccount = erc20WrapperAccount.SyncableAccount
-			onchainAsset = erc20WrapperAccount.OnchainAsset
-		default:
-			panic(fmt.Sprintf("unsupported address type '%s' for account '%s'", addressType, syncableAccount.AccountName))
+	// An account whose rate was rejected by the previous sync is valued with freshly retrieved quotes,
+	// so that a glitched quote that has been cached cannot confirm the rejected rate
+	accountPriceProviders := make([]price.BatchingProvider, len(positions))
+	hasFreshQuotes := make([]bool, len(positions))
+	for positionIndex, position := range positions {
+		accountPriceProviders[positionIndex] = priceProvider
+		hasFreshQuotes[positionIndex] = !useCache
+		if hasPendingRate(rateHistory, currency, ynabAccounts[positionIndex].Name, position) {
+			accountPriceProviders[positionIndex] = freshPriceProvider
+			hasFreshQuotes[positionIndex] = true
 		}
+	}
 
-		var categoryID string
-		for _, categoryGroup := range categoryGroups {
-			for _, category := range categoryGroup.Categories {
-				if category.Name == syncableAccount.TransactionCategoryName {
-					categoryID = category.Id
-					break
+	// Register every asset to be priced up front, so that prices are retrieved in as few requests as possible
+	for positionIndex, position := range positions {
+		for _, valuedPosition := range position.valuedPositions() {
+			for _, component := range valuedPosition.components {
+				priceAsset, priceAssetErr := getPriceAsset(ctx, chainIDFetcher, valuedPosition, component)
+				if priceAssetErr != nil {
+					panic(fmt.Sprintf("failed to determine how to price account '%s': %v", position.syncableAccount.AccountName, priceAssetErr))
 				}
+
+				component.priceAsset = priceAsset
+				accountPriceProviders[positionIndex].RegisterAsset(ctx, priceAsset, currency)
 			}
 		}
+	}
 
-		if categoryID == "" {
-			panic(fmt.Sprintf("No category '%s' found in budget for account '%s'", syncableAccount.TransactionCategoryName, syncableAccount.AccountName))
-		}
+	accountChangeSummaries := make(map[string]*changeSummary)
+	var failedAccountNames []string
+
+	for positionIndex, position := range positions {
+		syncableAccount := position.syncableAccount
+		ynabAccount := ynabAccounts[positionIndex]
+		categoryID := categoryIDs[positionIndex]
+		accountPriceProvider := accountPriceProviders[positionIndex]
+
+		currentValue := money.Zero()
+		valuedPositions := position.valuedPositions()
+		memoItems := make([]string, len(valuedPositions))
+		var rates []guardedRate
+		for holdingIndex, valuedPosition := range valuedPositions {
+			positionValue, positionRates, holdings, valueErr := valuePosition(ctx, accountPriceProvider, currency, valuedPosition)
+			if valueErr != nil {
+				panic(fmt.Sprintf("failed to value account '%s': %v", syncableAccount.AccountName, valueErr))
+			}
 
-		var dollarRate int64
-		var centsRate float64
+			currentValue = currentValue.Add(positionValue)
+			memoItems[holdingIndex] = strings.Join(holdings, " + ")
+			rates = append(rates, guardedRates(ynabAccount.Name, position, holdingIndex, positionRates)...)
+			if position.holdings != nil {
+				memoItems[holdingIndex] = formatAggregateMemoItem(valuedPosition, memoItems[holdingIndex])
+			}
+		}
 
-		chainID, chainIDErr := chainIDFetcher.GetChainID(ctx, onchainAsset.ChainName)
-		if chainIDErr != nil {
-			panic(fmt.Sprintf("failed to retrieve chain ID for account '%s': %v", syncableAccount.AccountName, chainIDErr))
+		currentBalance, err := currentValue.ToMilliunits(currency.FractionDigits)
+		if err != nil {
+			panic(fmt.Sprintf("failed to convert the value of account '%s' to milliunits: %v", syncableAccount.AccountName, err))
 		}
 
-		assetPlatformID, assetPlatformIDErr := assetPlatformIDResolver.ResolveForChainID(ctx, chainID)
-		if assetPlatformIDErr != nil {
-			panic(fmt.Sprintf("failed to retrieve asset platform ID for account '%s': %v", syncableAccount.AccountName, assetPlatformIDErr))
+		// Guard against a glitched price being written into the budget by comparing the rate against that of the previous sync
+		var flagColor string
+		var skipReason string
+		var rejectedRates []guardedRate
+		for _, rateChange := range findRateChanges(rateHistory, currency, rates, hasFreshQuotes[positionIndex], syncConfig.Pricing.MaxRateChangePercent) {
+			switch rateChangeAction {
+			case priceconfig.RateChangeActionSkip:
+				if skipReason == "" {
+					skipReason = rateChange.description
+				}
+				rejectedRates = append(rejectedRates, rateChange.rate)
+			case priceconfig.RateChangeActionFlag:
+				fmt.Printf("Flagging the adjustment of account '%s', whose %s\n", ynabAccount.Name, rateChange.description)
+				flagColor = rateChangeFlagColor
+			}
 		}
 
-		if tokenAddress != nil {
-			var hasQuote bool
-			var quoteErr error
-			dollarRate, centsRate, hasQuote, quoteErr = coingeckoQuoteResolver.ResolveQuote(ctx, assetPlatformID, *tokenAddress)
-			if quoteErr != nil {
-				panic(fmt.Sprintf("failed to get quote for token address '%s': %v", *tokenAddress, quoteErr))
-			} else if !hasQuote {
-				panic(fmt.Sprintf("unable to resolve a quote for token address '%s'; please configure one explicitly for the account", *tokenAddress))
+		if skipReason != "" {
+			// The rejected rates are kept as pending, so the next sync accepts them if freshly retrieved quotes confirm them
+			for _, rate := range rejectedRates {
+				rateHistory.Reject(rate.historyKey, currency, rate.rate)
 			}
-		} else {
-			var quoteErr error
-			dollarRate, centsRate, quoteErr = coingeckoQuoteResolver.ResolveETHQuote(ctx)
-			if quoteErr != nil {
-				panic(fmt.Sprintf("unable to resolve quote for ETH: %v", quoteErr))
+
+			fmt.Printf("Skipping account '%s', whose %s; the account will be updated by the next sync if the rate holds\n", ynabAccount.Name, skipReason)
+			accountChangeSummaries[ynabAccount.Name] = &changeSummary{
+				skipReason: skipReason,
 			}
+			continue
 		}
 
-		tokenDecimals, err := decimalsResolver.ResolveDecimals(ctx, onchainAsset, tokenAddress)
-		if err != nil {
-			panic(fmt.Sprintf("failed to resolve token decimals for account '%s': %v", syncableAccount.AccountName, err))
+		accountDiff := currentBalance - int64(ynabAccount.Balance)
+		if accountDiff != 0 && !dryRun {
+			if err := updateAccount(ynabClient, budget.Id, ynabAccount.Id, categoryID, syncableAccount.PayeeName, memoItems, position.blockNumber, accountDiff, flagColor); err != nil {
+				// The rates are not recorded, so the next sync compares against the rates at which the account was last updated
+				fmt.Printf("Failed to update account '%s': %v\n", ynabAccount.Name, err)
+				failedAccountNames = append(failedAccountNames, ynabAccount.Name)
+				continue
+			}
 		}
 
-		currentBalance := balance.AsFiat(tokenBalance, tokenDecimals, dollarRate, centsRate) * 10 // YNAB stores cents as hundreds, not tens
-
-		ynabAccount, err := getAccount(syncableAccount.AccountName, accounts)
-		if err != nil {
-			panic(fmt.Sprintf("failed to find account: %v", err))
+		for _, rate := range rates {
+			rateHistory.Record(rate.historyKey, currency, rate.rate)
 		}
 
-		if accountDiff := currentBalance - int64(ynabAccount.Balance); accountDiff != 0 {
-			if !dryRun {
-				updateAccount(ynabClient, budget.Id, ynabAccount.Id, categoryID, syncableAccount.PayeeName, tokenBalance.Int64(), tokenDecimals, dollarRate, centsRate, accountDiff)
-			}
+		accountChangeSummaries[ynabAccount.Name] = &changeSummary{
+			milliunits: accountDiff,
+		}
+	}
 
-			accountDiffCents := accountDiff % 1000
-			accountDiffDollars := (accountDiff - accountDiffCents) / 1000
-			accountDiffCents = (accountDiffCents - (accountDiffCents % 10)) / 10
-			accountChangeSummaries[ynabAccount.Name] = &changeSummary{
-				dollars: accountDiffDollars,
-				cents:   accountDiffCents,
-			}
-		} else {
-			accountChangeSummaries[ynabAccount.Name] = &changeSummary{}
+	if useCache {
+		// An unsaved cache only means that the next run retrieves everything again
+		if err := fileCache.Save(); err != nil {
+			fmt.Printf("Failed to save the cache: %v\n", err)
 		}
+	}
 
+	if !dryRun {
+		// The history only guards against unexpected changes, so being unable to save it should not fail the sync
+		if err := rateHistory.Save(); err != nil {
+			fmt.Printf("Failed to save the rates of this sync: %v\n", err)
+		}
 	}
 
 	fmt.Println("================")
-	fmt.Printf("Updated %d accounts:\n", len(accountChangeSummaries))
-
 	accountNames := make([]string, 0, len(accountChangeSummaries))
-	for accountName := range accountChangeSummaries {
+	var skippedAccountCount int
+	for accountName, changeSummary := range accountChangeSummaries {
 		accountNames = append(accountNames, accountName)
+		if changeSummary.skipReason != "" {
+			skippedAccountCount++
+		}
 	}
 	sort.Strings(accountNames)
 
+	fmt.Printf("Updated %d accounts:\n", len(accountChangeSummaries)-skippedAccountCount)
 	for _, accountName := range accountNames {
-		changeSummary, _ := accountChangeSummaries[accountName]
-		absCents := int(math.Abs(float64(changeSummary.cents)))
-		fmt.Printf("  %s: $%d.%02d\n", accountName, changeSummary.dollars, absCents)
+		if changeSummary := accountChangeSummaries[accountName]; changeSummary.skipReason == "" {
+			fmt.Printf("  %s: %s\n", accountName, currency.Format(money.NewDecimalFromMilliunits(changeSummary.milliunits)))
+		}
+	}
+
+	if skippedAccountCount > 0 {
+		fmt.Printf("Skipped %d accounts:\n", skippedAccountCount)
+		for _, accountName := range accountNames {
+			if changeSummary := accountChangeSummaries[accountName]; changeSummary.skipReason != "" {
+				fmt.Printf("  %s: %s\n", accountName, changeSummary.skipReason)
+			}
+		}
+	}
+
+	if len(failedAccountNames) > 0 {
+		panic(fmt.Sprintf("failed to update %d accounts: %s", len(failedAccountNames), strings.Join(failedAccountNames, ", ")))
 	}
 }
 
-func dryRunEnabled() bool {
+// flagEnabled determines whether the given flag was passed on the command line.
+func flagEnabled(flag string) bool {
 	for _, osArg := range os.Args {
-		if strings.HasPrefix(osArg, "--dry-run") {
+		if strings.HasPrefix(osArg, flag) {
 			return true
 		}
 	}
@@ -274,6 +447,56 @@ func getAccount(desiredAccountName string, accounts []ynab.Account) (*ynab.Accou
 	return nil, fmt.Errorf("no account found for name '%s'; available accounts are: ['%s']", desiredAccountName, strings.Join(accountNames, "', '"))
 }
 
+// resolveYNABAccount resolves the YNAB account to which the given position is synced, along with the ID of the category under which
+// its adjustments are to be written; the category ID is blank if its adjustments are to be left uncategorized.
+// An error is returned if the position cannot be synced to the account as configured.
+func resolveYNABAccount(position *accountPosition, accounts []ynab.Account, categoryGroups []ynab.CategoryGroupWithCategories) (*ynab.Account, string, error) {
+	ynabAccount, err := getAccount(position.syncableAccount.AccountName, accounts)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to find account: %w", err)
+	}
+
+	isLiability := isLiabilityAccountType(ynabAccount.Type)
+	if position.isDebt && !isLiability {
+		return nil, "", fmt.Errorf("the account holds a debt, but is a '%s' account in YNAB; debts can only be synced to loan, credit, or other liability accounts", ynabAccount.Type)
+	}
+
+	// Transactions in tracking accounts cannot be categorized
+	if !ynabAccount.OnBudget {
+		return ynabAccount, "", nil
+	}
+
+	categoryName := position.syncableAccount.TransactionCategoryName
+	if categoryName == "" {
+		// An adjustment of what is owed on a liability may be left uncategorized, but that of an asset on budget would go unbudgeted
+		if isLiability {
+			return ynabAccount, "", nil
+		}
+
+		return nil, "", errors.New("a transaction category name is required for accounts that are on budget, other than liability accounts")
+	}
+
+	categoryID, err := getCategoryID(categoryName, categoryGroups)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to find category: %w", err)
+	}
+
+	return ynabAccount, categoryID, nil
+}
+
+// getCategoryID gets the ID of the category with the given name.
+func getCategoryID(categoryName string, categoryGroups []ynab.CategoryGroupWithCategories) (string, error) {
+	for _, categoryGroup := range categoryGroups {
+		for _, category := range categoryGroup.Categories {
+			if category.Name == categoryName {
+				return category.Id, nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("no category '%s' found in budget", categoryName)
+}
+
 func getBudget(desiredBudgetName string, client *ynab.Client) (*ynab.BudgetSummary, error) {
 	budgets, err := client.BudgetService.List()
 	if err != nil {
@@ -294,6 +517,81 @@ func getBudget(desiredBudgetName string, client *ynab.Client) (*ynab.BudgetSumma
 	return nil, fmt.Errorf("Budget '%s' not found; available budget(s) are: ['%s']", desiredBudgetName, strings.Join(budgetNames, "', '"))
 }
 
+// getPriceAsset determines the asset whose price is to be used to value the given component of the given position, honoring the price configured for its account.
+func getPriceAsset(ctx context.Context, chainIDFetcher evm.ChainIDFetcher, position *accountPosition, component *positionComponent) (price.Asset, error) {
+	accountPrice := position.price
+
+	if accountPrice != nil && accountPrice.FixedPrice != nil {
+		fixedPrice, err := money.ParseDecimal(*accountPrice.FixedPrice)
+		if err != nil {
+			return price.Asset{}, fmt.Errorf("invalid fixed price '%s': %w", *accountPrice.FixedPrice, err)
+		}
+
+		return price.Asset{
+			ChainName:       position.onchainAsset.ChainName,
+			ContractAddress: component.tokenAddress,
+			FixedPrice:      &fixedPrice,
+		}, nil
+	}
+
+	if accountPrice != nil && accountPrice.PeggedTo != nil {
+		peggedAsset := price.Asset{
+			ChainName:       accountPrice.PeggedTo.ChainName,
+			ContractAddress: accountPrice.PeggedTo.TokenAddress,
+		}
+
+		if accountPrice.PeggedTo.ChainID != nil {
+			peggedAsset.ChainID = new(big.Int).SetUint64(*accountPrice.PeggedTo.ChainID)
+		} else {
+			chainID, err := chainIDFetcher.GetChainID(ctx, peggedAsset.ChainName)
+			if err != nil {
+				return price.Asset{}, fmt.Errorf("failed to retrieve chain ID of pegged asset: %w", err)
+			}
+			peggedAsset.ChainID = chainID
+		}
+
+		return peggedAsset, nil
+	}
+
+	chainID, err := chainIDFetcher.GetChainID(ctx, position.onchainAsset.ChainName)
+	if err != nil {
+		return price.Asset{}, fmt.Errorf("failed to retrieve chain ID: %w", err)
+	}
+
+	asset := price.Asset{
+		ChainName:       position.onchainAsset.ChainName,
+		ChainID:         chainID,
+		ContractAddress: component.tokenAddress,
+	}
+
+	if accountPrice != nil {
+		asset.CoinID = accountPrice.CoingeckoCoinID
+	}
+
+	return asset, nil
+}
+
+// isLiabilityAccountType determines whether YNAB accounts of the given type track amounts owed, rather than amounts held.
+func isLiabilityAccountType(accountType string) bool {
+	switch accountType {
+	case "creditCard", "lineOfCredit", "otherLiability", "mortgage", "autoLoan", "studentLoan", "personalLoan", "medicalDebt", "otherDebt":
+		return true
+	default:
+		return false
+	}
+}
+
+// getCacheFile gets the location of the file with the given name in this tool's cache directory.
+// If no user cache directory can be determined, a blank string is returned and nothing is cached on disk.
+func getCacheFile(fileName string) string {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(cacheDir, "cryptonabber-sync", fileName)
+}
+
 func getConfigFile() string {
 	for _, osArg := range os.Args {
 		if strings.HasPrefix(osArg, "--file=") {
@@ -304,22 +602,25 @@ func getConfigFile() string {
 	return "config.yaml"
 }
 
-func updateAccount(client *ynab.Client, budgetID string, accountID string, categoryID string, payeeName string, tokenBalance int64, tokenDecimals int, dollarRate int64, centsRate float64, deltaDecicents int64) error {
+func updateAccount(client *ynab.Client, budgetID string, accountID string, categoryID string, payeeName string, memoItems []string, blockNumber *big.Int, deltaMilliunits int64, flagColor string) error {
 	dateString := time.Now().Format("2006-01-02")
 
-	tokenFractions := tokenBalance % int64(math.Pow10(tokenDecimals))
-	wholeTokens := (tokenBalance - tokenFractions) / int64(math.Pow10(tokenDecimals))
-	formattedTokenBalance := fmt.Sprintf("%d.%s", wholeTokens, fmt.Sprintf("%d", tokenFractions)[:2])
-	formattedRate := fmt.Sprintf("$%d.%s", dollarRate, fmt.Sprintf("%.2f", centsRate)[2:])
+	formattedHoldings := strings.Join(memoItems, "; ")
 	formattedTime := time.Now().Format("03:04 PM MST")
 
+	memo := fmt.Sprintf("%s (executed %v)", formattedHoldings, formattedTime)
+	if blockNumber != nil {
+		memo = fmt.Sprintf("%s (block %v; executed %v)", formattedHoldings, blockNumber, formattedTime)
+	}
+
 	_, err := client.TransactionsService.Create(budgetID, &ynab.SaveTransaction{
 		AccountId:  accountID,
 		Date:       dateString,
-		Amount:     int(deltaDecicents),
+		Amount:     int(deltaMilliunits),
 		PayeeName:  payeeName,
 		CategoryId: categoryID,
-		Memo:       fmt.Sprintf("%s @ %s (executed %v)", formattedTokenBalance, formattedRate, formattedTime),
+		Memo:       memo,
+		FlagColor:  flagColor,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to create adjustment transaction: %w", err)
@@ -328,7 +629,177 @@ func updateAccount(client *ynab.Client, budgetID string, accountID string, categ
 	return nil
 }
 
+// guardedRate is a rate of an account that is guarded against unexpected changes between syncs.
+type guardedRate struct {
+	historyKey string // the key under which the rate is recorded in the rate history
+	subject    string // a description of the rate for messages about its changes
+	rate       money.Decimal
+}
+
+// rateChange is a guarded rate that has changed by more than is accepted as-is since the previous sync.
+type rateChange struct {
+	rate        guardedRate
+	description string
+}
+
+// findRateChanges compares the given rates against those of the previous sync recorded in the given rate history, and returns
+// those that have changed by more than the given maximum percentage. No rate is considered to have changed if the maximum is not positive.
+func findRateChanges(rateHistory *price.RateHistory, currency money.Currency, rates []guardedRate, hasFreshQuotes bool, maxRateChangePercent float64) []rateChange {
+	var rateChanges []rateChange
+	for _, rate := range rates {
+		previousRate, changePercent, hasPreviousRate := rateHistory.Observe(rate.historyKey, currency, rate.rate, hasFreshQuotes)
+		if !hasPreviousRate || maxRateChangePercent <= 0 || math.Abs(changePercent) <= maxRateChangePercent {
+			continue
+		}
+
+		rateChanges = append(rateChanges, rateChange{
+			rate:        rate,
+			description: fmt.Sprintf("%s changed by %.2f%% from %s to %s since the previous sync", rate.subject, changePercent, currency.FormatRate(previousRate), currency.FormatRate(rate.rate)),
+		})
+	}
+
+	return rateChanges
+}
+
+// guardedRates labels the given rates, at which the valued position at the given index of the given position was valued,
+// with the keys under which they are recorded in the rate history.
+func guardedRates(ynabAccountName string, position *accountPosition, holdingIndex int, rates []money.Decimal) []guardedRate {
+	guarded := make([]guardedRate, len(rates))
+	for rateIndex, rate := range rates {
+		historyKey, subject := rateHistoryKey(ynabAccountName, position, holdingIndex, rateIndex, len(rates))
+		guarded[rateIndex] = guardedRate{
+			historyKey: historyKey,
+			subject:    subject,
+			rate:       rate,
+		}
+	}
+
+	return guarded
+}
+
+// rateHistoryKey gets the key under which the rate at the given index of the rates of the valued position at the given index of the given position
+// is recorded in the rate history, along with a description of the rate for messages about its changes.
+// The rate of each holding of an aggregate, and of each component of a position guarded by the prices of several components, is tracked separately,
+// as each may be of a different asset.
+func rateHistoryKey(ynabAccountName string, position *accountPosition, holdingIndex int, rateIndex int, rateCount int) (string, string) {
+	switch {
+	case position.holdings == nil && rateCount == 1:
+		return ynabAccountName, "rate"
+	case position.holdings == nil:
+		return fmt.Sprintf("%s [component %d]", ynabAccountName, rateIndex), fmt.Sprintf("rate of component %d", rateIndex)
+	case rateCount == 1:
+		return fmt.Sprintf("%s [holding %d]", ynabAccountName, holdingIndex), fmt.Sprintf("rate of holding %d", holdingIndex)
+	default:
+		return fmt.Sprintf("%s [holding %d, component %d]", ynabAccountName, holdingIndex, rateIndex), fmt.Sprintf("rate of component %d of holding %d", rateIndex, holdingIndex)
+	}
+}
+
+// hasPendingRate determines whether a rate of the given position that was rejected by a previous sync is pending in the given rate history.
+func hasPendingRate(rateHistory *price.RateHistory, currency money.Currency, ynabAccountName string, position *accountPosition) bool {
+	for holdingIndex, valuedPosition := range position.valuedPositions() {
+		rateCount := guardedRateCount(valuedPosition)
+		for rateIndex := range rateCount {
+			if historyKey, _ := rateHistoryKey(ynabAccountName, position, holdingIndex, rateIndex, rateCount); rateHistory.HasPendingRate(historyKey, currency) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// guardedRateCount gets the number of rates by which unexpected changes in the value of the given position are guarded:
+// a single rate, the value of a share, for a position held as shares of a pool, or else the price of each of its components.
+func guardedRateCount(position *accountPosition) int {
+	if position.shareBalance != nil {
+		return 1
+	}
+
+	return len(position.components)
+}
+
+// valuePosition values the components of the given position at their quotes. Along with the value, it returns the rates against which
+// unexpected changes in the position's value are guarded, as described by guardedRateCount, and each of the position's components formatted for a transaction memo.
+func valuePosition(ctx context.Context, priceProvider price.Provider, currency money.Currency, position *accountPosition) (money.Decimal, []money.Decimal, []string, error) {
+	value := money.Zero()
+	rates := make([]money.Decimal, len(position.components))
+	holdings := make([]string, len(position.components))
+	for componentIndex, component := range position.components {
+		priceAsset := component.priceAsset
+		quote, hasQuote, err := priceProvider.ResolvePrice(ctx, priceAsset, currency)
+		if err != nil {
+			return money.Decimal{}, nil, nil, fmt.Errorf("failed to resolve price of %s: %w", priceAsset, err)
+		} else if !hasQuote {
+			return money.Decimal{}, nil, nil, fmt.Errorf("unable to resolve a price of %s from any of the price providers (%s); please configure a Chainlink feed or a price for it", priceAsset, priceProvider.Name())
+		}
+
+		value = value.Add(balance.AsFiat(component.tokenBalance, component.tokenDecimals, quote.Price))
+		holdings[componentIndex] = formatHolding(component, currency, quote.Price)
+		rates[componentIndex] = quote.Price
+	}
+
+	// A position held as shares of a pool is valued by the value of each share, rather than by the prices of the pool's assets
+	if position.shareBalance != nil {
+		rates = []money.Decimal{getShareRate(value, position.shareBalance, position.shareDecimals)}
+	}
+
+	return value, rates, holdings, nil
+}
+
+// formatAggregateMemoItem labels the given formatted holdings of one of the holdings of an aggregate account with the chain,
+// and block, from which the holding was read, as the holdings of an aggregate may span several chains.
+func formatAggregateMemoItem(position *accountPosition, formattedHoldings string) string {
+	if position.blockNumber == nil {
+		return fmt.Sprintf("%s: %s", position.onchainAsset.ChainName, formattedHoldings)
+	}
+
+	return fmt.Sprintf("%s (block %v): %s", position.onchainAsset.ChainName, position.blockNumber, formattedHoldings)
+}
+
+// formatHolding formats the balance of the given component, and the rate at which it was valued, for a transaction memo.
+func formatHolding(component *positionComponent, currency money.Currency, rate money.Decimal) string {
+	formattedTokenBalance := money.NewDecimalFromUnits(component.tokenBalance, component.tokenDecimals).FloatString(2)
+
+	return fmt.Sprintf("%s @ %s", formattedTokenBalance, currency.FormatRate(rate))
+}
+
+// getShareRate gets the value of a single share of a pool, given the value of the given balance of shares.
+func getShareRate(value money.Decimal, shareBalance *big.Int, shareDecimals int) money.Decimal {
+	if shareBalance.Sign() == 0 {
+		return money.Zero()
+	}
+
+	shares := money.NewDecimalFromUnits(shareBalance, shareDecimals)
+
+	return money.NewDecimalFromRat(new(big.Rat).Quo(value.Rat(), shares.Rat()))
+}
+
+// getCurrency determines the currency in which balances are valued: the configured currency, if any, or else the currency of the given budget.
+func getCurrency(configuredCurrencyCode string, budget *ynab.BudgetSummary) money.Currency {
+	if configuredCurrencyCode != "" {
+		return money.CurrencyForCode(configuredCurrencyCode)
+	}
+
+	currencyFormat := budget.CurrencyFormat
+	if currencyFormat.IsoCode == "" {
+		return money.USD
+	}
+
+	currency := money.Currency{
+		Code:             strings.ToUpper(currencyFormat.IsoCode),
+		SymbolFirst:      currencyFormat.SymbolFirst,
+		FractionDigits:   currencyFormat.DecimalDigits,
+		DecimalSeparator: currencyFormat.DecimalSeparator,
+	}
+
+	if currencyFormat.DisplaySymbol {
+		currency.Symbol = currencyFormat.CurrencySymbol
+	}
+
+	return currency
+}
+
 type changeSummary struct {
-	dollars int64
-	cents   int64
+	milliunits int64
+	skipReason string // why the account was not updated; blank if it was
 }
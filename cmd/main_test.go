@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/jrh3k5/cryptonabber-sync/v3/money"
+	"github.com/jrh3k5/cryptonabber-sync/v3/price"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("guarding the rates of a position", func() {
+	const maxRateChangePercent = 50.0
+
+	var ctx context.Context
+	var rateHistory *price.RateHistory
+	var token0Address, token1Address string
+	var position *accountPosition
+	var prices map[string]string
+
+	// sync values the position at the current prices, and records its rates if none of them changed unexpectedly
+	sync := func() []rateChange {
+		_, rates, _, err := valuePosition(ctx, &stubPriceProvider{prices: prices}, money.USD, position)
+		Expect(err).ToNot(HaveOccurred(), "valuing the position should not fail")
+
+		guarded := guardedRates("Account", position, 0, rates)
+		rateChanges := findRateChanges(rateHistory, money.USD, guarded, true, maxRateChangePercent)
+		if len(rateChanges) == 0 {
+			for _, rate := range guarded {
+				rateHistory.Record(rate.historyKey, money.USD, rate.rate)
+			}
+		}
+
+		return rateChanges
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		var err error
+		rateHistory, err = price.LoadRateHistory("")
+		Expect(err).ToNot(HaveOccurred(), "loading the history should not fail")
+
+		token0Address = "0x0000000000000000000000000000000000000a00"
+		token1Address = "0x0000000000000000000000000000000000000b00"
+		prices = map[string]string{
+			token0Address: "2",
+			token1Address: "1",
+		}
+
+		// A position in a pool of two assets, such as a Uniswap V3 position, that is not held as shares of the pool
+		position = &accountPosition{
+			components: []*positionComponent{
+				{
+					tokenAddress: &token0Address,
+					tokenBalance: big.NewInt(10),
+					priceAsset:   price.Asset{ChainName: "ethereum", ChainID: big.NewInt(1), ContractAddress: &token0Address},
+				},
+				{
+					tokenAddress: &token1Address,
+					tokenBalance: big.NewInt(20),
+					priceAsset:   price.Asset{ChainName: "ethereum", ChainID: big.NewInt(1), ContractAddress: &token1Address},
+				},
+			},
+		}
+	})
+
+	It("guards the price of each component of the position", func() {
+		Expect(sync()).To(BeEmpty(), "the first sync should have nothing to compare against")
+
+		prices[token0Address] = "20"
+		rateChanges := sync()
+		Expect(rateChanges).To(HaveLen(1), "the jump in the price of token0 should be caught, even though token1's price is unchanged")
+		Expect(rateChanges[0].rate.historyKey).To(Equal("Account [component 0]"), "the price of token0 should be guarded under its own key")
+		Expect(rateChanges[0].description).To(ContainSubstring("rate of component 0"), "the change should describe the component whose price changed")
+	})
+
+	It("guards a position held as shares of a pool by the value of a share", func() {
+		position.shareBalance = big.NewInt(5)
+		Expect(sync()).To(BeEmpty(), "the first sync should have nothing to compare against")
+
+		prices[token0Address] = "20"
+		rateChanges := sync()
+		Expect(rateChanges).To(HaveLen(1), "the change in the value of a share should be caught")
+		Expect(rateChanges[0].rate.historyKey).To(Equal("Account"), "the value of a share should be guarded under the account's name")
+	})
+})
+
+// stubPriceProvider prices assets by their contract addresses.
+type stubPriceProvider struct {
+	prices map[string]string // contract address -> price
+}
+
+func (*stubPriceProvider) Name() string {
+	return "stub"
+}
+
+func (s *stubPriceProvider) ResolvePrice(_ context.Context, asset price.Asset, _ money.Currency) (price.Quote, bool, error) {
+	if asset.ContractAddress == nil {
+		return price.Quote{}, false, nil
+	}
+
+	assetPrice, hasPrice := s.prices[*asset.ContractAddress]
+	if !hasPrice {
+		return price.Quote{}, false, nil
+	}
+
+	parsedPrice, err := money.ParseDecimal(assetPrice)
+	return price.Quote{Price: parsedPrice}, true, err
+}
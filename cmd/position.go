@@ -0,0 +1,417 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/jrh3k5/cryptonabber-sync/v3/config"
+	"github.com/jrh3k5/cryptonabber-sync/v3/config/chain"
+	rpcconfig "github.com/jrh3k5/cryptonabber-sync/v3/config/rpc"
+	"github.com/jrh3k5/cryptonabber-sync/v3/http/json/rpc"
+	"github.com/jrh3k5/cryptonabber-sync/v3/price"
+	"github.com/jrh3k5/cryptonabber-sync/v3/token"
+	"github.com/jrh3k5/cryptonabber-sync/v3/token/balance"
+)
+
+// accountPosition describes the onchain position backing a YNAB account.
+type accountPosition struct {
+	syncableAccount config.SyncableAccount
+	onchainAsset    config.OnchainAsset
+	components      []*positionComponent // the assets of which the position consists; most positions consist of a single asset
+	shareBalance    *big.Int             // for a position held as shares of a pool of its components (e.g., LP tokens), the balance of those shares; nil otherwise
+	shareDecimals   int
+	blockNumber     *big.Int             // the block as of which the position was read; nil if it was read as of the latest block
+	price           *config.AccountPrice // how the asset of a single-asset position is to be priced, if not by its own address
+	isDebt          bool                 // whether the position is an amount owed, rather than an amount held
+	holdings        []*accountPosition   // for an account that aggregates several holdings, the position of each holding; nil otherwise
+}
+
+// valuedPositions gets the positions whose values make up the balance of the position's account:
+// the position of each of its holdings, if it aggregates several holdings, or else the position itself.
+func (a *accountPosition) valuedPositions() []*accountPosition {
+	if a.holdings != nil {
+		return a.holdings
+	}
+
+	return []*accountPosition{a}
+}
+
+// positionComponent is an amount of one of the assets of which a position consists.
+type positionComponent struct {
+	tokenAddress  *string  // the address of the asset whose quote values the component; nil for a chain's native coin
+	tokenBalance  *big.Int // the balance of the component, expressed in the asset identified by tokenAddress; negative for a debt
+	tokenDecimals int
+	priceAsset    price.Asset // the asset whose quote values the component, as determined once the position is resolved
+}
+
+// positionResolver resolves the onchain positions described by account configurations.
+type positionResolver struct {
+	erc20BalanceFetcher          balance.Fetcher[*config.ERC20Account]
+	erc4626BalanceFetcher        balance.Fetcher[*config.ERC4626Account]
+	erc20WrapperBalanceFetcher   balance.Fetcher[*config.ERC20WrapperAccount]
+	nativeBalanceFetcher         balance.Fetcher[*config.NativeAccount]
+	aaveSupplyBalanceFetcher     balance.Fetcher[*config.AaveSupplyAccount]
+	aaveDebtBalanceFetcher       balance.Fetcher[*config.AaveDebtAccount]
+	cometSupplyBalanceFetcher    balance.Fetcher[*config.CometSupplyAccount]
+	cometDebtBalanceFetcher      balance.Fetcher[*config.CometDebtAccount]
+	liquidStakingBalanceFetcher  balance.Fetcher[*config.LiquidStakingAccount]
+	virtualPriceLPBalanceFetcher balance.Fetcher[*config.VirtualPriceLPAccount]
+
+	erc20AssetResolver          token.AssetResolver[*config.ERC20Account]
+	erc4626AssetResolver        token.AssetResolver[*config.ERC4626Account]
+	erc20WrapperAssetResolver   token.AssetResolver[*config.ERC20WrapperAccount]
+	nativeAssetResolver         token.AssetResolver[*config.NativeAccount]
+	aaveSupplyAssetResolver     token.AssetResolver[*config.AaveSupplyAccount]
+	aaveDebtAssetResolver       token.AssetResolver[*config.AaveDebtAccount]
+	cometSupplyAssetResolver    token.AssetResolver[*config.CometSupplyAccount]
+	cometDebtAssetResolver      token.AssetResolver[*config.CometDebtAccount]
+	liquidStakingAssetResolver  token.AssetResolver[*config.LiquidStakingAccount]
+	virtualPriceLPAssetResolver token.AssetResolver[*config.VirtualPriceLPAccount]
+
+	lpV2Fetcher      *balance.LPV2Fetcher
+	uniswapV3Fetcher *balance.UniswapV3Fetcher
+
+	decimalsResolver token.DecimalsResolver
+
+	rpcConfigurationResolver rpcconfig.ConfigurationResolver
+	blockResolver            rpc.BlockResolver
+}
+
+// resolvePositions resolves the positions of all of the given accounts concurrently, so that the onchain reads
+// for them can be batched together. The returned positions are in the same order as the given accounts.
+func (p *positionResolver) resolvePositions(ctx context.Context, accounts []config.AccountProperties) ([]*accountPosition, error) {
+	positions := make([]*accountPosition, len(accounts))
+	errs := make([]error, len(accounts))
+
+	var waitGroup sync.WaitGroup
+	for accountIndex, account := range accounts {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+
+			positions[accountIndex], errs[accountIndex] = p.resolvePosition(ctx, accountIndex, account)
+		}()
+	}
+	waitGroup.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return positions, nil
+}
+
+func (p *positionResolver) resolvePosition(ctx context.Context, accountIndex int, account config.AccountProperties) (*accountPosition, error) {
+	holdingAccounts, err := account.GetHoldings()
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve holdings for account at index %d: %w", accountIndex, err)
+	} else if holdingAccounts != nil {
+		return p.resolveAggregatePosition(ctx, accountIndex, account, holdingAccounts)
+	}
+
+	addressType, err := account.GetAddressType()
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve address type for account at index %d: %w", accountIndex, err)
+	}
+
+	position := &accountPosition{}
+	component := &positionComponent{}
+	switch addressType {
+	case config.AddressTypeERC20:
+		erc20Account, err := account.AsERC20Account()
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve ERC20 account at index %d: %w", accountIndex, err)
+		}
+
+		component.tokenAddress, err = p.erc20AssetResolver.ResolveAssetAddress(ctx, erc20Account)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve token address for ERC20 account '%s': %w", erc20Account.AccountName, err)
+		}
+
+		component.tokenBalance, err = p.erc20BalanceFetcher.FetchBalance(ctx, erc20Account)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve balance of ERC20 token '%s' for address '%s': %w", erc20Account.TokenAddress, erc20Account.WalletAddress, err)
+		}
+
+		position.syncableAccount = erc20Account.SyncableAccount
+		position.onchainAsset = erc20Account.OnchainAsset
+	case config.AddressTypeERC4626:
+		erc4626Account, err := account.AsERC4626Account()
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve ERC4626 account at index %d: %w", accountIndex, err)
+		}
+
+		component.tokenAddress, err = p.erc4626AssetResolver.ResolveAssetAddress(ctx, erc4626Account)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve token address for ERC4626 account '%s' with vault address '%s': %w", erc4626Account.AccountName, erc4626Account.VaultAddress, err)
+		}
+
+		component.tokenBalance, err = p.erc4626BalanceFetcher.FetchBalance(ctx, erc4626Account)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve balance of ERC4626 vault '%s' for address '%s': %w", erc4626Account.VaultAddress, erc4626Account.WalletAddress, err)
+		}
+
+		position.syncableAccount = erc4626Account.SyncableAccount
+		position.onchainAsset = erc4626Account.OnchainAsset
+	case config.AddressTypeERC20Wrapper:
+		erc20WrapperAccount, err := account.AsERC20WrapperAccount()
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve ERC20Wrapper account at index %d: %w", accountIndex, err)
+		}
+
+		component.tokenAddress, err = p.erc20WrapperAssetResolver.ResolveAssetAddress(ctx, erc20WrapperAccount)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve token address for ERC20Wrapper account '%s': %w", erc20WrapperAccount.AccountName, err)
+		}
+
+		component.tokenBalance, err = p.erc20WrapperBalanceFetcher.FetchBalance(ctx, erc20WrapperAccount)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve balance of ERC20Wrapper token '%s' for address '%s': %w", erc20WrapperAccount.TokenAddress, erc20WrapperAccount.WalletAddress, err)
+		}
+
+		position.syncableAccount = erc20WrapperAccount.SyncableAccount
+		position.onchainAsset = erc20WrapperAccount.OnchainAsset
+	case config.AddressTypeNative:
+		nativeAccount, err := account.AsNativeAccount()
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve native account at index %d: %w", accountIndex, err)
+		}
+
+		component.tokenAddress, err = p.nativeAssetResolver.ResolveAssetAddress(ctx, nativeAccount)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve token address for native account '%s': %w", nativeAccount.AccountName, err)
+		}
+
+		component.tokenBalance, err = p.nativeBalanceFetcher.FetchBalance(ctx, nativeAccount)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve native balance on chain '%s' for address '%s': %w", nativeAccount.ChainName, nativeAccount.WalletAddress, err)
+		}
+
+		position.syncableAccount = nativeAccount.SyncableAccount
+		position.onchainAsset = nativeAccount.OnchainAsset
+	case config.AddressTypeAaveSupply:
+		aaveSupplyAccount, err := account.AsAaveSupplyAccount()
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve Aave supply account at index %d: %w", accountIndex, err)
+		}
+
+		component.tokenAddress, err = p.aaveSupplyAssetResolver.ResolveAssetAddress(ctx, aaveSupplyAccount)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve token address for Aave supply account '%s' with aToken address '%s': %w", aaveSupplyAccount.AccountName, aaveSupplyAccount.ATokenAddress, err)
+		}
+
+		component.tokenBalance, err = p.aaveSupplyBalanceFetcher.FetchBalance(ctx, aaveSupplyAccount)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve balance of Aave aToken '%s' for address '%s': %w", aaveSupplyAccount.ATokenAddress, aaveSupplyAccount.WalletAddress, err)
+		}
+
+		position.syncableAccount = aaveSupplyAccount.SyncableAccount
+		position.onchainAsset = aaveSupplyAccount.OnchainAsset
+	case config.AddressTypeAaveDebt:
+		aaveDebtAccount, err := account.AsAaveDebtAccount()
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve Aave debt account at index %d: %w", accountIndex, err)
+		}
+
+		component.tokenAddress, err = p.aaveDebtAssetResolver.ResolveAssetAddress(ctx, aaveDebtAccount)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve token address for Aave debt account '%s' with debt token address '%s': %w", aaveDebtAccount.AccountName, aaveDebtAccount.DebtTokenAddress, err)
+		}
+
+		component.tokenBalance, err = p.aaveDebtBalanceFetcher.FetchBalance(ctx, aaveDebtAccount)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve balance of Aave debt token '%s' for address '%s': %w", aaveDebtAccount.DebtTokenAddress, aaveDebtAccount.WalletAddress, err)
+		}
+
+		position.syncableAccount = aaveDebtAccount.SyncableAccount
+		position.onchainAsset = aaveDebtAccount.OnchainAsset
+		position.isDebt = true
+	case config.AddressTypeCometSupply:
+		cometSupplyAccount, err := account.AsCometSupplyAccount()
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve Comet supply account at index %d: %w", accountIndex, err)
+		}
+
+		component.tokenAddress, err = p.cometSupplyAssetResolver.ResolveAssetAddress(ctx, cometSupplyAccount)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve token address for Comet supply account '%s' with Comet address '%s': %w", cometSupplyAccount.AccountName, cometSupplyAccount.CometAddress, err)
+		}
+
+		component.tokenBalance, err = p.cometSupplyBalanceFetcher.FetchBalance(ctx, cometSupplyAccount)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve supplied balance of Comet '%s' for address '%s': %w", cometSupplyAccount.CometAddress, cometSupplyAccount.WalletAddress, err)
+		}
+
+		position.syncableAccount = cometSupplyAccount.SyncableAccount
+		position.onchainAsset = cometSupplyAccount.OnchainAsset
+	case config.AddressTypeCometDebt:
+		cometDebtAccount, err := account.AsCometDebtAccount()
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve Comet debt account at index %d: %w", accountIndex, err)
+		}
+
+		component.tokenAddress, err = p.cometDebtAssetResolver.ResolveAssetAddress(ctx, cometDebtAccount)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve token address for Comet debt account '%s' with Comet address '%s': %w", cometDebtAccount.AccountName, cometDebtAccount.CometAddress, err)
+		}
+
+		component.tokenBalance, err = p.cometDebtBalanceFetcher.FetchBalance(ctx, cometDebtAccount)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve borrow balance of Comet '%s' for address '%s': %w", cometDebtAccount.CometAddress, cometDebtAccount.WalletAddress, err)
+		}
+
+		position.syncableAccount = cometDebtAccount.SyncableAccount
+		position.onchainAsset = cometDebtAccount.OnchainAsset
+		position.isDebt = true
+	case config.AddressTypeLiquidStaking:
+		liquidStakingAccount, err := account.AsLiquidStakingAccount()
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve liquid staking account at index %d: %w", accountIndex, err)
+		}
+
+		component.tokenAddress, err = p.liquidStakingAssetResolver.ResolveAssetAddress(ctx, liquidStakingAccount)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve token address for liquid staking account '%s': %w", liquidStakingAccount.AccountName, err)
+		}
+
+		component.tokenBalance, err = p.liquidStakingBalanceFetcher.FetchBalance(ctx, liquidStakingAccount)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve balance of liquid staking token '%s' for address '%s': %w", liquidStakingAccount.TokenAddress, liquidStakingAccount.WalletAddress, err)
+		}
+
+		position.syncableAccount = liquidStakingAccount.SyncableAccount
+		position.onchainAsset = liquidStakingAccount.OnchainAsset
+	case config.AddressTypeVirtualPriceLP:
+		virtualPriceLPAccount, err := account.AsVirtualPriceLPAccount()
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve virtual price LP account at index %d: %w", accountIndex, err)
+		}
+
+		component.tokenAddress, err = p.virtualPriceLPAssetResolver.ResolveAssetAddress(ctx, virtualPriceLPAccount)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve token address for virtual price LP account '%s': %w", virtualPriceLPAccount.AccountName, err)
+		}
+
+		component.tokenBalance, err = p.virtualPriceLPBalanceFetcher.FetchBalance(ctx, virtualPriceLPAccount)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve balance of LP token '%s' of pool '%s' for address '%s': %w", virtualPriceLPAccount.TokenAddress, virtualPriceLPAccount.PoolAddress, virtualPriceLPAccount.WalletAddress, err)
+		}
+
+		position.syncableAccount = virtualPriceLPAccount.SyncableAccount
+		position.onchainAsset = virtualPriceLPAccount.OnchainAsset
+	case config.AddressTypeLPV2:
+		lpV2Account, err := account.AsLPV2Account()
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve LP V2 account at index %d: %w", accountIndex, err)
+		}
+
+		poolShare, err := p.lpV2Fetcher.FetchPoolShare(ctx, lpV2Account)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve share of pair '%s' for address '%s': %w", lpV2Account.PairAddress, lpV2Account.WalletAddress, err)
+		}
+
+		for _, pooledAsset := range poolShare.Assets {
+			position.components = append(position.components, &positionComponent{
+				tokenAddress: &pooledAsset.TokenAddress,
+				tokenBalance: pooledAsset.Balance,
+			})
+		}
+
+		position.shareBalance = poolShare.Balance
+		position.shareDecimals, err = p.decimalsResolver.ResolveDecimals(ctx, lpV2Account.OnchainAsset, &lpV2Account.PairAddress)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve decimals of the LP token of pair '%s': %w", lpV2Account.PairAddress, err)
+		}
+
+		position.syncableAccount = lpV2Account.SyncableAccount
+		position.onchainAsset = lpV2Account.OnchainAsset
+	case config.AddressTypeUniswapV3:
+		uniswapV3Account, err := account.AsUniswapV3Account()
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve Uniswap V3 account at index %d: %w", accountIndex, err)
+		}
+
+		pooledAssets, err := p.uniswapV3Fetcher.FetchPooledAssets(ctx, uniswapV3Account)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve positions of position manager '%s' for address '%s': %w", uniswapV3Account.PositionManagerAddress, uniswapV3Account.WalletAddress, err)
+		}
+
+		// A wallet without any positions holds nothing, rather than a single asset
+		position.components = []*positionComponent{}
+		for _, pooledAsset := range pooledAssets {
+			position.components = append(position.components, &positionComponent{
+				tokenAddress: &pooledAsset.TokenAddress,
+				tokenBalance: pooledAsset.Balance,
+			})
+		}
+
+		position.syncableAccount = uniswapV3Account.SyncableAccount
+		position.onchainAsset = uniswapV3Account.OnchainAsset
+	default:
+		return nil, fmt.Errorf("unsupported address type '%s' for account at index %d", addressType, accountIndex)
+	}
+
+	if position.components == nil {
+		position.components = []*positionComponent{component}
+	}
+
+	position.price, err = account.GetPrice()
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve price for account '%s': %w", position.syncableAccount.AccountName, err)
+	} else if position.price != nil && len(position.components) > 1 {
+		return nil, fmt.Errorf("a price cannot be configured for account '%s', as each of the assets of which its position consists is priced by its own address", position.syncableAccount.AccountName)
+	}
+
+	for _, component := range position.components {
+		// A debt is owed, so it is valued as a negative balance
+		if position.isDebt {
+			component.tokenBalance = new(big.Int).Neg(component.tokenBalance)
+		}
+
+		component.tokenDecimals, err = p.decimalsResolver.ResolveDecimals(ctx, position.onchainAsset, component.tokenAddress)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve token decimals for account '%s': %w", position.syncableAccount.AccountName, err)
+		}
+	}
+
+	rpcURL, err := token.ResolveRPCURL(ctx, p.rpcConfigurationResolver, position.onchainAsset, chain.TypeEVM)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve RPC URL for account '%s': %w", position.syncableAccount.AccountName, err)
+	}
+
+	position.blockNumber, err = p.blockResolver.ResolveBlock(ctx, rpcURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve block for account '%s': %w", position.syncableAccount.AccountName, err)
+	}
+
+	return position, nil
+}
+
+// resolveAggregatePosition resolves the position of the given account, which aggregates the given holdings, each of which is resolved as its own position.
+func (p *positionResolver) resolveAggregatePosition(ctx context.Context, accountIndex int, account config.AccountProperties, holdingAccounts []config.AccountProperties) (*accountPosition, error) {
+	syncableAccount, err := account.AsSyncableAccount()
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve aggregate account at index %d: %w", accountIndex, err)
+	}
+
+	holdings, err := p.resolvePositions(ctx, holdingAccounts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve holdings for account at index %d: %w", accountIndex, err)
+	}
+
+	// An aggregate is only an amount owed if all of its holdings are; otherwise, its debts are netted against its other holdings
+	position := &accountPosition{
+		syncableAccount: *syncableAccount,
+		holdings:        holdings,
+		isDebt:          true,
+	}
+	for _, holding := range holdings {
+		position.isDebt = position.isDebt && holding.isDebt
+	}
+
+	return position, nil
+}
@@ -0,0 +1,213 @@
+package coingecko
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	coingeckoconfig "github.com/jrh3k5/cryptonabber-sync/v3/config/coingecko"
+	synchttp "github.com/jrh3k5/cryptonabber-sync/v3/http"
+)
+
+const (
+	publicAPIHost = "api.coingecko.com"
+	proAPIHost    = "pro-api.coingecko.com"
+
+	// maxRetryDelay is the longest that a request is delayed before being retried, regardless of what Coingecko asks for.
+	maxRetryDelay = 2 * time.Minute
+)
+
+// DefaultRequestsPerMinute gets the number of requests that can be made per minute under the given plan
+// without exceeding its rate limit.
+func DefaultRequestsPerMinute(plan coingeckoconfig.APIPlan) int {
+	switch plan {
+	case coingeckoconfig.APIPlanDemo:
+		return 30
+	case coingeckoconfig.APIPlanPro:
+		return 500
+	default:
+		return 5
+	}
+}
+
+// APIDoer is a synchttp.Doer that makes requests to Coingecko's API under an API plan.
+//
+// Requests are authenticated with the plan's API key and, for the pro plan, sent to the pro API's host.
+// Requests are spaced out to stay within the given number of requests per minute, and requests that are rate-limited
+// or that fail due to the unavailability of the API are retried, honoring the Retry-After header if Coingecko provides it
+// and otherwise backing off exponentially. Requests to hosts other than Coingecko's API are passed through unchanged.
+type APIDoer struct {
+	doer              synchttp.Doer
+	plan              coingeckoconfig.APIPlan
+	apiKey            string
+	requestInterval   time.Duration // the minimum amount of time between requests
+	maxRetries        int
+	initialRetryDelay time.Duration // the delay before the first retry of a request if Coingecko does not specify one; doubled for each subsequent retry
+
+	pacingMutex   sync.Mutex
+	nextRequestAt time.Time
+}
+
+// NewAPIDoer builds an APIDoer that makes at most the given number of requests per minute under the given plan with the given API key,
+// retrying a request at most the given number of times.
+func NewAPIDoer(doer synchttp.Doer, plan coingeckoconfig.APIPlan, apiKey string, requestsPerMinute int, maxRetries int, initialRetryDelay time.Duration) *APIDoer {
+	var requestInterval time.Duration
+	if requestsPerMinute > 0 {
+		requestInterval = time.Minute / time.Duration(requestsPerMinute)
+	}
+
+	return &APIDoer{
+		doer:              doer,
+		plan:              plan,
+		apiKey:            apiKey,
+		requestInterval:   requestInterval,
+		maxRetries:        maxRetries,
+		initialRetryDelay: initialRetryDelay,
+	}
+}
+
+func (a *APIDoer) Do(request *http.Request) (*http.Response, error) {
+	if request.URL.Host != publicAPIHost {
+		return a.doer.Do(request)
+	}
+
+	ctx := request.Context()
+	for attempt := 0; ; attempt++ {
+		if err := a.awaitTurn(ctx); err != nil {
+			return nil, err
+		}
+
+		attemptRequest, err := a.buildAttemptRequest(request)
+		if err != nil {
+			return nil, err
+		}
+
+		response, err := a.doer.Do(attemptRequest)
+		if err != nil {
+			return nil, err
+		}
+
+		if !isRetryableStatus(response.StatusCode) || attempt >= a.maxRetries {
+			return response, nil
+		}
+
+		retryDelay := a.getRetryDelay(response, attempt)
+		_, _ = io.Copy(io.Discard, response.Body)
+		_ = response.Body.Close()
+
+		// Hold back all requests, not just this one, as the rate limit applies to all of them
+		a.deferRequests(retryDelay)
+	}
+}
+
+// buildAttemptRequest builds the request to be sent for an attempt of the given request, authenticated according to the plan.
+func (a *APIDoer) buildAttemptRequest(request *http.Request) (*http.Request, error) {
+	attemptRequest := request.Clone(request.Context())
+	if request.Body != nil && request.GetBody != nil {
+		body, err := request.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get request body: %w", err)
+		}
+		attemptRequest.Body = body
+	}
+
+	switch a.plan {
+	case coingeckoconfig.APIPlanDemo:
+		attemptRequest.Header.Set("x-cg-demo-api-key", a.apiKey)
+	case coingeckoconfig.APIPlanPro:
+		attemptRequest.URL.Host = proAPIHost
+		attemptRequest.Host = ""
+		attemptRequest.Header.Set("x-cg-pro-api-key", a.apiKey)
+	}
+
+	return attemptRequest, nil
+}
+
+// awaitTurn waits until a request can be made without exceeding the request budget.
+func (a *APIDoer) awaitTurn(ctx context.Context) error {
+	a.pacingMutex.Lock()
+	now := time.Now()
+	requestAt := a.nextRequestAt
+	if requestAt.Before(now) {
+		requestAt = now
+	}
+	a.nextRequestAt = requestAt.Add(a.requestInterval)
+	a.pacingMutex.Unlock()
+
+	return sleep(ctx, requestAt.Sub(now))
+}
+
+// deferRequests holds back requests that have not yet been made for at least the given amount of time.
+func (a *APIDoer) deferRequests(delay time.Duration) {
+	a.pacingMutex.Lock()
+	defer a.pacingMutex.Unlock()
+
+	if deferredUntil := time.Now().Add(delay); a.nextRequestAt.Before(deferredUntil) {
+		a.nextRequestAt = deferredUntil
+	}
+}
+
+// getRetryDelay determines how long to wait before retrying a request that received the given response on the given attempt.
+func (a *APIDoer) getRetryDelay(response *http.Response, attempt int) time.Duration {
+	if retryAfter, hasRetryAfter := parseRetryAfter(response.Header.Get("Retry-After")); hasRetryAfter {
+		return max(0, min(retryAfter, maxRetryDelay))
+	}
+
+	delay := a.initialRetryDelay
+	for range attempt {
+		if delay >= maxRetryDelay {
+			break
+		}
+		delay *= 2
+	}
+
+	return min(delay, maxRetryDelay)
+}
+
+// parseRetryAfter parses the value of a Retry-After header, which is either a number of seconds or an HTTP date.
+func parseRetryAfter(retryAfter string) (time.Duration, bool) {
+	if retryAfter == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(retryAfter); err == nil {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if retryAt, err := http.ParseTime(retryAfter); err == nil {
+		return time.Until(retryAt), true
+	}
+
+	return 0, false
+}
+
+// isRetryableStatus determines whether a request that received a response with the given status code should be retried.
+func isRetryableStatus(statusCode int) bool {
+	switch statusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+
+	return false
+}
+
+// sleep waits for the given amount of time, or until the given context is done.
+func sleep(ctx context.Context, duration time.Duration) error {
+	if duration <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
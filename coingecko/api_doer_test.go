@@ -0,0 +1,140 @@
+package coingecko_test
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/jrh3k5/cryptonabber-sync/v3/coingecko"
+	coingeckoconfig "github.com/jrh3k5/cryptonabber-sync/v3/config/coingecko"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("APIDoer", func() {
+	const (
+		publicURL = "https://api.coingecko.com/api/v3/ping"
+		proURL    = "https://pro-api.coingecko.com/api/v3/ping"
+	)
+
+	var ctx context.Context
+
+	// respondWith registers a responder for the given URL that responds with the given responses in order,
+	// repeating the last one; the returned function reports the requests that have been received.
+	respondWith := func(requestURL string, responses ...*http.Response) func() []*http.Request {
+		var requests []*http.Request
+		httpmock.RegisterResponder(http.MethodGet, requestURL, func(request *http.Request) (*http.Response, error) {
+			requests = append(requests, request)
+			return responses[min(len(requests), len(responses))-1], nil
+		})
+
+		return func() []*http.Request {
+			return requests
+		}
+	}
+
+	newResponse := func(status int, retryAfter string) *http.Response {
+		response := httpmock.NewStringResponse(status, "{}")
+		if retryAfter != "" {
+			response.Header.Set("Retry-After", retryAfter)
+		}
+
+		return response
+	}
+
+	get := func(doer *coingecko.APIDoer, requestURL string) *http.Response {
+		request, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+		Expect(err).ToNot(HaveOccurred(), "building the request should not fail")
+
+		response, err := doer.Do(request)
+		Expect(err).ToNot(HaveOccurred(), "executing the request should not fail")
+		return response
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	It("authenticates requests under the demo plan", func() {
+		requests := respondWith(publicURL, newResponse(http.StatusOK, ""))
+
+		doer := coingecko.NewAPIDoer(http.DefaultClient, coingeckoconfig.APIPlanDemo, "demo-key", 0, 0, 0)
+		get(doer, publicURL)
+
+		Expect(requests()).To(HaveLen(1), "one request should be made")
+		Expect(requests()[0].Header.Get("x-cg-demo-api-key")).To(Equal("demo-key"), "the demo API key should be supplied")
+	})
+
+	It("sends requests under the pro plan to the pro API", func() {
+		requests := respondWith(proURL, newResponse(http.StatusOK, ""))
+
+		doer := coingecko.NewAPIDoer(http.DefaultClient, coingeckoconfig.APIPlanPro, "pro-key", 0, 0, 0)
+		get(doer, publicURL)
+
+		Expect(requests()).To(HaveLen(1), "one request should be made to the pro API")
+		Expect(requests()[0].Header.Get("x-cg-pro-api-key")).To(Equal("pro-key"), "the pro API key should be supplied")
+	})
+
+	It("retries rate-limited requests after the requested delay", func() {
+		requests := respondWith(publicURL, newResponse(http.StatusTooManyRequests, "1"), newResponse(http.StatusOK, ""))
+
+		doer := coingecko.NewAPIDoer(http.DefaultClient, coingeckoconfig.APIPlanPublic, "", 0, 3, time.Millisecond)
+		startTime := time.Now()
+		response := get(doer, publicURL)
+
+		Expect(response.StatusCode).To(Equal(http.StatusOK), "the retried request should succeed")
+		Expect(requests()).To(HaveLen(2), "the request should have been retried once")
+		Expect(time.Since(startTime)).To(BeNumerically(">=", time.Second), "the Retry-After header should be honored")
+	})
+
+	It("backs off exponentially if no delay is requested", func() {
+		requests := respondWith(publicURL,
+			newResponse(http.StatusServiceUnavailable, ""),
+			newResponse(http.StatusServiceUnavailable, ""),
+			newResponse(http.StatusServiceUnavailable, ""),
+			newResponse(http.StatusOK, ""))
+
+		doer := coingecko.NewAPIDoer(http.DefaultClient, coingeckoconfig.APIPlanPublic, "", 0, 3, 20*time.Millisecond)
+		startTime := time.Now()
+		response := get(doer, publicURL)
+
+		Expect(response.StatusCode).To(Equal(http.StatusOK), "the retried request should succeed")
+		Expect(requests()).To(HaveLen(4), "the request should have been retried three times")
+		Expect(time.Since(startTime)).To(BeNumerically(">=", (20+40+80)*time.Millisecond), "the delays between retries should double")
+	})
+
+	It("returns the last response once retries are exhausted", func() {
+		requests := respondWith(publicURL, newResponse(http.StatusTooManyRequests, "0"))
+
+		doer := coingecko.NewAPIDoer(http.DefaultClient, coingeckoconfig.APIPlanPublic, "", 0, 2, time.Millisecond)
+		response := get(doer, publicURL)
+
+		Expect(response.StatusCode).To(Equal(http.StatusTooManyRequests), "the rate-limited response should be returned")
+		Expect(requests()).To(HaveLen(3), "the request should have been retried twice")
+	})
+
+	It("spaces requests out to stay within the request budget", func() {
+		respondWith(publicURL, newResponse(http.StatusOK, ""))
+
+		doer := coingecko.NewAPIDoer(http.DefaultClient, coingeckoconfig.APIPlanPublic, "", 1200, 0, 0)
+		startTime := time.Now()
+		for range 3 {
+			get(doer, publicURL)
+		}
+
+		Expect(time.Since(startTime)).To(BeNumerically(">=", 100*time.Millisecond), "the requests should be at least 50ms apart")
+	})
+
+	It("passes through requests to other hosts", func() {
+		otherURL := "https://example.com/other"
+		requests := respondWith(otherURL, newResponse(http.StatusTooManyRequests, "0"))
+
+		doer := coingecko.NewAPIDoer(http.DefaultClient, coingeckoconfig.APIPlanDemo, "demo-key", 0, 3, 0)
+		response := get(doer, otherURL)
+
+		Expect(response.StatusCode).To(Equal(http.StatusTooManyRequests), "the response should be returned as-is")
+		Expect(requests()).To(HaveLen(1), "the request should not be retried")
+		Expect(requests()[0].Header.Get("x-cg-demo-api-key")).To(BeEmpty(), "the API key should not be sent to other hosts")
+	})
+})
@@ -12,7 +12,13 @@ type AssetPlatformIDResolver interface {
 	ResolveForChainID(ctx context.Context, chainID *big.Int) (string, error)
 }
 
-// SimpleAssetPlatformIDResolver resolves asset platform IDs based on a hardcoded list.
+// NativeCoinIDResolver is a resolver for the Coingecko coin ID of a chain's native coin.
+type NativeCoinIDResolver interface {
+	// ResolveNativeCoinIDForChainID tries to resolve the Coingecko coin ID of the native coin for the given chain ID
+	ResolveNativeCoinIDForChainID(ctx context.Context, chainID *big.Int) (string, error)
+}
+
+// SimpleAssetPlatformIDResolver resolves asset platform IDs and native coin IDs based on a hardcoded list.
 type SimpleAssetPlatformIDResolver struct {
 }
 
@@ -36,3 +42,16 @@ func (*SimpleAssetPlatformIDResolver) ResolveForChainID(ctx context.Context, cha
 
 	return "", fmt.Errorf("unsupported chain ID value: %d", chainID.Int64())
 }
+
+func (*SimpleAssetPlatformIDResolver) ResolveNativeCoinIDForChainID(ctx context.Context, chainID *big.Int) (string, error) {
+	switch chainID.Text(10) {
+	case "1", "8453", "42161":
+		return "ethereum", nil
+	case "137":
+		return "polygon-ecosystem-token", nil
+	case "43114":
+		return "avalanche-2", nil
+	}
+
+	return "", fmt.Errorf("unsupported chain ID value: %d", chainID.Int64())
+}
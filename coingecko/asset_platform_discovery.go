@@ -0,0 +1,215 @@
+package coingecko
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	coingeckoconfig "github.com/jrh3k5/cryptonabber-sync/v3/config/coingecko"
+	synchttp "github.com/jrh3k5/cryptonabber-sync/v3/http"
+)
+
+// HTTPAssetPlatformIDResolver is an AssetPlatformIDResolver and NativeCoinIDResolver that resolves
+// asset platforms using the list of asset platforms published by Coingecko's HTTP API.
+//
+// The list is retrieved at most once per run and is cached on disk; if the cached list has expired and
+// the list cannot be retrieved, the expired list is used. Configured asset platforms take precedence over the list.
+type HTTPAssetPlatformIDResolver struct {
+	doer      synchttp.Doer
+	cacheFile string                                   // the location of the file in which the list is cached; if blank, the list is not cached on disk
+	cacheTTL  time.Duration                            // how long the list cached on disk is used before it is retrieved again
+	overrides map[string]coingeckoconfig.AssetPlatform // chain ID -> configured asset platform
+
+	loadOnce  sync.Once
+	platforms map[string]assetPlatform // chain ID -> asset platform
+	loadErr   error
+}
+
+// NewHTTPAssetPlatformIDResolver builds an HTTPAssetPlatformIDResolver that caches the list of asset platforms in the given file
+// for the given amount of time, and that uses the given asset platforms in preference to those listed by Coingecko.
+func NewHTTPAssetPlatformIDResolver(doer synchttp.Doer, cacheFile string, cacheTTL time.Duration, overrides []coingeckoconfig.AssetPlatform) *HTTPAssetPlatformIDResolver {
+	overridesByChainID := make(map[string]coingeckoconfig.AssetPlatform)
+	for _, override := range overrides {
+		overridesByChainID[new(big.Int).SetUint64(override.ChainID).Text(10)] = override
+	}
+
+	return &HTTPAssetPlatformIDResolver{
+		doer:      doer,
+		cacheFile: cacheFile,
+		cacheTTL:  cacheTTL,
+		overrides: overridesByChainID,
+	}
+}
+
+func (h *HTTPAssetPlatformIDResolver) ResolveForChainID(ctx context.Context, chainID *big.Int) (string, error) {
+	if override, hasOverride := h.overrides[chainID.Text(10)]; hasOverride && override.AssetPlatformID != "" {
+		return override.AssetPlatformID, nil
+	}
+
+	platform, err := h.resolvePlatform(ctx, chainID)
+	if err != nil {
+		return "", err
+	}
+
+	return platform.ID, nil
+}
+
+func (h *HTTPAssetPlatformIDResolver) ResolveNativeCoinIDForChainID(ctx context.Context, chainID *big.Int) (string, error) {
+	if override, hasOverride := h.overrides[chainID.Text(10)]; hasOverride && override.NativeCoinID != "" {
+		return override.NativeCoinID, nil
+	}
+
+	platform, err := h.resolvePlatform(ctx, chainID)
+	if err != nil {
+		return "", err
+	}
+
+	if platform.NativeCoinID == "" {
+		return "", fmt.Errorf("Coingecko does not list a native coin for chain ID %v; please configure one under coingecko.asset_platforms", chainID)
+	}
+
+	return platform.NativeCoinID, nil
+}
+
+func (h *HTTPAssetPlatformIDResolver) resolvePlatform(ctx context.Context, chainID *big.Int) (assetPlatform, error) {
+	h.loadOnce.Do(func() {
+		h.platforms, h.loadErr = h.loadPlatforms(context.WithoutCancel(ctx))
+	})
+
+	if h.loadErr != nil {
+		return assetPlatform{}, fmt.Errorf("failed to load Coingecko asset platforms: %w", h.loadErr)
+	}
+
+	platform, hasPlatform := h.platforms[chainID.Text(10)]
+	if !hasPlatform {
+		return assetPlatform{}, fmt.Errorf("no Coingecko asset platform found for chain ID %v; please configure one under coingecko.asset_platforms", chainID)
+	}
+
+	return platform, nil
+}
+
+// loadPlatforms loads the asset platforms from the cache file, if it has not expired, or from Coingecko.
+func (h *HTTPAssetPlatformIDResolver) loadPlatforms(ctx context.Context) (map[string]assetPlatform, error) {
+	cached, cacheErr := h.readCache()
+	if cacheErr == nil && time.Since(cached.FetchedAt) < h.cacheTTL {
+		return indexPlatforms(cached.AssetPlatforms), nil
+	}
+
+	platforms, fetchErr := h.fetchPlatforms(ctx)
+	if fetchErr != nil {
+		if cacheErr == nil {
+			// An out-of-date list is better than none at all
+			return indexPlatforms(cached.AssetPlatforms), nil
+		}
+
+		return nil, fetchErr
+	}
+
+	// The list is used even if it cannot be cached
+	_ = h.writeCache(platforms)
+
+	return indexPlatforms(platforms), nil
+}
+
+func (h *HTTPAssetPlatformIDResolver) fetchPlatforms(ctx context.Context) ([]assetPlatform, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://api.coingecko.com/api/v3/asset_platforms", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+
+	response, err := h.doer.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer func() {
+		_ = response.Body.Close()
+	}()
+
+	if response.StatusCode != http.StatusOK {
+		statusErr := synchttp.BuildUnexpectedStatusErr(response)
+		return nil, statusErr
+	}
+
+	var platforms []assetPlatform
+	if unmarshalErr := json.NewDecoder(response.Body).Decode(&platforms); unmarshalErr != nil {
+		return nil, fmt.Errorf("failed to unmarshal response body: %w", unmarshalErr)
+	}
+
+	return platforms, nil
+}
+
+func (h *HTTPAssetPlatformIDResolver) readCache() (*assetPlatformCache, error) {
+	if h.cacheFile == "" {
+		return nil, errors.New("no cache file configured")
+	}
+
+	cacheBytes, err := os.ReadFile(h.cacheFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache file '%s': %w", h.cacheFile, err)
+	}
+
+	cached := &assetPlatformCache{}
+	if unmarshalErr := json.Unmarshal(cacheBytes, cached); unmarshalErr != nil {
+		return nil, fmt.Errorf("failed to unmarshal cache file '%s': %w", h.cacheFile, unmarshalErr)
+	}
+
+	return cached, nil
+}
+
+func (h *HTTPAssetPlatformIDResolver) writeCache(platforms []assetPlatform) error {
+	if h.cacheFile == "" {
+		return nil
+	}
+
+	cacheBytes, err := json.Marshal(&assetPlatformCache{
+		FetchedAt:      time.Now(),
+		AssetPlatforms: platforms,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(h.cacheFile), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory for cache file '%s': %w", h.cacheFile, err)
+	}
+
+	if err := os.WriteFile(h.cacheFile, cacheBytes, 0o644); err != nil {
+		return fmt.Errorf("failed to write cache file '%s': %w", h.cacheFile, err)
+	}
+
+	return nil
+}
+
+// indexPlatforms indexes the given asset platforms by chain ID, omitting those that are not associated with a chain ID.
+func indexPlatforms(platforms []assetPlatform) map[string]assetPlatform {
+	indexed := make(map[string]assetPlatform)
+	for _, platform := range platforms {
+		if platform.ChainIdentifier == nil {
+			continue
+		}
+
+		indexed[platform.ChainIdentifier.String()] = platform
+	}
+
+	return indexed
+}
+
+// assetPlatform is an asset platform as listed by Coingecko's /asset_platforms endpoint.
+type assetPlatform struct {
+	ID              string       `json:"id"`
+	ChainIdentifier *json.Number `json:"chain_identifier"`
+	NativeCoinID    string       `json:"native_coin_id"`
+}
+
+// assetPlatformCache is the content of the file in which asset platforms are cached.
+type assetPlatformCache struct {
+	FetchedAt      time.Time       `json:"fetched_at"`
+	AssetPlatforms []assetPlatform `json:"asset_platforms"`
+}
@@ -0,0 +1,148 @@
+package coingecko_test
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/jrh3k5/cryptonabber-sync/v3/coingecko"
+	coingeckoconfig "github.com/jrh3k5/cryptonabber-sync/v3/config/coingecko"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("HTTPAssetPlatformIDResolver", func() {
+	const assetPlatformsURL = "https://api.coingecko.com/api/v3/asset_platforms"
+
+	var ctx context.Context
+	var cacheFile string
+	var responseStatus int
+	var requestCount int
+
+	assetPlatformsJSON := `[
+		{ "id": "optimistic-ethereum", "chain_identifier": 10, "name": "Optimism", "native_coin_id": "ethereum" },
+		{ "id": "binance-smart-chain", "chain_identifier": 56, "name": "BNB Smart Chain", "native_coin_id": "binancecoin" },
+		{ "id": "no-chain", "chain_identifier": null, "name": "No Chain", "native_coin_id": "no-chain" },
+		{ "id": "no-native-coin", "chain_identifier": 12345, "name": "No Native Coin", "native_coin_id": "" }
+	]`
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		cacheFile = filepath.Join(GinkgoT().TempDir(), "cache", "asset_platforms.json")
+
+		responseStatus = http.StatusOK
+		requestCount = 0
+		httpmock.RegisterResponder(http.MethodGet, assetPlatformsURL, func(_ *http.Request) (*http.Response, error) {
+			requestCount++
+			if responseStatus != http.StatusOK {
+				return httpmock.NewStringResponse(responseStatus, "unavailable"), nil
+			}
+
+			return httpmock.NewStringResponse(http.StatusOK, assetPlatformsJSON), nil
+		})
+	})
+
+	It("resolves the asset platform and native coin listed for a chain", func() {
+		resolver := coingecko.NewHTTPAssetPlatformIDResolver(http.DefaultClient, cacheFile, time.Hour, nil)
+
+		assetPlatformID, err := resolver.ResolveForChainID(ctx, big.NewInt(56))
+		Expect(err).ToNot(HaveOccurred(), "resolving the asset platform should not fail")
+		Expect(assetPlatformID).To(Equal("binance-smart-chain"), "the listed asset platform should be resolved")
+
+		nativeCoinID, err := resolver.ResolveNativeCoinIDForChainID(ctx, big.NewInt(56))
+		Expect(err).ToNot(HaveOccurred(), "resolving the native coin should not fail")
+		Expect(nativeCoinID).To(Equal("binancecoin"), "the listed native coin should be resolved")
+
+		Expect(requestCount).To(Equal(1), "the list should only be retrieved once")
+	})
+
+	It("fails for a chain that is not listed", func() {
+		resolver := coingecko.NewHTTPAssetPlatformIDResolver(http.DefaultClient, cacheFile, time.Hour, nil)
+
+		_, err := resolver.ResolveForChainID(ctx, big.NewInt(534352))
+		Expect(err).To(HaveOccurred(), "resolving an unlisted chain should fail")
+	})
+
+	It("fails for a chain without a listed native coin", func() {
+		resolver := coingecko.NewHTTPAssetPlatformIDResolver(http.DefaultClient, cacheFile, time.Hour, nil)
+
+		_, err := resolver.ResolveNativeCoinIDForChainID(ctx, big.NewInt(12345))
+		Expect(err).To(HaveOccurred(), "resolving the native coin should fail")
+	})
+
+	It("prefers configured asset platforms", func() {
+		resolver := coingecko.NewHTTPAssetPlatformIDResolver(http.DefaultClient, cacheFile, time.Hour, []coingeckoconfig.AssetPlatform{
+			{ChainID: 10, AssetPlatformID: "overridden-platform"},
+			{ChainID: 534352, AssetPlatformID: "scroll", NativeCoinID: "ethereum"},
+		})
+
+		assetPlatformID, err := resolver.ResolveForChainID(ctx, big.NewInt(10))
+		Expect(err).ToNot(HaveOccurred(), "resolving the asset platform should not fail")
+		Expect(assetPlatformID).To(Equal("overridden-platform"), "the configured asset platform should be used")
+
+		nativeCoinID, err := resolver.ResolveNativeCoinIDForChainID(ctx, big.NewInt(10))
+		Expect(err).ToNot(HaveOccurred(), "resolving the native coin should not fail")
+		Expect(nativeCoinID).To(Equal("ethereum"), "the listed native coin should be used if none is configured")
+
+		assetPlatformID, err = resolver.ResolveForChainID(ctx, big.NewInt(534352))
+		Expect(err).ToNot(HaveOccurred(), "resolving the asset platform should not fail")
+		Expect(assetPlatformID).To(Equal("scroll"), "the configured asset platform should be used for an unlisted chain")
+	})
+
+	Context("caching", func() {
+		It("reuses the list cached on disk", func() {
+			_, err := coingecko.NewHTTPAssetPlatformIDResolver(http.DefaultClient, cacheFile, time.Hour, nil).ResolveForChainID(ctx, big.NewInt(10))
+			Expect(err).ToNot(HaveOccurred(), "resolving the asset platform should not fail")
+
+			assetPlatformID, err := coingecko.NewHTTPAssetPlatformIDResolver(http.DefaultClient, cacheFile, time.Hour, nil).ResolveForChainID(ctx, big.NewInt(10))
+			Expect(err).ToNot(HaveOccurred(), "resolving the asset platform from the cache should not fail")
+			Expect(assetPlatformID).To(Equal("optimistic-ethereum"), "the cached asset platform should be resolved")
+
+			Expect(requestCount).To(Equal(1), "the cached list should be used")
+		})
+
+		When("the cached list has expired", func() {
+			BeforeEach(func() {
+				_, err := coingecko.NewHTTPAssetPlatformIDResolver(http.DefaultClient, cacheFile, time.Hour, nil).ResolveForChainID(ctx, big.NewInt(10))
+				Expect(err).ToNot(HaveOccurred(), "resolving the asset platform should not fail")
+
+				cacheBytes, err := os.ReadFile(cacheFile)
+				Expect(err).ToNot(HaveOccurred(), "the cache file should be readable")
+
+				var cache map[string]any
+				Expect(json.Unmarshal(cacheBytes, &cache)).To(Succeed(), "the cache file should be valid JSON")
+				cache["fetched_at"] = time.Now().Add(-2 * time.Hour)
+
+				cacheBytes, err = json.Marshal(cache)
+				Expect(err).ToNot(HaveOccurred(), "the cache should be serializable")
+				Expect(os.WriteFile(cacheFile, cacheBytes, 0o644)).To(Succeed(), "the cache file should be writable")
+			})
+
+			It("retrieves the list again", func() {
+				_, err := coingecko.NewHTTPAssetPlatformIDResolver(http.DefaultClient, cacheFile, time.Hour, nil).ResolveForChainID(ctx, big.NewInt(10))
+				Expect(err).ToNot(HaveOccurred(), "resolving the asset platform should not fail")
+				Expect(requestCount).To(Equal(2), "the list should be retrieved again")
+			})
+
+			It("uses the expired list if the list cannot be retrieved", func() {
+				responseStatus = http.StatusServiceUnavailable
+
+				assetPlatformID, err := coingecko.NewHTTPAssetPlatformIDResolver(http.DefaultClient, cacheFile, time.Hour, nil).ResolveForChainID(ctx, big.NewInt(10))
+				Expect(err).ToNot(HaveOccurred(), "resolving the asset platform should not fail")
+				Expect(assetPlatformID).To(Equal("optimistic-ethereum"), "the expired list should be used")
+			})
+		})
+
+		It("fails if the list cannot be retrieved and nothing is cached", func() {
+			responseStatus = http.StatusServiceUnavailable
+
+			_, err := coingecko.NewHTTPAssetPlatformIDResolver(http.DefaultClient, cacheFile, time.Hour, nil).ResolveForChainID(ctx, big.NewInt(10))
+			Expect(err).To(HaveOccurred(), "resolving the asset platform should fail")
+		})
+	})
+})
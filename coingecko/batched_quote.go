@@ -0,0 +1,277 @@
+package coingecko
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+
+	synchttp "github.com/jrh3k5/cryptonabber-sync/v3/http"
+	"github.com/jrh3k5/cryptonabber-sync/v3/money"
+)
+
+// maxQuoteBatchSize is the maximum number of coins or contract addresses whose quotes are requested in a single request.
+const maxQuoteBatchSize = 100
+
+// BatchedQuoteResolver is a QuoteResolver that resolves quotes in batches using Coingecko's /simple/price
+// and /simple/token_price endpoints.
+//
+// Coins and tokens whose quotes will be needed can be registered ahead of time with RegisterCoin and RegisterToken;
+// the first time a quote is resolved, the quotes of all registered coins (or, for a token, all registered tokens on the same
+// asset platform) are retrieved together. Each quote is retrieved at most once, so resolving the same quote repeatedly
+// does not result in further requests; resolving a quote that is already being retrieved waits for that retrieval.
+type BatchedQuoteResolver struct {
+	doer synchttp.Doer
+
+	stateMutex sync.Mutex
+	pending    map[batchedQuoteGroup]map[string]bool // IDs awaiting retrieval
+	inFlight   map[batchedQuoteKey]chan struct{}     // quotes being retrieved -> channel closed once the retrieval is done
+	quotes     map[batchedQuoteKey]*Quote            // resolved quotes; nil if no quote was found
+}
+
+// NewBatchedQuoteResolver builds a BatchedQuoteResolver.
+func NewBatchedQuoteResolver(doer synchttp.Doer) *BatchedQuoteResolver {
+	return &BatchedQuoteResolver{
+		doer:     doer,
+		pending:  make(map[batchedQuoteGroup]map[string]bool),
+		inFlight: make(map[batchedQuoteKey]chan struct{}),
+		quotes:   make(map[batchedQuoteKey]*Quote),
+	}
+}
+
+// RegisterCoin registers the coin with the given Coingecko coin ID as one whose quote in the given currency will be resolved.
+func (b *BatchedQuoteResolver) RegisterCoin(coinID string, currency money.Currency) {
+	b.stateMutex.Lock()
+	defer b.stateMutex.Unlock()
+
+	b.register(batchedQuoteKey{quoteCode: currency.QuoteCode(), id: coinID})
+}
+
+// RegisterToken registers the given contract address on the given asset platform as one whose quote in the given currency will be resolved.
+func (b *BatchedQuoteResolver) RegisterToken(assetPlatformID string, contractAddress string, currency money.Currency) {
+	b.stateMutex.Lock()
+	defer b.stateMutex.Unlock()
+
+	b.register(batchedQuoteKey{quoteCode: currency.QuoteCode(), assetPlatformID: assetPlatformID, id: strings.ToLower(contractAddress)})
+}
+
+func (b *BatchedQuoteResolver) ResolveCoinQuote(ctx context.Context, coinID string, currency money.Currency) (Quote, bool, error) {
+	quoteCode := currency.QuoteCode()
+	key := batchedQuoteKey{
+		quoteCode: quoteCode,
+		id:        coinID,
+	}
+
+	return b.resolve(ctx, key, func(coinIDs []string) string {
+		return fmt.Sprintf("https://api.coingecko.com/api/v3/simple/price?ids=%s&vs_currencies=%s&include_last_updated_at=true", url.QueryEscape(strings.Join(coinIDs, ",")), url.QueryEscape(quoteCode))
+	})
+}
+
+func (b *BatchedQuoteResolver) ResolveQuote(ctx context.Context, assetPlatformID string, contractAddress string, currency money.Currency) (Quote, bool, error) {
+	quoteCode := currency.QuoteCode()
+	key := batchedQuoteKey{
+		quoteCode:       quoteCode,
+		assetPlatformID: assetPlatformID,
+		id:              strings.ToLower(contractAddress),
+	}
+
+	return b.resolve(ctx, key, func(contractAddresses []string) string {
+		return fmt.Sprintf("https://api.coingecko.com/api/v3/simple/token_price/%s?contract_addresses=%s&vs_currencies=%s&include_last_updated_at=true", url.PathEscape(assetPlatformID), url.QueryEscape(strings.Join(contractAddresses, ",")), url.QueryEscape(quoteCode))
+	})
+}
+
+// resolve resolves the quote with the given key, retrieving it along with the quotes of every other pending ID of its group
+// from the URLs built by the given function if it has not yet been retrieved.
+// The state of the resolver is not locked while quotes are retrieved, so that resolving quotes that have already been
+// retrieved is not held up by a slow request.
+func (b *BatchedQuoteResolver) resolve(ctx context.Context, key batchedQuoteKey, buildRequestURL func(ids []string) string) (Quote, bool, error) {
+	for {
+		b.stateMutex.Lock()
+		if quote, isResolved := b.quotes[key]; isResolved {
+			b.stateMutex.Unlock()
+			return derefQuote(quote)
+		}
+
+		if retrievalDone, isInFlight := b.inFlight[key]; isInFlight {
+			b.stateMutex.Unlock()
+
+			select {
+			case <-retrievalDone:
+				// The retrieval either resolved the quote or failed, leaving it to be retrieved again
+				continue
+			case <-ctx.Done():
+				return Quote{}, false, ctx.Err()
+			}
+		}
+
+		group := key.group()
+		b.register(key)
+		ids := sortedKeys(b.pending[group])
+		delete(b.pending, group)
+
+		retrievalDone := make(chan struct{})
+		for _, id := range ids {
+			b.inFlight[group.key(id)] = retrievalDone
+		}
+		b.stateMutex.Unlock()
+
+		quotes, err := b.fetchAllQuotes(ctx, group, ids, buildRequestURL)
+
+		b.stateMutex.Lock()
+		for _, id := range ids {
+			delete(b.inFlight, group.key(id))
+		}
+		for quoteKey, quote := range quotes {
+			b.quotes[quoteKey] = quote
+		}
+		if err != nil {
+			// Leave anything that could not be retrieved to be retrieved again
+			for _, id := range ids {
+				b.register(group.key(id))
+			}
+		}
+		b.stateMutex.Unlock()
+		close(retrievalDone)
+
+		if err != nil {
+			return Quote{}, false, err
+		}
+	}
+}
+
+// fetchAllQuotes retrieves the quotes for the given IDs of the given group in batches, using the URLs built by the given function.
+// The quotes retrieved before any failure are returned alongside the failure.
+func (b *BatchedQuoteResolver) fetchAllQuotes(ctx context.Context, group batchedQuoteGroup, ids []string, buildRequestURL func(ids []string) string) (map[batchedQuoteKey]*Quote, error) {
+	quotes := make(map[batchedQuoteKey]*Quote, len(ids))
+	for batch := range slices.Chunk(ids, maxQuoteBatchSize) {
+		if err := b.fetchQuotes(ctx, buildRequestURL(batch), group, batch, quotes); err != nil {
+			return quotes, err
+		}
+	}
+
+	return quotes, nil
+}
+
+// fetchQuotes retrieves the quotes for the given IDs from the given URL, which is expected to respond with prices
+// keyed by ID and then by quote code, and records them in the given map.
+func (b *BatchedQuoteResolver) fetchQuotes(ctx context.Context, requestURL string, group batchedQuoteGroup, ids []string, quotes map[batchedQuoteKey]*Quote) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+
+	response, err := b.doer.Do(request)
+	if err != nil {
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer func() {
+		_ = response.Body.Close()
+	}()
+
+	if response.StatusCode != http.StatusOK {
+		statusErr := synchttp.BuildUnexpectedStatusErr(response)
+		return statusErr
+	}
+
+	responseBody := make(map[string]map[string]json.Number)
+	if unmarshalErr := json.NewDecoder(response.Body).Decode(&responseBody); unmarshalErr != nil {
+		return fmt.Errorf("failed to unmarshal response body: %w", unmarshalErr)
+	}
+
+	pricesByID := make(map[string]map[string]json.Number, len(responseBody))
+	for id, prices := range responseBody {
+		pricesByID[strings.ToLower(id)] = prices
+	}
+
+	batchQuotes := make(map[batchedQuoteKey]*Quote, len(ids))
+	for _, id := range ids {
+		prices := pricesByID[strings.ToLower(id)]
+		quote, hasQuote, err := parseQuote(prices[group.quoteCode], prices[lastUpdatedAtKey])
+		if err != nil {
+			return fmt.Errorf("failed to parse quote of '%s': %w", id, err)
+		}
+
+		if hasQuote {
+			batchQuotes[group.key(id)] = &quote
+		} else {
+			batchQuotes[group.key(id)] = nil
+		}
+	}
+
+	maps.Copy(quotes, batchQuotes)
+
+	return nil
+}
+
+// register records the quote with the given key as awaiting retrieval, unless it has already been resolved or is being retrieved.
+// The caller is expected to hold the state mutex.
+func (b *BatchedQuoteResolver) register(key batchedQuoteKey) {
+	if _, isResolved := b.quotes[key]; isResolved {
+		return
+	}
+
+	if _, isInFlight := b.inFlight[key]; isInFlight {
+		return
+	}
+
+	group := key.group()
+	if b.pending[group] == nil {
+		b.pending[group] = make(map[string]bool)
+	}
+	b.pending[group][key.id] = true
+}
+
+// derefQuote translates a recorded quote into the result of resolving it.
+func derefQuote(quote *Quote) (Quote, bool, error) {
+	if quote == nil {
+		return Quote{}, false, nil
+	}
+
+	return *quote, true, nil
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	return keys
+}
+
+// batchedQuoteKey identifies a quote resolved by a BatchedQuoteResolver.
+type batchedQuoteKey struct {
+	quoteCode       string
+	assetPlatformID string // blank for coins
+	id              string // the coin ID or, for tokens, the lowercased contract address
+}
+
+// group gets the group of quotes whose quotes are retrieved together with this quote.
+func (k batchedQuoteKey) group() batchedQuoteGroup {
+	return batchedQuoteGroup{
+		quoteCode:       k.quoteCode,
+		assetPlatformID: k.assetPlatformID,
+	}
+}
+
+// batchedQuoteGroup identifies a group of quotes that are retrieved together by a BatchedQuoteResolver: those of coins, or those
+// of tokens on the same asset platform, in the same currency.
+type batchedQuoteGroup struct {
+	quoteCode       string
+	assetPlatformID string // blank for coins
+}
+
+// key gets the key of the quote of the given ID within this group.
+func (g batchedQuoteGroup) key(id string) batchedQuoteKey {
+	return batchedQuoteKey{
+		quoteCode:       g.quoteCode,
+		assetPlatformID: g.assetPlatformID,
+		id:              id,
+	}
+}
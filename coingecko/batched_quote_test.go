@@ -0,0 +1,178 @@
+package coingecko_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/jrh3k5/cryptonabber-sync/v3/coingecko"
+	"github.com/jrh3k5/cryptonabber-sync/v3/money"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("BatchedQuoteResolver", func() {
+	var resolver *coingecko.BatchedQuoteResolver
+
+	var ctx context.Context
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		resolver = coingecko.NewBatchedQuoteResolver(http.DefaultClient)
+	})
+
+	Context("ResolveQuote", func() {
+		var requestedContractAddresses []string
+		var requestedCurrencies []string
+
+		BeforeEach(func() {
+			requestedContractAddresses = nil
+			requestedCurrencies = nil
+
+			httpmock.RegisterResponder(http.MethodGet, "https://api.coingecko.com/api/v3/simple/token_price/test-batched-platform", func(request *http.Request) (*http.Response, error) {
+				contractAddresses := request.URL.Query().Get("contract_addresses")
+				requestedContractAddresses = append(requestedContractAddresses, contractAddresses)
+				vsCurrencies := request.URL.Query().Get("vs_currencies")
+				requestedCurrencies = append(requestedCurrencies, vsCurrencies)
+
+				prices := map[string]string{
+					"0xaaaa": "1.23",
+					"0xbbbb": "4567",
+				}
+
+				var entries []string
+				for _, contractAddress := range strings.Split(contractAddresses, ",") {
+					if price, hasPrice := prices[contractAddress]; hasPrice {
+						entries = append(entries, fmt.Sprintf(`"%s": { "%s": %s }`, contractAddress, vsCurrencies, price))
+					}
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, "{"+strings.Join(entries, ",")+"}"), nil
+			})
+		})
+
+		It("resolves all registered tokens on the platform in a single request", func() {
+			resolver.RegisterToken("test-batched-platform", "0xAAAA", money.USD)
+			resolver.RegisterToken("test-batched-platform", "0xbbbb", money.USD)
+			resolver.RegisterToken("test-batched-platform", "0xaaaa", money.USD)
+
+			firstQuote, hasFirstQuote, err := resolver.ResolveQuote(ctx, "test-batched-platform", "0xaaaa", money.USD)
+			Expect(err).ToNot(HaveOccurred(), "resolving the first quote should not fail")
+			Expect(hasFirstQuote).To(BeTrue(), "the first quote should be found")
+			Expect(firstQuote.Price.String()).To(Equal("1.23"), "the first price should be parsed")
+
+			secondQuote, hasSecondQuote, err := resolver.ResolveQuote(ctx, "test-batched-platform", "0xBBBB", money.USD)
+			Expect(err).ToNot(HaveOccurred(), "resolving the second quote should not fail")
+			Expect(hasSecondQuote).To(BeTrue(), "the second quote should be found")
+			Expect(secondQuote.Price.String()).To(Equal("4567"), "the second price should be parsed")
+
+			Expect(requestedContractAddresses).To(Equal([]string{"0xaaaa,0xbbbb"}), "the deduplicated contract addresses should be requested together, once")
+			Expect(requestedCurrencies).To(Equal([]string{"usd"}), "the quotes should be requested in the given currency")
+		})
+
+		It("does not request a token that could not be quoted again", func() {
+			_, hasQuote, err := resolver.ResolveQuote(ctx, "test-batched-platform", "0xcccc", money.USD)
+			Expect(err).ToNot(HaveOccurred(), "resolving the quote should not fail")
+			Expect(hasQuote).To(BeFalse(), "no quote should be found")
+
+			_, hasQuote, err = resolver.ResolveQuote(ctx, "test-batched-platform", "0xcccc", money.USD)
+			Expect(err).ToNot(HaveOccurred(), "resolving the quote again should not fail")
+			Expect(hasQuote).To(BeFalse(), "no quote should be found again")
+
+			Expect(requestedContractAddresses).To(HaveLen(1), "the quote should only be requested once")
+		})
+
+		It("requests quotes in different currencies separately", func() {
+			eur := money.CurrencyForCode("EUR")
+			resolver.RegisterToken("test-batched-platform", "0xaaaa", money.USD)
+			resolver.RegisterToken("test-batched-platform", "0xbbbb", eur)
+
+			_, hasQuote, err := resolver.ResolveQuote(ctx, "test-batched-platform", "0xbbbb", eur)
+			Expect(err).ToNot(HaveOccurred(), "resolving the quote should not fail")
+			Expect(hasQuote).To(BeTrue(), "the quote should be found")
+
+			Expect(requestedContractAddresses).To(Equal([]string{"0xbbbb"}), "only the token registered in the currency should be requested")
+			Expect(requestedCurrencies).To(Equal([]string{"eur"}), "the quote should be requested in the given currency")
+		})
+
+		It("does not hold up other quotes while a request is in flight", func() {
+			var slowRequestCount atomic.Int32
+			slowRequestReceived := make(chan struct{}, 1)
+			releaseSlowRequest := make(chan struct{})
+			httpmock.RegisterResponder(http.MethodGet, "https://api.coingecko.com/api/v3/simple/token_price/test-slow-platform", func(_ *http.Request) (*http.Response, error) {
+				slowRequestCount.Add(1)
+				slowRequestReceived <- struct{}{}
+				<-releaseSlowRequest
+
+				return httpmock.NewStringResponse(http.StatusOK, `{ "0xdddd": { "usd": 8.9 } }`), nil
+			})
+
+			slowQuotes := make(chan coingecko.Quote, 2)
+			resolveSlowQuote := func() {
+				defer GinkgoRecover()
+
+				quote, hasQuote, err := resolver.ResolveQuote(ctx, "test-slow-platform", "0xdddd", money.USD)
+				Expect(err).ToNot(HaveOccurred(), "resolving the slow quote should not fail")
+				Expect(hasQuote).To(BeTrue(), "the slow quote should be found")
+				slowQuotes <- quote
+			}
+
+			go resolveSlowQuote()
+			Eventually(slowRequestReceived).Should(Receive(), "the slow quote should be requested")
+			go resolveSlowQuote()
+
+			_, hasQuote, err := resolver.ResolveQuote(ctx, "test-batched-platform", "0xaaaa", money.USD)
+			Expect(err).ToNot(HaveOccurred(), "resolving a quote while another is in flight should not fail")
+			Expect(hasQuote).To(BeTrue(), "the quote should be found while another is in flight")
+
+			close(releaseSlowRequest)
+			for range 2 {
+				var slowQuote coingecko.Quote
+				Eventually(slowQuotes).Should(Receive(&slowQuote), "the slow quote should be resolved once its request completes")
+				Expect(slowQuote.Price.String()).To(Equal("8.9"), "the slow price should be parsed")
+			}
+
+			Expect(slowRequestCount.Load()).To(Equal(int32(1)), "the quote in flight should not be requested again")
+		})
+	})
+
+	Context("ResolveCoinQuote", func() {
+		var requestedCoinIDs []string
+
+		BeforeEach(func() {
+			requestedCoinIDs = nil
+
+			httpmock.RegisterResponder(http.MethodGet, `=~^https:\/\/api\.coingecko\.com\/api\/v3\/simple\/price\?ids=test-batched-coin`, func(request *http.Request) (*http.Response, error) {
+				coinIDs := request.URL.Query().Get("ids")
+				requestedCoinIDs = append(requestedCoinIDs, coinIDs)
+
+				return httpmock.NewStringResponse(http.StatusOK, `{ "test-batched-coin-a": { "usd": 3456.78, "last_updated_at": 1700000000 }, "test-batched-coin-b": { "usd": 25.5 } }`), nil
+			})
+		})
+
+		It("resolves all registered coins in a single request", func() {
+			resolver.RegisterCoin("test-batched-coin-a", money.USD)
+			resolver.RegisterCoin("test-batched-coin-b", money.USD)
+			resolver.RegisterCoin("test-batched-coin-a", money.USD)
+
+			firstQuote, hasFirstQuote, err := resolver.ResolveCoinQuote(ctx, "test-batched-coin-a", money.USD)
+			Expect(err).ToNot(HaveOccurred(), "resolving the first quote should not fail")
+			Expect(hasFirstQuote).To(BeTrue(), "the first quote should be found")
+			Expect(firstQuote.Price.String()).To(Equal("3456.78"), "the first price should be parsed")
+			Expect(firstQuote.LastUpdatedAt).To(Equal(time.Unix(1700000000, 0)), "the time at which the first price was last updated should be parsed")
+
+			secondQuote, hasSecondQuote, err := resolver.ResolveCoinQuote(ctx, "test-batched-coin-b", money.USD)
+			Expect(err).ToNot(HaveOccurred(), "resolving the second quote should not fail")
+			Expect(hasSecondQuote).To(BeTrue(), "the second quote should be found")
+			Expect(secondQuote.Price.String()).To(Equal("25.5"), "the second price should be parsed")
+			Expect(secondQuote.LastUpdatedAt).To(BeZero(), "the second price should have no last update time")
+
+			Expect(requestedCoinIDs).To(Equal([]string{"test-batched-coin-a,test-batched-coin-b"}), "the deduplicated coin IDs should be requested together, once")
+		})
+	})
+})
@@ -3,153 +3,58 @@ package coingecko
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
-	"math"
-	"net/http"
-	"strconv"
-	"strings"
+	"time"
 
-	synchttp "github.com/jrh3k5/cryptonabber-sync/v3/http"
+	"github.com/jrh3k5/cryptonabber-sync/v3/money"
 )
 
+// Quote is the per-token price of a coin or token as given by Coingecko.
+type Quote struct {
+	Price         money.Decimal
+	LastUpdatedAt time.Time // when Coingecko last updated the price; zero if Coingecko did not say
+}
+
 // QuoteResolver can be used to resolve quotes.
 type QuoteResolver interface {
-	// ResolveETHQuote resolves a quote for Ether.
-	// It returns dollars and cents. The cents is expressed as a floating value relative to whole dollars to account for assets that may
-	// have a value of less than 1 cent per whole token.
-	// This method enforces the assumption that there is always a price of ETH available.
-	ResolveETHQuote(ctx context.Context) (int64, float64, error)
+	// ResolveCoinQuote resolves the per-token price, in the given currency, of the coin with the given Coingecko coin ID (e.g., "ethereum").
+	// The returned bool is true if a quote was found; false if not.
+	ResolveCoinQuote(ctx context.Context, coinID string, currency money.Currency) (Quote, bool, error)
 
-	// ResolveQuote resolves a quote for the given contract address on the given asset platform.
-	// It returns dollars and cents. The cents is expressed as a floating value relative to whole dollars to account for assets that may
-	// have a value of less than 1 cent per whole token.
+	// ResolveQuote resolves the per-token price, in the given currency, of the given contract address on the given asset platform.
 	// The returned bool is true if a quote was found; false if not.
-	ResolveQuote(ctx context.Context, assetPlatformID string, contractAddress string) (int64, float64, bool, error)
+	ResolveQuote(ctx context.Context, assetPlatformID string, contractAddress string, currency money.Currency) (Quote, bool, error)
 }
 
-// HTTPQuoteResolver is a QuoteResolver that uses Coingecko's HTTP API to resolve quotes.
-type HTTPQuoteResolver struct {
-	doer synchttp.Doer
-}
+// lastUpdatedAtKey is the key under which Coingecko's /simple endpoints give the Unix time at which a price was last updated,
+// alongside the price itself.
+const lastUpdatedAtKey = "last_updated_at"
 
-func NewHTTPQuoteResolver(doer synchttp.Doer) *HTTPQuoteResolver {
-	return &HTTPQuoteResolver{
-		doer: doer,
+// parseQuote parses a price, and the Unix time at which it was last updated, as given by Coingecko.
+// The returned bool is false if no price was given; a quote without a last update time is given a zero LastUpdatedAt.
+func parseQuote(price json.Number, lastUpdatedAt json.Number) (Quote, bool, error) {
+	priceString := price.String()
+	if priceString == "" {
+		return Quote{}, false, nil
 	}
-}
 
-func (q *HTTPQuoteResolver) ResolveETHQuote(ctx context.Context) (int64, float64, error) {
-	requestURL := "https://api.coingecko.com/api/v3/simple/price?ids=ethereum&vs_currencies=usd&include_market_cap=false&include_24hr_vol=false&include_24hr_change=false&include_last_updated_at=false&precision=false"
-	request, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	parsedPrice, err := money.ParseDecimal(priceString)
 	if err != nil {
-		return 0, 0, fmt.Errorf("failed to build request: %w", err)
-	}
-
-	response, err := q.doer.Do(request)
-	if err != nil {
-		return 0, 0, fmt.Errorf("failed to execute request: %w", err)
-	}
-
-	if response.StatusCode != http.StatusOK {
-		statusErr := synchttp.BuildUnexpectedStatusErr(response)
-		return 0, 0, statusErr
-	}
-	defer func() {
-		_ = response.Body.Close()
-	}()
-
-	responseBody := make(map[string]map[string]json.Number)
-	if unmarshalErr := json.NewDecoder(response.Body).Decode(&responseBody); unmarshalErr != nil {
-		return 0, 0, fmt.Errorf("failed to unmarshal response body: %w", unmarshalErr)
-	}
-
-	ethereumPrices, hasEthereum := responseBody["ethereum"]
-	if !hasEthereum {
-		return 0, 0, errors.New("ethereum not returned in response")
+		return Quote{}, false, fmt.Errorf("failed to parse price: %w", err)
 	}
 
-	usdPrice, hasUSD := ethereumPrices["usd"]
-	if !hasUSD {
-		return 0, 0, errors.New("USD price not found in Ethereum prices")
+	quote := Quote{
+		Price: parsedPrice,
 	}
 
-	dollars, centsRatio, _, err := q.parsePrice(usdPrice)
-	if err != nil {
-		return 0, 0, fmt.Errorf("failed to parse price: %w", err)
-	}
+	if lastUpdatedAt.String() != "" {
+		lastUpdatedAtSeconds, err := lastUpdatedAt.Int64()
+		if err != nil {
+			return Quote{}, false, fmt.Errorf("failed to parse last update time '%s': %w", lastUpdatedAt, err)
+		}
 
-	return dollars, centsRatio, nil
-}
-
-func (q *HTTPQuoteResolver) ResolveQuote(ctx context.Context, assetPlatformID string, contractAddress string) (int64, float64, bool, error) {
-	requestURL := fmt.Sprintf("https://api.coingecko.com/api/v3/coins/%s/contract/%s", assetPlatformID, contractAddress)
-	request, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
-	if err != nil {
-		return 0, 0, false, fmt.Errorf("failed to build request: %w", err)
-	}
-
-	response, err := q.doer.Do(request)
-	if err != nil {
-		return 0, 0, false, fmt.Errorf("failed to execute request: %w", err)
-	} else if response.StatusCode == http.StatusNotFound {
-		return 0, 0, false, nil
-	} else if response.StatusCode != http.StatusOK {
-		statusErr := synchttp.BuildUnexpectedStatusErr(response)
-		return 0, 0, false, statusErr
+		quote.LastUpdatedAt = time.Unix(lastUpdatedAtSeconds, 0)
 	}
-	defer func() {
-		_ = response.Body.Close()
-	}()
-
-	responseBody := &coinDetailsResponse{}
-	if unmarshalErr := json.NewDecoder(response.Body).Decode(responseBody); unmarshalErr != nil {
-		return 0, 0, false, fmt.Errorf("failed to unmarshal response body: %w", unmarshalErr)
-	}
-
-	if responseBody.MarketData == nil || responseBody.MarketData.CurrentPrice == nil {
-		return 0, 0, false, nil
-	}
-
-	usdPrice, hasUSD := responseBody.MarketData.CurrentPrice["usd"]
-	if !hasUSD {
-		return 0, 0, false, nil
-	}
-
-	return q.parsePrice(usdPrice)
-}
-
-func (*HTTPQuoteResolver) parsePrice(price json.Number) (int64, float64, bool, error) {
-	usdPriceString := price.String()
-	if usdPriceString == "" {
-		return 0, 0, false, nil
-	}
-
-	splitUSD := strings.Split(usdPriceString, ".")
-	dollars, parseErr := strconv.ParseInt(splitUSD[0], 10, 64)
-	if parseErr != nil {
-		return 0, 0, false, fmt.Errorf("failed to parse USD dollars '%s': %w", splitUSD[0], parseErr)
-	}
-
-	if len(splitUSD) == 1 {
-		return dollars, 0, true, nil
-	}
-
-	centsString := splitUSD[1]
-	cents, parseErr := strconv.ParseInt(centsString, 10, 64)
-	if parseErr != nil {
-		return 0, 0, false, fmt.Errorf("failed to parse USD cents '%s': %w", splitUSD[1], parseErr)
-	}
-
-	centsRatio := float64(cents) / math.Pow10(len(centsString))
-
-	return dollars, centsRatio, true, nil
-}
-
-type coinDetailsResponse struct {
-	MarketData *coinDetailMarketData `json:"market_data"`
-}
 
-type coinDetailMarketData struct {
-	CurrentPrice map[string]json.Number `json:"current_price"`
+	return quote, true, nil
 }
@@ -0,0 +1,54 @@
+package coingecko
+
+import (
+	"fmt"
+	"time"
+)
+
+// APIPlan describes the Coingecko API plan under which requests are made.
+type APIPlan string
+
+const (
+	APIPlanPublic APIPlan = "public" // the anonymous public API
+	APIPlanDemo   APIPlan = "demo"   // the Demo plan, which requires a demo API key
+	APIPlanPro    APIPlan = "pro"    // any of the paid plans, which require a pro API key
+)
+
+// Configuration describes how to use Coingecko.
+type Configuration struct {
+	APIKey                string          `yaml:"api_key"`                  // the API key with which requests are made; if blank, the anonymous public API is used
+	APIPlan               APIPlan         `yaml:"api_plan"`                 // the plan to which the API key belongs; if blank, "demo" is assumed for an API key
+	RequestsPerMinute     int             `yaml:"requests_per_minute"`      // the maximum number of requests to be made per minute; if zero, a limit appropriate to the plan is used
+	AssetPlatforms        []AssetPlatform `yaml:"asset_platforms"`          // asset platforms for chains that Coingecko does not associate with a chain ID, or whose association is to be overridden
+	AssetPlatformCacheTTL time.Duration   `yaml:"asset_platform_cache_ttl"` // how long the list of asset platforms retrieved from Coingecko is cached on disk; if zero, a default is used
+}
+
+// Plan resolves the plan under which requests are to be made.
+// An error is returned if the configured plan is unknown or if it requires an API key that has not been configured.
+func (c Configuration) Plan() (APIPlan, error) {
+	switch c.APIPlan {
+	case "":
+		if c.APIKey == "" {
+			return APIPlanPublic, nil
+		}
+
+		return APIPlanDemo, nil
+	case APIPlanPublic:
+		return APIPlanPublic, nil
+	case APIPlanDemo, APIPlanPro:
+		if c.APIKey == "" {
+			return "", fmt.Errorf("an API key is required for the '%s' plan", c.APIPlan)
+		}
+
+		return c.APIPlan, nil
+	}
+
+	return "", fmt.Errorf("unknown API plan: '%s'", c.APIPlan)
+}
+
+// AssetPlatform describes the Coingecko asset platform for a chain.
+type AssetPlatform struct {
+	ChainID         uint64 `yaml:"chain_id"`          // the ID of the chain
+	AssetPlatformID string `yaml:"asset_platform_id"` // the ID of the Coingecko asset platform for the chain (e.g., "optimistic-ethereum")
+	NativeCoinID    string `yaml:"native_coin_id"`    // the Coingecko coin ID of the native coin of the chain (e.g., "ethereum")
+}
@@ -0,0 +1,108 @@
+package price
+
+import (
+	"fmt"
+	"time"
+)
+
+// ProviderName is the name of a source of prices.
+type ProviderName string
+
+const (
+	ProviderChainlink ProviderName = "chainlink" // Chainlink price feeds, read onchain
+	ProviderCoingecko ProviderName = "coingecko" // Coingecko's API
+	ProviderDefiLlama ProviderName = "defillama" // DefiLlama's coins API
+	ProviderDEX       ProviderName = "dex"       // DEX liquidity pools, read onchain
+)
+
+// DefaultProviders gets the providers that are consulted, in order, if none are configured.
+// DefiLlama is not consulted by default, as it would be sent the address of every priced asset; it must be configured to be used.
+func DefaultProviders() []ProviderName {
+	return []ProviderName{ProviderChainlink, ProviderDEX, ProviderCoingecko}
+}
+
+// RateChangeAction is what is done with an account whose rate has changed by more than the maximum since the previous sync.
+type RateChangeAction string
+
+const (
+	RateChangeActionSkip RateChangeAction = "skip" // the account is not updated
+	RateChangeActionFlag RateChangeAction = "flag" // the account is updated, and its adjustment transaction is flagged for review
+)
+
+// Configuration describes how assets are priced.
+type Configuration struct {
+	Providers            []ProviderName   `yaml:"providers"`               // the providers to be consulted for prices, in order; if empty, DefaultProviders is used
+	ChainlinkFeeds       []ChainlinkFeed  `yaml:"chainlink_feeds"`         // the Chainlink price feeds used to price assets
+	DefiLlamaChains      []DefiLlamaChain `yaml:"defillama_chains"`        // the DefiLlama names of chains that are not known by default, or whose names are to be overridden
+	DEXPools             []DEXPool        `yaml:"dex_pools"`               // the DEX liquidity pools used to price assets
+	MaxQuoteAge          time.Duration    `yaml:"max_quote_age"`           // quotes last updated longer ago than this are rejected; if zero, quotes of any age are accepted
+	MaxRateChangePercent float64          `yaml:"max_rate_change_percent"` // the largest change, in percent, in an account's rate since the previous sync that is accepted as-is; if zero, rates are not compared
+	RateChangeAction     RateChangeAction `yaml:"rate_change_action"`      // what is done with an account whose rate has changed by more than MaxRateChangePercent; if blank, RateChangeActionSkip
+	QuoteCacheTTL        time.Duration    `yaml:"quote_cache_ttl"`         // how long quotes are cached on disk; if zero, a default is used
+}
+
+// ProviderOrder resolves the providers to be consulted for prices, in order.
+// An error is returned if an unknown provider is configured.
+func (c Configuration) ProviderOrder() ([]ProviderName, error) {
+	if len(c.Providers) == 0 {
+		return DefaultProviders(), nil
+	}
+
+	for _, provider := range c.Providers {
+		switch provider {
+		case ProviderChainlink, ProviderCoingecko, ProviderDefiLlama, ProviderDEX:
+		default:
+			return nil, fmt.Errorf("unknown price provider '%s'", provider)
+		}
+	}
+
+	return c.Providers, nil
+}
+
+// RateChangeActionOrDefault resolves what is to be done with an account whose rate has changed by more than the maximum since the previous sync.
+// An error is returned if an unknown action, or a negative maximum change, is configured.
+func (c Configuration) RateChangeActionOrDefault() (RateChangeAction, error) {
+	if c.MaxRateChangePercent < 0 {
+		return "", fmt.Errorf("maximum rate change must not be negative: %v", c.MaxRateChangePercent)
+	}
+
+	switch c.RateChangeAction {
+	case "":
+		return RateChangeActionSkip, nil
+	case RateChangeActionSkip, RateChangeActionFlag:
+		return c.RateChangeAction, nil
+	}
+
+	return "", fmt.Errorf("unknown rate change action '%s'", c.RateChangeAction)
+}
+
+// ChainlinkFeed describes a Chainlink price feed (i.e., an AggregatorV3Interface contract) that prices an asset.
+type ChainlinkFeed struct {
+	ChainName    string `yaml:"chain_name"`    // the chain name of the RPC configuration of the chain on which the priced asset resides
+	TokenAddress string `yaml:"token_address"` // the address of the priced asset; blank for the chain's native coin
+	FeedAddress  string `yaml:"feed_address"`  // the address of the price feed, which must reside on the same chain as the asset
+	Currency     string `yaml:"currency"`      // the ISO 4217 code of the currency in which the feed is denominated; if blank, USD is assumed
+}
+
+// DefiLlamaChain associates a chain with the name by which DefiLlama refers to it.
+type DefiLlamaChain struct {
+	ChainID uint64 `yaml:"chain_id"` // the ID of the chain
+	Chain   string `yaml:"chain"`    // the name of the chain in DefiLlama's coins API (e.g., "ethereum" or "arbitrum")
+}
+
+// DEXPoolType is the type of a DEX liquidity pool.
+type DEXPoolType string
+
+const (
+	DEXPoolTypeUniswapV2 DEXPoolType = "uniswap_v2" // a Uniswap V2 pair, or a pair of a fork with the same interface
+	DEXPoolTypeUniswapV3 DEXPoolType = "uniswap_v3" // a Uniswap V3 pool, or a pool of a fork with the same interface
+)
+
+// DEXPool describes a DEX liquidity pool that prices an asset relative to the other asset in the pool.
+type DEXPool struct {
+	ChainName    string        `yaml:"chain_name"`    // the chain name of the RPC configuration of the chain on which the priced asset and the pool reside
+	TokenAddress string        `yaml:"token_address"` // the address of the priced asset
+	PoolAddress  string        `yaml:"pool_address"`  // the address of the pool
+	PoolType     DEXPoolType   `yaml:"pool_type"`     // the type of the pool
+	TWAPPeriod   time.Duration `yaml:"twap_period"`   // for Uniswap V3 pools, the period over which a time-weighted average price is taken; if zero, the current price is used
+}
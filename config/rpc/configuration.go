@@ -1,10 +1,59 @@
 package rpc
 
-import "github.com/jrh3k5/cryptonabber-sync/v3/config/chain"
+import (
+	"sort"
+	"time"
+
+	"github.com/jrh3k5/cryptonabber-sync/v3/config/chain"
+)
 
 // Configuration describes how to communciate with an RPC node.
 type Configuration struct {
-	RPCURL    string     `yaml:"rpc_url"`    // the URL of the RPC node
-	ChainName string     `yaml:"chain_name"` // the name of the chain
-	ChainType chain.Type `yaml:"chain_type"` // the type of the chain
+	RPCURL            string     `yaml:"rpc_url"`              // the URL of the RPC node
+	RPCURLs           []Endpoint `yaml:"rpc_urls"`             // additional RPC nodes for the chain, to which requests fail over if a node is unavailable
+	ChainName         string     `yaml:"chain_name"`           // the name of the chain
+	ChainType         chain.Type `yaml:"chain_type"`           // the type of the chain
+	ChainID           *uint64    `yaml:"chain_id"`             // if set, the chain ID that the RPC node must report for the chain; a node reporting any other chain ID is treated as misconfigured
+	CrossCheckChainID bool       `yaml:"cross_check_chain_id"` // if true, all of the RPC nodes for the chain are checked to agree on the chain ID before they are used
+	BlockNumber       *uint64    `yaml:"block_number"`         // if set, the block at which all reads against the chain are made; otherwise, the latest block as of the start of the sync is used
+	BlockTimestamp    *time.Time `yaml:"block_timestamp"`      // if set, all reads against the chain are made at the last block at or before this time; cannot be used with BlockNumber
+}
+
+// Endpoint describes one of multiple RPC nodes for a chain.
+type Endpoint struct {
+	URL      string `yaml:"url"`      // the URL of the RPC node
+	Priority int    `yaml:"priority"` // the priority of the RPC node; nodes with lower values are used first
+}
+
+// Endpoints returns all of the RPC nodes configured for the chain, in the order in which they should be used.
+// The node given by RPCURL, if any, has a priority of 0; nodes of equal priority are used in the order in which they are configured.
+func (c Configuration) Endpoints() []Endpoint {
+	var endpoints []Endpoint
+	if c.RPCURL != "" {
+		endpoints = append(endpoints, Endpoint{URL: c.RPCURL})
+	}
+
+	for _, endpoint := range c.RPCURLs {
+		if endpoint.URL != "" {
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+
+	sort.SliceStable(endpoints, func(i, j int) bool {
+		return endpoints[i].Priority < endpoints[j].Priority
+	})
+
+	return endpoints
+}
+
+// PrimaryURL returns the URL of the RPC node that should be used first for the chain.
+// Requests made to this URL are failed over to the chain's other nodes by the FailoverDoer in the http/json/rpc package.
+// Returns an empty string if no RPC node is configured.
+func (c Configuration) PrimaryURL() string {
+	endpoints := c.Endpoints()
+	if len(endpoints) == 0 {
+		return ""
+	}
+
+	return endpoints[0].URL
 }
@@ -6,7 +6,10 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strconv"
 
+	"github.com/jrh3k5/cryptonabber-sync/v3/config/coingecko"
+	"github.com/jrh3k5/cryptonabber-sync/v3/config/price"
 	"github.com/jrh3k5/cryptonabber-sync/v3/config/rpc"
 	"gopkg.in/yaml.v3"
 )
@@ -17,26 +20,92 @@ type AddressType string
 // AccountProperties is the configuration for an account
 type AccountProperties map[string]any
 
-const (
-	AddressTypeERC20        AddressType = "erc20"         // describes an ERC20 token
-	AddressTypeERC4626      AddressType = "erc4626"       // describes an ERC4626 vault
-	AddressTypeERC20Wrapper AddressType = "erc20_wrapper" // describes an ERC20 wrapper
+// VaultConversionArgument describes the argument taken by the function that converts a vault's shares into its asset
+type VaultConversionArgument string
 
-	balanceFunctionDefault = "balanceOf"
+const (
+	AddressTypeERC20          AddressType = "erc20"            // describes an ERC20 token
+	AddressTypeERC4626        AddressType = "erc4626"          // describes an ERC4626 vault
+	AddressTypeERC20Wrapper   AddressType = "erc20_wrapper"    // describes an ERC20 wrapper
+	AddressTypeNative         AddressType = "native"           // describes the native coin of a chain (e.g., ETH on Ethereum)
+	AddressTypeAaveSupply     AddressType = "aave_supply"      // describes an Aave v3 aToken
+	AddressTypeAaveDebt       AddressType = "aave_debt"        // describes an Aave v3 variable or stable debt token
+	AddressTypeCometSupply    AddressType = "comet_supply"     // describes a supply of the base asset of a Compound v3 (Comet) market
+	AddressTypeCometDebt      AddressType = "comet_debt"       // describes a borrow position in a Compound v3 (Comet) market
+	AddressTypeLPV2           AddressType = "lp_v2"            // describes the LP token of a Uniswap V2-style pair
+	AddressTypeUniswapV3      AddressType = "uniswap_v3"       // describes the Uniswap V3-style concentrated liquidity positions held by a wallet
+	AddressTypeLiquidStaking  AddressType = "liquid_staking"   // describes a liquid staking token that accrues value through an exchange rate (e.g., wstETH)
+	AddressTypeVirtualPriceLP AddressType = "virtual_price_lp" // describes the LP token of a pool that reports the value of its LP token in a reference asset (e.g., Curve, Balancer)
+
+	balanceFunctionDefault      = "balanceOf"
+	rateDecimalsDefault         = 18
+	virtualPriceFunctionDefault = "get_virtual_price"
+
+	VaultConversionArgumentShares VaultConversionArgument = "shares" // the function takes an amount of shares and returns the amount of the asset to which they are worth (e.g., convertToAssets)
+	VaultConversionArgumentNone   VaultConversionArgument = "none"   // the function takes no arguments and returns the amount of the asset to which one share is worth (e.g., pricePerShare)
+
+	vaultTypeERC4626 = "erc4626"
 
 	fieldAccountName              = "account_name"
 	fieldAddressType              = "address_type"
+	fieldAssetFunction            = "asset_function"
 	fieldBackingAsset             = "backing_asset"
 	fieldBalanceFunction          = "balance_function"
 	fieldBaseTokenAddressFunction = "base_token_address_function"
+	fieldChainID                  = "chain_id"
+	fieldChainName                = "chain_name"
+	fieldCometAddress             = "comet_address"
+	fieldCoingeckoCoinID          = "coingecko_coin_id"
 	fieldContractAddress          = "contract_address"
+	fieldConversionArgument       = "conversion_argument"
+	fieldConversionDecimals       = "conversion_decimals"
+	fieldConversionFunction       = "conversion_function"
+	fieldFixed                    = "fixed"
+	fieldHoldings                 = "holdings"
+	fieldIncludeUncollectedFees   = "include_uncollected_fees"
+	fieldPairAddress              = "pair_address"
 	fieldPayeeName                = "payee_name"
+	fieldPeggedTo                 = "pegged_to"
+	fieldPoolAddress              = "pool_address"
+	fieldPositionManagerAddress   = "position_manager_address"
+	fieldPrice                    = "price"
+	fieldRateDecimals             = "rate_decimals"
+	fieldRateFunction             = "rate_function"
+	fieldReferenceAsset           = "reference_asset"
 	fieldTokenAddress             = "token_address"
 	fieldTransactionCategoryName  = "transaction_category_name"
+	fieldUnderlyingTokenAddress   = "underlying_token_address"
 	fieldVaultAddress             = "vault_address"
+	fieldVaultType                = "vault_type"
 	fieldWalletAddress            = "wallet_address"
 )
 
+// vaultPreset describes how the asset of a family of vaults that predates ERC4626 is resolved, and how its shares are converted into that asset.
+type vaultPreset struct {
+	assetFunction string
+	conversion    *VaultConversion
+}
+
+// vaultPresets are the presets of the vault families that can be configured by their vault type; ERC4626 vaults need no preset.
+var vaultPresets = map[string]vaultPreset{
+	vaultTypeERC4626: {},
+	"yearn_v2": {
+		assetFunction: "token",
+		conversion: &VaultConversion{
+			Function: "pricePerShare",
+			Argument: VaultConversionArgumentNone,
+		},
+	},
+	"beefy": {
+		assetFunction: "want",
+		conversion: &VaultConversion{
+			Function: "getPricePerFullShare",
+			Argument: VaultConversionArgumentNone,
+			Decimals: intPointer(18),
+		},
+	},
+}
+
 // FromFile builds a SyncConfig out of the contents of a YAML file at the given location.
 func FromFile(fileLocation string) (*SyncConfig, error) {
 	file, err := os.ReadFile(fileLocation)
@@ -54,14 +123,40 @@ func FromYAML(reader io.Reader) (*SyncConfig, error) {
 		return nil, fmt.Errorf("failed to unmarshal configuration: %w", unmarshalErr)
 	}
 
+	if err := syncConfig.validateAccounts(); err != nil {
+		return nil, fmt.Errorf("invalid account configuration: %w", err)
+	}
+
 	return syncConfig, nil
 }
 
 // SyncConfig is the overall configuration for the application.
 type SyncConfig struct {
-	BudgetName        string              `yaml:"ynab_budget_name"`
-	Accounts          []AccountProperties `yaml:"ynab_accounts"`
-	RPCConfigurations []rpc.Configuration `yaml:"rpc_configurations"`
+	BudgetName        string                  `yaml:"ynab_budget_name"`
+	Accounts          []AccountProperties     `yaml:"ynab_accounts"`
+	RPCConfigurations []rpc.Configuration     `yaml:"rpc_configurations"`
+	Coingecko         coingecko.Configuration `yaml:"coingecko"`
+	Pricing           price.Configuration     `yaml:"pricing"`
+	Currency          string                  `yaml:"currency"` // the ISO 4217 code of the currency in which balances are valued; if blank, the currency of the budget is used
+}
+
+// validateAccounts checks that each YNAB account is configured by a single entry, as each entry is synced to its account on its own.
+func (s *SyncConfig) validateAccounts() error {
+	accountIndices := make(map[string]int, len(s.Accounts))
+	for accountIndex, account := range s.Accounts {
+		// An entry without a valid account name is rejected once it is resolved
+		accountName, hasAccountName, err := account.stringProperty(fieldAccountName)
+		if err != nil || !hasAccountName {
+			continue
+		}
+
+		if previousIndex, isDuplicate := accountIndices[accountName]; isDuplicate {
+			return fmt.Errorf("account '%s' is configured by the entries at indices %d and %d; list its holdings within a single entry instead", accountName, previousIndex, accountIndex)
+		}
+		accountIndices[accountName] = accountIndex
+	}
+
+	return nil
 }
 
 // GetAddressType resolves the type of the address represented by the account properties
@@ -152,6 +247,30 @@ func (a AccountProperties) AsERC4626Account() (*ERC4626Account, error) {
 		}
 	}
 
+	vaultType, hasVaultType, err := a.stringProperty(fieldVaultType)
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve vault type: %w", err)
+	} else if !hasVaultType {
+		vaultType = vaultTypeERC4626
+	}
+
+	preset, hasPreset := vaultPresets[vaultType]
+	if !hasPreset {
+		return nil, fmt.Errorf("unsupported vault type '%s'", vaultType)
+	}
+
+	assetFunction, hasAssetFunction, err := a.stringProperty(fieldAssetFunction)
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve asset function: %w", err)
+	} else if !hasAssetFunction {
+		assetFunction = preset.assetFunction
+	}
+
+	conversion, err := a.asVaultConversion(preset.conversion)
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve conversion: %w", err)
+	}
+
 	return &ERC4626Account{
 		SyncableAccount:     *syncableAccount,
 		OnchainWallet:       *onchainWallet,
@@ -159,6 +278,8 @@ func (a AccountProperties) AsERC4626Account() (*ERC4626Account, error) {
 		VaultAddress:        vaultAddress,
 		BalanceFunctionName: balanceFunction,
 		BackingAsset:        backingAsset,
+		AssetFunction:       assetFunction,
+		Conversion:          conversion,
 	}, nil
 }
 
@@ -189,8 +310,506 @@ func (a AccountProperties) AsERC20WrapperAccount() (*ERC20WrapperAccount, error)
 	}, nil
 }
 
+// AsNativeAccount resolves the account properties into a native coin account
+func (a AccountProperties) AsNativeAccount() (*NativeAccount, error) {
+	addressType, err := a.GetAddressType()
+	if err != nil {
+		return nil, err
+	} else if addressType != AddressTypeNative {
+		return nil, fmt.Errorf("invalid address type: %s", addressType)
+	}
+
+	syncableAccount, err := a.asSyncableAccount()
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve syncable account: %w", err)
+	}
+
+	onchainWallet, err := a.asOnchainWallet()
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve onchain wallet: %w", err)
+	}
+
+	onchainAsset, err := a.asOnchainAsset()
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve onchain asset: %w", err)
+	}
+
+	return &NativeAccount{
+		SyncableAccount: *syncableAccount,
+		OnchainWallet:   *onchainWallet,
+		OnchainAsset:    *onchainAsset,
+	}, nil
+}
+
+// AsAaveSupplyAccount resolves the account properties into an Aave supply account
+func (a AccountProperties) AsAaveSupplyAccount() (*AaveSupplyAccount, error) {
+	addressType, err := a.GetAddressType()
+	if err != nil {
+		return nil, err
+	} else if addressType != AddressTypeAaveSupply {
+		return nil, fmt.Errorf("invalid address type: %s", addressType)
+	}
+
+	erc20Account, err := a.toERC20AccountType()
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve erc20 account: %w", err)
+	}
+
+	return &AaveSupplyAccount{
+		SyncableAccount: erc20Account.SyncableAccount,
+		OnchainAsset:    erc20Account.OnchainAsset,
+		OnchainWallet:   erc20Account.OnchainWallet,
+		ATokenAddress:   erc20Account.TokenAddress,
+	}, nil
+}
+
+// AsAaveDebtAccount resolves the account properties into an Aave debt account
+func (a AccountProperties) AsAaveDebtAccount() (*AaveDebtAccount, error) {
+	addressType, err := a.GetAddressType()
+	if err != nil {
+		return nil, err
+	} else if addressType != AddressTypeAaveDebt {
+		return nil, fmt.Errorf("invalid address type: %s", addressType)
+	}
+
+	erc20Account, err := a.toERC20AccountType()
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve erc20 account: %w", err)
+	}
+
+	return &AaveDebtAccount{
+		SyncableAccount:  erc20Account.SyncableAccount,
+		OnchainAsset:     erc20Account.OnchainAsset,
+		OnchainWallet:    erc20Account.OnchainWallet,
+		DebtTokenAddress: erc20Account.TokenAddress,
+	}, nil
+}
+
+// AsCometSupplyAccount resolves the account properties into a Compound v3 supply account
+func (a AccountProperties) AsCometSupplyAccount() (*CometSupplyAccount, error) {
+	addressType, err := a.GetAddressType()
+	if err != nil {
+		return nil, err
+	} else if addressType != AddressTypeCometSupply {
+		return nil, fmt.Errorf("invalid address type: %s", addressType)
+	}
+
+	cometAccount, err := a.toCometAccount()
+	if err != nil {
+		return nil, err
+	}
+
+	return &CometSupplyAccount{
+		CometAccount: *cometAccount,
+	}, nil
+}
+
+// AsCometDebtAccount resolves the account properties into a Compound v3 debt account
+func (a AccountProperties) AsCometDebtAccount() (*CometDebtAccount, error) {
+	addressType, err := a.GetAddressType()
+	if err != nil {
+		return nil, err
+	} else if addressType != AddressTypeCometDebt {
+		return nil, fmt.Errorf("invalid address type: %s", addressType)
+	}
+
+	cometAccount, err := a.toCometAccount()
+	if err != nil {
+		return nil, err
+	}
+
+	return &CometDebtAccount{
+		CometAccount: *cometAccount,
+	}, nil
+}
+
+// AsLPV2Account resolves the account properties into a Uniswap V2-style LP token account
+func (a AccountProperties) AsLPV2Account() (*LPV2Account, error) {
+	addressType, err := a.GetAddressType()
+	if err != nil {
+		return nil, err
+	} else if addressType != AddressTypeLPV2 {
+		return nil, fmt.Errorf("invalid address type: %s", addressType)
+	}
+
+	syncableAccount, err := a.asSyncableAccount()
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve syncable account: %w", err)
+	}
+
+	onchainWallet, err := a.asOnchainWallet()
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve onchain wallet: %w", err)
+	}
+
+	onchainAsset, err := a.asOnchainAsset()
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve onchain asset: %w", err)
+	}
+
+	pairAddress, hasPairAddress, err := a.stringProperty(fieldPairAddress)
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve pair address: %w", err)
+	} else if !hasPairAddress {
+		return nil, errors.New("pair address is required")
+	}
+
+	return &LPV2Account{
+		SyncableAccount: *syncableAccount,
+		OnchainWallet:   *onchainWallet,
+		OnchainAsset:    *onchainAsset,
+		PairAddress:     pairAddress,
+	}, nil
+}
+
+// AsUniswapV3Account resolves the account properties into a Uniswap V3 positions account
+func (a AccountProperties) AsUniswapV3Account() (*UniswapV3Account, error) {
+	addressType, err := a.GetAddressType()
+	if err != nil {
+		return nil, err
+	} else if addressType != AddressTypeUniswapV3 {
+		return nil, fmt.Errorf("invalid address type: %s", addressType)
+	}
+
+	syncableAccount, err := a.asSyncableAccount()
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve syncable account: %w", err)
+	}
+
+	onchainWallet, err := a.asOnchainWallet()
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve onchain wallet: %w", err)
+	}
+
+	onchainAsset, err := a.asOnchainAsset()
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve onchain asset: %w", err)
+	}
+
+	positionManagerAddress, hasPositionManagerAddress, err := a.stringProperty(fieldPositionManagerAddress)
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve position manager address: %w", err)
+	} else if !hasPositionManagerAddress {
+		return nil, errors.New("position manager address is required")
+	}
+
+	includeUncollectedFees, _, err := a.boolProperty(fieldIncludeUncollectedFees)
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve whether uncollected fees are included: %w", err)
+	}
+
+	return &UniswapV3Account{
+		SyncableAccount:        *syncableAccount,
+		OnchainWallet:          *onchainWallet,
+		OnchainAsset:           *onchainAsset,
+		PositionManagerAddress: positionManagerAddress,
+		IncludeUncollectedFees: includeUncollectedFees,
+	}, nil
+}
+
+// AsLiquidStakingAccount resolves the account properties into a liquid staking token account
+func (a AccountProperties) AsLiquidStakingAccount() (*LiquidStakingAccount, error) {
+	addressType, err := a.GetAddressType()
+	if err != nil {
+		return nil, err
+	} else if addressType != AddressTypeLiquidStaking {
+		return nil, fmt.Errorf("invalid address type: %s", addressType)
+	}
+
+	erc20Account, err := a.toERC20AccountType()
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve erc20 account: %w", err)
+	}
+
+	rateFunction, hasRateFunction, err := a.stringProperty(fieldRateFunction)
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve rate function: %w", err)
+	} else if !hasRateFunction {
+		return nil, errors.New("rate function is required")
+	}
+
+	rateDecimals, err := a.rateDecimals()
+	if err != nil {
+		return nil, err
+	}
+
+	// The underlying asset of most liquid staking tokens is the chain's native coin, which has no address
+	var underlyingTokenAddress *string
+	underlyingTokenAddressString, hasUnderlyingTokenAddress, err := a.stringProperty(fieldUnderlyingTokenAddress)
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve underlying token address: %w", err)
+	} else if hasUnderlyingTokenAddress && underlyingTokenAddressString != "" {
+		underlyingTokenAddress = &underlyingTokenAddressString
+	}
+
+	return &LiquidStakingAccount{
+		ERC20Account:           *erc20Account,
+		RateFunction:           rateFunction,
+		RateDecimals:           rateDecimals,
+		UnderlyingTokenAddress: underlyingTokenAddress,
+	}, nil
+}
+
+// AsVirtualPriceLPAccount resolves the account properties into an account for the LP token of a pool that reports the value of its LP token
+func (a AccountProperties) AsVirtualPriceLPAccount() (*VirtualPriceLPAccount, error) {
+	addressType, err := a.GetAddressType()
+	if err != nil {
+		return nil, err
+	} else if addressType != AddressTypeVirtualPriceLP {
+		return nil, fmt.Errorf("invalid address type: %s", addressType)
+	}
+
+	erc20Account, err := a.toERC20AccountType()
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve erc20 account: %w", err)
+	}
+
+	// The LP tokens of most pools are issued by the pools themselves
+	poolAddress, hasPoolAddress, err := a.stringProperty(fieldPoolAddress)
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve pool address: %w", err)
+	} else if !hasPoolAddress {
+		poolAddress = erc20Account.TokenAddress
+	}
+
+	rateFunction, hasRateFunction, err := a.stringProperty(fieldRateFunction)
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve rate function: %w", err)
+	} else if !hasRateFunction {
+		rateFunction = virtualPriceFunctionDefault
+	}
+
+	rateDecimals, err := a.rateDecimals()
+	if err != nil {
+		return nil, err
+	}
+
+	referenceAsset, hasReferenceAsset, err := a.mapProperty(fieldReferenceAsset)
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve reference asset: %w", err)
+	} else if !hasReferenceAsset {
+		return nil, errors.New("reference asset is required")
+	}
+
+	referenceContractAddress, hasReferenceContractAddress, err := referenceAsset.stringProperty(fieldContractAddress)
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve reference asset contract address: %w", err)
+	} else if !hasReferenceContractAddress {
+		return nil, errors.New("reference asset contract address is required")
+	}
+
+	var referenceTokenAddress *string
+	if referenceContractAddress != "" {
+		referenceTokenAddress = &referenceContractAddress
+	}
+
+	return &VirtualPriceLPAccount{
+		ERC20Account:          *erc20Account,
+		PoolAddress:           poolAddress,
+		RateFunction:          rateFunction,
+		RateDecimals:          rateDecimals,
+		ReferenceTokenAddress: referenceTokenAddress,
+	}, nil
+}
+
+// GetHoldings resolves the holdings whose values are summed into the account's balance, if the account aggregates several holdings.
+// Each holding inherits the properties of the account that it does not set itself, so that, for example, a wallet address shared
+// by the holdings need only be configured once. The properties of the YNAB account (i.e., its name, payee, and category) can only be
+// set on the account itself, as every holding is synced to it. If the account does not aggregate holdings, nil is returned.
+func (a AccountProperties) GetHoldings() ([]AccountProperties, error) {
+	holdingsAny, hasHoldings := a[fieldHoldings]
+	if !hasHoldings {
+		return nil, nil
+	}
+
+	holdingsList, isList := holdingsAny.([]any)
+	if !isList {
+		return nil, fmt.Errorf("invalid property type for '%s': %v", fieldHoldings, holdingsAny)
+	} else if len(holdingsList) == 0 {
+		return nil, errors.New("at least one holding is required")
+	}
+
+	holdings := make([]AccountProperties, len(holdingsList))
+	for holdingIndex, holdingAny := range holdingsList {
+		var holdingProperties AccountProperties
+		switch holding := holdingAny.(type) {
+		case AccountProperties:
+			holdingProperties = holding
+		case map[string]any:
+			holdingProperties = AccountProperties(holding)
+		default:
+			return nil, fmt.Errorf("invalid holding at index %d: %v", holdingIndex, holdingAny)
+		}
+
+		if holdingProperties.hasProperty(fieldHoldings) {
+			return nil, fmt.Errorf("holding at index %d cannot itself have holdings", holdingIndex)
+		}
+
+		for _, accountField := range []string{fieldAccountName, fieldPayeeName, fieldTransactionCategoryName} {
+			if holdingProperties.hasProperty(accountField) {
+				return nil, fmt.Errorf("holding at index %d cannot set '%s', which can only be set on the account that aggregates it", holdingIndex, accountField)
+			}
+		}
+
+		mergedProperties := make(AccountProperties, len(a)+len(holdingProperties))
+		for propertyName, propertyValue := range a {
+			if propertyName != fieldHoldings {
+				mergedProperties[propertyName] = propertyValue
+			}
+		}
+		for propertyName, propertyValue := range holdingProperties {
+			mergedProperties[propertyName] = propertyValue
+		}
+
+		holdings[holdingIndex] = mergedProperties
+	}
+
+	return holdings, nil
+}
+
+// GetPrice resolves how the price of the account's asset is to be resolved, if not by the asset's own address.
+// If no price is configured for the account, nil is returned.
+func (a AccountProperties) GetPrice() (*AccountPrice, error) {
+	priceProperties, hasPrice, err := a.mapProperty(fieldPrice)
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve price: %w", err)
+	} else if !hasPrice {
+		return nil, nil
+	}
+
+	accountPrice := &AccountPrice{}
+	configuredCount := 0
+
+	if priceProperties.hasProperty(fieldFixed) {
+		configuredCount++
+
+		fixedPrice, err := priceProperties.numericStringProperty(fieldFixed)
+		if err != nil {
+			return nil, fmt.Errorf("unable to resolve fixed price: %w", err)
+		}
+		accountPrice.FixedPrice = &fixedPrice
+	}
+
+	peggedToProperties, hasPeggedTo, err := priceProperties.mapProperty(fieldPeggedTo)
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve pegged asset: %w", err)
+	} else if hasPeggedTo {
+		configuredCount++
+
+		accountPrice.PeggedTo, err = peggedToProperties.asPeggedAsset()
+		if err != nil {
+			return nil, fmt.Errorf("unable to resolve pegged asset: %w", err)
+		}
+	}
+
+	coingeckoCoinID, hasCoingeckoCoinID, err := priceProperties.stringProperty(fieldCoingeckoCoinID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve Coingecko coin ID: %w", err)
+	} else if hasCoingeckoCoinID {
+		configuredCount++
+
+		if coingeckoCoinID == "" {
+			return nil, errors.New("Coingecko coin ID must not be blank")
+		}
+		accountPrice.CoingeckoCoinID = coingeckoCoinID
+	}
+
+	if configuredCount != 1 {
+		return nil, fmt.Errorf("exactly one of '%s', '%s', or '%s' must be configured for the price", fieldFixed, fieldPeggedTo, fieldCoingeckoCoinID)
+	}
+
+	return accountPrice, nil
+}
+
+func (a AccountProperties) asPeggedAsset() (*PeggedAsset, error) {
+	peggedAsset := &PeggedAsset{}
+
+	chainName, hasChainName, err := a.stringProperty(fieldChainName)
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve chain name: %w", err)
+	}
+	peggedAsset.ChainName = chainName
+
+	if a.hasProperty(fieldChainID) {
+		chainIDString, err := a.numericStringProperty(fieldChainID)
+		if err != nil {
+			return nil, fmt.Errorf("unable to resolve chain ID: %w", err)
+		}
+
+		chainID, err := strconv.ParseUint(chainIDString, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid chain ID '%s': %w", chainIDString, err)
+		}
+		peggedAsset.ChainID = &chainID
+	}
+
+	if hasChainName == (peggedAsset.ChainID != nil) {
+		return nil, fmt.Errorf("exactly one of '%s' or '%s' must be configured", fieldChainName, fieldChainID)
+	}
+
+	tokenAddress, hasTokenAddress, err := a.stringProperty(fieldTokenAddress)
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve token address: %w", err)
+	} else if hasTokenAddress && tokenAddress != "" {
+		peggedAsset.TokenAddress = &tokenAddress
+	}
+
+	return peggedAsset, nil
+}
+
+// asVaultConversion resolves how a vault's shares are converted into its asset, overriding the given conversion of the vault's preset
+// with any configured conversion properties. A nil conversion describes the ERC4626 convertToAssets function.
+func (a AccountProperties) asVaultConversion(presetConversion *VaultConversion) (*VaultConversion, error) {
+	if !a.hasProperty(fieldConversionFunction) && !a.hasProperty(fieldConversionArgument) && !a.hasProperty(fieldConversionDecimals) {
+		return presetConversion, nil
+	}
+
+	conversion := &VaultConversion{
+		Function: "convertToAssets",
+		Argument: VaultConversionArgumentShares,
+	}
+	if presetConversion != nil {
+		*conversion = *presetConversion
+	}
+
+	conversionFunction, hasConversionFunction, err := a.stringProperty(fieldConversionFunction)
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve conversion function: %w", err)
+	} else if hasConversionFunction {
+		conversion.Function = conversionFunction
+	}
+
+	conversionArgument, hasConversionArgument, err := a.stringProperty(fieldConversionArgument)
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve conversion argument: %w", err)
+	} else if hasConversionArgument {
+		switch VaultConversionArgument(conversionArgument) {
+		case VaultConversionArgumentShares, VaultConversionArgumentNone:
+			conversion.Argument = VaultConversionArgument(conversionArgument)
+		default:
+			return nil, fmt.Errorf("unsupported conversion argument '%s'; must be '%s' or '%s'", conversionArgument, VaultConversionArgumentShares, VaultConversionArgumentNone)
+		}
+	}
+
+	if a.hasProperty(fieldConversionDecimals) {
+		conversionDecimalsString, err := a.numericStringProperty(fieldConversionDecimals)
+		if err != nil {
+			return nil, fmt.Errorf("unable to resolve conversion decimals: %w", err)
+		}
+
+		conversionDecimals, err := strconv.Atoi(conversionDecimalsString)
+		if err != nil || conversionDecimals < 0 {
+			return nil, fmt.Errorf("conversion decimals must be a non-negative integer, but is '%s'", conversionDecimalsString)
+		}
+
+		conversion.Decimals = &conversionDecimals
+	}
+
+	return conversion, nil
+}
+
 func (a AccountProperties) asOnchainAsset() (*OnchainAsset, error) {
-	chainName, hasChainName, err := a.stringProperty("chain_name")
+	chainName, hasChainName, err := a.stringProperty(fieldChainName)
 	if err != nil {
 		return nil, fmt.Errorf("unable to resolve chain name: %w", err)
 	} else if !hasChainName {
@@ -202,6 +821,12 @@ func (a AccountProperties) asOnchainAsset() (*OnchainAsset, error) {
 	}, nil
 }
 
+// AsSyncableAccount resolves the YNAB account to which the account properties are synced.
+// Unlike the other conversions, this does not require any onchain properties, so it can be used for an account that aggregates holdings.
+func (a AccountProperties) AsSyncableAccount() (*SyncableAccount, error) {
+	return a.asSyncableAccount()
+}
+
 // asSyncableAccount resolves the account properties into a syncable account
 func (a AccountProperties) asSyncableAccount() (*SyncableAccount, error) {
 	accountName, hasAccountName, err := a.stringProperty(fieldAccountName)
@@ -218,11 +843,10 @@ func (a AccountProperties) asSyncableAccount() (*SyncableAccount, error) {
 		return nil, errors.New("payee name is required")
 	}
 
-	transactionCategoryName, hasTransactionCategoryName, err := a.stringProperty(fieldTransactionCategoryName)
+	// Transactions in tracking accounts cannot be categorized, so whether a category is required depends on the account in YNAB
+	transactionCategoryName, _, err := a.stringProperty(fieldTransactionCategoryName)
 	if err != nil {
 		return nil, fmt.Errorf("unable to resolve transaction category name: %w", err)
-	} else if !hasTransactionCategoryName {
-		return nil, errors.New("transaction category name is required")
 	}
 
 	return &SyncableAccount{
@@ -245,11 +869,78 @@ func (a AccountProperties) asOnchainWallet() (*OnchainWallet, error) {
 	}, nil
 }
 
+func (a AccountProperties) boolProperty(propertyName string) (bool, bool, error) {
+	propertyAny, hasProperty := a[propertyName]
+	if !hasProperty {
+		return false, false, nil
+	}
+
+	propertyBool, isBool := propertyAny.(bool)
+	if !isBool {
+		return false, false, fmt.Errorf("invalid property type for '%s': %v", propertyName, propertyAny)
+	}
+
+	return propertyBool, true, nil
+}
+
 func (a AccountProperties) hasProperty(propertyName string) bool {
 	_, hasProperty := a[propertyName]
 	return hasProperty
 }
 
+// mapProperty resolves a property whose value is itself a set of properties.
+func (a AccountProperties) mapProperty(propertyName string) (AccountProperties, bool, error) {
+	propertyAny, hasProperty := a[propertyName]
+	if !hasProperty {
+		return nil, false, nil
+	}
+
+	switch property := propertyAny.(type) {
+	case AccountProperties:
+		return property, true, nil
+	case map[string]any:
+		return AccountProperties(property), true, nil
+	default:
+		return nil, false, fmt.Errorf("invalid property type for '%s': %v", propertyName, propertyAny)
+	}
+}
+
+// numericStringProperty resolves a property whose value is a number, which may be given either as a number or as a string.
+// The number is returned in its string form so that no precision is lost.
+func (a AccountProperties) numericStringProperty(propertyName string) (string, error) {
+	switch property := a[propertyName].(type) {
+	case string:
+		return property, nil
+	case int:
+		return strconv.Itoa(property), nil
+	case uint64:
+		return strconv.FormatUint(property, 10), nil
+	case float64:
+		return strconv.FormatFloat(property, 'f', -1, 64), nil
+	default:
+		return "", fmt.Errorf("invalid property type for '%s': %v", propertyName, property)
+	}
+}
+
+// rateDecimals resolves the number of decimals in which an exchange rate is expressed, defaulting to 18.
+func (a AccountProperties) rateDecimals() (int, error) {
+	if !a.hasProperty(fieldRateDecimals) {
+		return rateDecimalsDefault, nil
+	}
+
+	rateDecimalsString, err := a.numericStringProperty(fieldRateDecimals)
+	if err != nil {
+		return 0, fmt.Errorf("unable to resolve rate decimals: %w", err)
+	}
+
+	rateDecimals, err := strconv.Atoi(rateDecimalsString)
+	if err != nil || rateDecimals < 0 {
+		return 0, fmt.Errorf("rate decimals must be a non-negative integer, but is '%s'", rateDecimalsString)
+	}
+
+	return rateDecimals, nil
+}
+
 func (a AccountProperties) stringProperty(propertyName string) (string, bool, error) {
 	propertyAny, hasProperty := a[propertyName]
 	if !hasProperty {
@@ -297,6 +988,38 @@ func (a AccountProperties) toERC20AccountType() (*ERC20Account, error) {
 	}, nil
 }
 
+// toCometAccount is an internal-only method to allow reuse of the Compound v3 data model
+func (a AccountProperties) toCometAccount() (*CometAccount, error) {
+	syncableAccount, err := a.asSyncableAccount()
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve syncable account: %w", err)
+	}
+
+	onchainWallet, err := a.asOnchainWallet()
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve onchain wallet: %w", err)
+	}
+
+	onchainAsset, err := a.asOnchainAsset()
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve onchain asset: %w", err)
+	}
+
+	cometAddress, hasCometAddress, err := a.stringProperty(fieldCometAddress)
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve Comet address: %w", err)
+	} else if !hasCometAddress {
+		return nil, errors.New("Comet address is required")
+	}
+
+	return &CometAccount{
+		SyncableAccount: *syncableAccount,
+		OnchainWallet:   *onchainWallet,
+		OnchainAsset:    *onchainAsset,
+		CometAddress:    cometAddress,
+	}, nil
+}
+
 // OnchainAccount is a marker interface to declare when an instance of onchain account is needed
 type OnchainAccount interface {
 	isOnchainAccount()
@@ -305,7 +1028,7 @@ type OnchainAccount interface {
 type SyncableAccount struct {
 	AccountName             string // the name of the account in YNAB
 	PayeeName               string // the name of the payee to which the transction should be attributed in YNAB
-	TransactionCategoryName string // the name of the YNAB category under which the transaction is to be classified
+	TransactionCategoryName string // the name of the YNAB category under which the transaction is to be classified; blank if the YNAB account is a tracking account
 }
 
 func (s *SyncableAccount) String() string {
@@ -330,6 +1053,21 @@ func (o *OnchainAsset) String() string {
 	return fmt.Sprintf("OnchainAsset{ChainName: %s}", o.ChainName)
 }
 
+// AccountPrice describes how the price of an account's asset is to be resolved, if not by the asset's own address.
+// Exactly one of its fields is set.
+type AccountPrice struct {
+	FixedPrice      *string      // a fixed per-token price, in the currency in which balances are valued
+	PeggedTo        *PeggedAsset // an asset whose price is used as the price of the account's asset
+	CoingeckoCoinID string       // the Coingecko coin ID by which the account's asset is to be quoted
+}
+
+// PeggedAsset identifies an asset to whose price the price of another asset is pegged.
+type PeggedAsset struct {
+	ChainName    string  // the chain name of the RPC configuration of the chain on which the asset resides; blank if ChainID is set
+	ChainID      *uint64 // the ID of the chain on which the asset resides, for chains without an RPC configuration; nil if ChainName is set
+	TokenAddress *string // the address of the asset; nil for the chain's native coin
+}
+
 // ERC20Account defines the properties needed to resolve the balance of an ERC20 token
 type ERC20Account struct {
 	SyncableAccount
@@ -354,6 +1092,8 @@ type ERC4626Account struct {
 	VaultAddress        string // the address of the ERC4626 vault
 	BalanceFunctionName string // the name of the function to be called in order to retrieve the wallet's balance of the vault asset (not the underlying asset)
 	BackingAsset        *ERC4626BackingAsset
+	AssetFunction       string           // the name of the function to be called in order to retrieve the address of the underlying asset; blank for the ERC4626 asset function
+	Conversion          *VaultConversion // how the vault's shares are converted into the underlying asset; nil for the ERC4626 convertToAssets function
 }
 
 func (*ERC4626Account) isOnchainAccount() {}
@@ -362,6 +1102,22 @@ func (e *ERC4626Account) String() string {
 	return fmt.Sprintf("ERC4626Account{SyncableAccount: %s, OnchainAsset: %s, OnchainWallet: %s, VaultAddress: %s, BalanceFunctionName: %s}", &e.SyncableAccount, &e.OnchainAsset, &e.OnchainWallet, e.VaultAddress, e.BalanceFunctionName)
 }
 
+// VaultConversion describes how the shares of a vault are converted into the vault's underlying asset.
+type VaultConversion struct {
+	Function string                  // the name of the function to be called on the vault
+	Argument VaultConversionArgument // the argument taken by the function
+	Decimals *int                    // for a function that returns the value of one share, the decimals in which that value is expressed; nil if it is expressed in the vault's own decimals
+}
+
+func (v *VaultConversion) String() string {
+	decimals := "<vault decimals>"
+	if v.Decimals != nil {
+		decimals = strconv.Itoa(*v.Decimals)
+	}
+
+	return fmt.Sprintf("VaultConversion{Function: %s, Argument: %s, Decimals: %s}", v.Function, v.Argument, decimals)
+}
+
 // ERC4626BackingAsset defines the asset backing the contents of the vault.
 type ERC4626BackingAsset struct {
 	ContractAddress *string // the address of the contract that is backing the vault
@@ -387,3 +1143,155 @@ func (*ERC20WrapperAccount) isOnchainAccount() {}
 func (e *ERC20WrapperAccount) String() string {
 	return fmt.Sprintf("ERC20WrapperAccount{ERC20Account: %s, BaseTokenAddressFunction: %s}", &e.ERC20Account, e.BaseTokenAddressFunction)
 }
+
+// NativeAccount defines the properties needed to resolve the balance of a chain's native coin
+type NativeAccount struct {
+	SyncableAccount
+	OnchainAsset
+	OnchainWallet
+}
+
+func (*NativeAccount) isOnchainAccount() {}
+
+func (n *NativeAccount) String() string {
+	return fmt.Sprintf("NativeAccount{SyncableAccount: %s, OnchainAsset: %s, OnchainWallet: %s}", &n.SyncableAccount, &n.OnchainAsset, &n.OnchainWallet)
+}
+
+// AaveSupplyAccount defines the properties needed to resolve the amount supplied to an Aave v3 market
+type AaveSupplyAccount struct {
+	SyncableAccount
+	OnchainAsset
+	OnchainWallet
+
+	ATokenAddress string // the address of the aToken that tracks the supplied amount
+}
+
+func (*AaveSupplyAccount) isOnchainAccount() {}
+
+func (a *AaveSupplyAccount) String() string {
+	return fmt.Sprintf("AaveSupplyAccount{SyncableAccount: %s, OnchainAsset: %s, OnchainWallet: %s, ATokenAddress: %s}", &a.SyncableAccount, &a.OnchainAsset, &a.OnchainWallet, a.ATokenAddress)
+}
+
+// AaveDebtAccount defines the properties needed to resolve the debt owed to an Aave v3 market
+type AaveDebtAccount struct {
+	SyncableAccount
+	OnchainAsset
+	OnchainWallet
+
+	DebtTokenAddress string // the address of the variable or stable debt token that tracks the debt
+}
+
+func (*AaveDebtAccount) isOnchainAccount() {}
+
+func (a *AaveDebtAccount) String() string {
+	return fmt.Sprintf("AaveDebtAccount{SyncableAccount: %s, OnchainAsset: %s, OnchainWallet: %s, DebtTokenAddress: %s}", &a.SyncableAccount, &a.OnchainAsset, &a.OnchainWallet, a.DebtTokenAddress)
+}
+
+// CometAccount defines the properties shared by the positions held in a Compound v3 (Comet) market
+type CometAccount struct {
+	SyncableAccount
+	OnchainAsset
+	OnchainWallet
+
+	CometAddress string // the address of the Comet contract of the market
+}
+
+// CometSupplyAccount defines the properties needed to resolve the amount of the base asset supplied to a Compound v3 (Comet) market
+type CometSupplyAccount struct {
+	CometAccount
+}
+
+func (*CometSupplyAccount) isOnchainAccount() {}
+
+func (c *CometSupplyAccount) String() string {
+	return fmt.Sprintf("CometSupplyAccount{SyncableAccount: %s, OnchainAsset: %s, OnchainWallet: %s, CometAddress: %s}", &c.SyncableAccount, &c.OnchainAsset, &c.OnchainWallet, c.CometAddress)
+}
+
+// CometDebtAccount defines the properties needed to resolve the debt owed to a Compound v3 (Comet) market
+type CometDebtAccount struct {
+	CometAccount
+}
+
+func (*CometDebtAccount) isOnchainAccount() {}
+
+func (c *CometDebtAccount) String() string {
+	return fmt.Sprintf("CometDebtAccount{SyncableAccount: %s, OnchainAsset: %s, OnchainWallet: %s, CometAddress: %s}", &c.SyncableAccount, &c.OnchainAsset, &c.OnchainWallet, c.CometAddress)
+}
+
+// LPV2Account defines the properties needed to resolve the value of the LP token of a Uniswap V2-style pair
+type LPV2Account struct {
+	SyncableAccount
+	OnchainAsset
+	OnchainWallet
+
+	PairAddress string // the address of the pair, which is also the address of its LP token
+}
+
+func (*LPV2Account) isOnchainAccount() {}
+
+func (l *LPV2Account) String() string {
+	return fmt.Sprintf("LPV2Account{SyncableAccount: %s, OnchainAsset: %s, OnchainWallet: %s, PairAddress: %s}", &l.SyncableAccount, &l.OnchainAsset, &l.OnchainWallet, l.PairAddress)
+}
+
+// UniswapV3Account defines the properties needed to resolve the value of the Uniswap V3-style concentrated liquidity positions held by a wallet
+type UniswapV3Account struct {
+	SyncableAccount
+	OnchainAsset
+	OnchainWallet
+
+	PositionManagerAddress string // the address of the NonfungiblePositionManager whose position NFTs are held by the wallet
+	IncludeUncollectedFees bool   // whether the fees earned by the positions, but not yet collected, are included in their value
+}
+
+func (*UniswapV3Account) isOnchainAccount() {}
+
+func (u *UniswapV3Account) String() string {
+	return fmt.Sprintf("UniswapV3Account{SyncableAccount: %s, OnchainAsset: %s, OnchainWallet: %s, PositionManagerAddress: %s, IncludeUncollectedFees: %t}", &u.SyncableAccount, &u.OnchainAsset, &u.OnchainWallet, u.PositionManagerAddress, u.IncludeUncollectedFees)
+}
+
+// LiquidStakingAccount defines the properties needed to resolve the value, in its underlying asset, of a liquid staking token
+// that accrues value through an exchange rate rather than by rebasing
+type LiquidStakingAccount struct {
+	ERC20Account
+
+	RateFunction           string  // the name of the function on the token contract that returns the amount of the underlying asset to which one token is worth
+	RateDecimals           int     // the number of decimals in which the rate is expressed
+	UnderlyingTokenAddress *string // the address of the underlying asset; nil for the chain's native coin
+}
+
+func (*LiquidStakingAccount) isOnchainAccount() {}
+
+func (l *LiquidStakingAccount) String() string {
+	underlyingTokenAddress := "<nil>"
+	if l.UnderlyingTokenAddress != nil {
+		underlyingTokenAddress = *l.UnderlyingTokenAddress
+	}
+
+	return fmt.Sprintf("LiquidStakingAccount{ERC20Account: %s, RateFunction: %s, RateDecimals: %d, UnderlyingTokenAddress: %s}", &l.ERC20Account, l.RateFunction, l.RateDecimals, underlyingTokenAddress)
+}
+
+// VirtualPriceLPAccount defines the properties needed to resolve the value, in a reference asset, of the LP token of a pool
+// that reports the value of its LP token (e.g., a Curve pool's virtual price or a Balancer pool's rate)
+type VirtualPriceLPAccount struct {
+	ERC20Account
+
+	PoolAddress           string  // the address of the pool that reports the value of its LP token; usually the address of the LP token itself
+	RateFunction          string  // the name of the function on the pool that returns the amount of the reference asset to which one LP token is worth
+	RateDecimals          int     // the number of decimals in which the rate is expressed
+	ReferenceTokenAddress *string // the address of the asset in which the rate is expressed; nil for the chain's native coin
+}
+
+func (*VirtualPriceLPAccount) isOnchainAccount() {}
+
+func (v *VirtualPriceLPAccount) String() string {
+	referenceTokenAddress := "<nil>"
+	if v.ReferenceTokenAddress != nil {
+		referenceTokenAddress = *v.ReferenceTokenAddress
+	}
+
+	return fmt.Sprintf("VirtualPriceLPAccount{ERC20Account: %s, PoolAddress: %s, RateFunction: %s, RateDecimals: %d, ReferenceTokenAddress: %s}", &v.ERC20Account, v.PoolAddress, v.RateFunction, v.RateDecimals, referenceTokenAddress)
+}
+
+func intPointer(value int) *int {
+	return &value
+}
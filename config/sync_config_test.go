@@ -2,9 +2,12 @@ package config_test
 
 import (
 	"bytes"
+	"time"
 
 	"github.com/jrh3k5/cryptonabber-sync/v3/config"
 	"github.com/jrh3k5/cryptonabber-sync/v3/config/chain"
+	coingeckoconfig "github.com/jrh3k5/cryptonabber-sync/v3/config/coingecko"
+	priceconfig "github.com/jrh3k5/cryptonabber-sync/v3/config/price"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"gopkg.in/yaml.v3"
@@ -17,6 +20,7 @@ var _ = Describe("SyncConfig", func() {
 				"rpc_url":    "http://localhost:8545",
 				"chain_name": "ethereum",
 				"chain_type": "evm",
+				"chain_id":   1,
 			}
 
 			configBytes, err := yaml.Marshal(map[string]any{
@@ -33,7 +37,213 @@ var _ = Describe("SyncConfig", func() {
 			Expect(rpcConfiguration.RPCURL).To(Equal("http://localhost:8545"), "the RPC URL should be successfully parsed")
 			Expect(rpcConfiguration.ChainName).To(Equal("ethereum"), "the chain name should be successfully parsed")
 			Expect(rpcConfiguration.ChainType).To(Equal(chain.TypeEVM), "the chain type should be successfully parsed")
+			Expect(rpcConfiguration.ChainID).ToNot(BeNil(), "the chain ID should be successfully parsed")
+			Expect(*rpcConfiguration.ChainID).To(Equal(uint64(1)), "the chain ID should be successfully parsed")
 		})
+
+		It("successfully deserializes multiple RPC URLs", func() {
+			rpcConfigurationYAML := map[string]any{
+				"rpc_url": "http://localhost:8545",
+				"rpc_urls": []any{
+					map[string]any{"url": "http://localhost:8547", "priority": 2},
+					map[string]any{"url": "http://localhost:8546", "priority": -1},
+					map[string]any{"url": "http://localhost:8548"},
+				},
+				"chain_name":           "ethereum",
+				"chain_type":           "evm",
+				"cross_check_chain_id": true,
+			}
+
+			configBytes, err := yaml.Marshal(map[string]any{
+				"rpc_configurations": []any{rpcConfigurationYAML},
+			})
+			Expect(err).ToNot(HaveOccurred(), "serializing the RPC configuration should not fail")
+
+			syncConfig, err := config.FromYAML(bytes.NewBuffer(configBytes))
+			Expect(err).ToNot(HaveOccurred(), "deserializing the RPC configuration should not fail")
+			Expect(syncConfig.RPCConfigurations).To(HaveLen(1), "there should be one RPC configuration")
+
+			rpcConfiguration := syncConfig.RPCConfigurations[0]
+			Expect(rpcConfiguration.CrossCheckChainID).To(BeTrue(), "the chain ID cross-check should be enabled")
+			Expect(rpcConfiguration.PrimaryURL()).To(Equal("http://localhost:8546"), "the highest-priority URL should be the primary URL")
+
+			var endpointURLs []string
+			for _, endpoint := range rpcConfiguration.Endpoints() {
+				endpointURLs = append(endpointURLs, endpoint.URL)
+			}
+			Expect(endpointURLs).To(Equal([]string{
+				"http://localhost:8546",
+				"http://localhost:8545",
+				"http://localhost:8548",
+				"http://localhost:8547",
+			}), "the URLs should be ordered by priority, and then by the order in which they are configured")
+		})
+
+		It("successfully deserializes a pinned block", func() {
+			configYAML := `
+rpc_configurations:
+  - rpc_url: "http://localhost:8545"
+    chain_name: "ethereum"
+    chain_type: "evm"
+    block_number: 19000000
+  - rpc_url: "http://localhost:8546"
+    chain_name: "base"
+    chain_type: "evm"
+    block_timestamp: "2024-01-31T23:59:59Z"
+`
+
+			syncConfig, err := config.FromYAML(bytes.NewBufferString(configYAML))
+			Expect(err).ToNot(HaveOccurred(), "deserializing the RPC configuration should not fail")
+			Expect(syncConfig.RPCConfigurations).To(HaveLen(2), "there should be two RPC configurations")
+
+			Expect(syncConfig.RPCConfigurations[0].BlockNumber).ToNot(BeNil(), "the block number should be parsed")
+			Expect(*syncConfig.RPCConfigurations[0].BlockNumber).To(Equal(uint64(19000000)), "the block number should be parsed")
+			Expect(syncConfig.RPCConfigurations[0].BlockTimestamp).To(BeNil(), "no block timestamp should be parsed")
+
+			Expect(syncConfig.RPCConfigurations[1].BlockNumber).To(BeNil(), "no block number should be parsed")
+			Expect(syncConfig.RPCConfigurations[1].BlockTimestamp).ToNot(BeNil(), "the block timestamp should be parsed")
+			Expect(syncConfig.RPCConfigurations[1].BlockTimestamp.Equal(time.Date(2024, time.January, 31, 23, 59, 59, 0, time.UTC))).To(BeTrue(), "the block timestamp should be parsed")
+		})
+	})
+
+	It("successfully deserializes the currency", func() {
+		syncConfig, err := config.FromYAML(bytes.NewBufferString(`currency: "CAD"`))
+		Expect(err).ToNot(HaveOccurred(), "deserializing the currency should not fail")
+		Expect(syncConfig.Currency).To(Equal("CAD"), "the currency should be parsed")
+	})
+
+	Context("coingecko", func() {
+		It("successfully deserializes the Coingecko configuration", func() {
+			configYAML := `
+coingecko:
+  api_key: "test-key"
+  api_plan: "pro"
+  requests_per_minute: 250
+  asset_platform_cache_ttl: "72h"
+  asset_platforms:
+    - chain_id: 534352
+      asset_platform_id: "scroll"
+      native_coin_id: "ethereum"
+`
+
+			syncConfig, err := config.FromYAML(bytes.NewBufferString(configYAML))
+			Expect(err).ToNot(HaveOccurred(), "deserializing the Coingecko configuration should not fail")
+			Expect(syncConfig.Coingecko.APIKey).To(Equal("test-key"), "the API key should be parsed")
+			Expect(syncConfig.Coingecko.APIPlan).To(Equal(coingeckoconfig.APIPlanPro), "the API plan should be parsed")
+			Expect(syncConfig.Coingecko.RequestsPerMinute).To(Equal(250), "the request budget should be parsed")
+			Expect(syncConfig.Coingecko.AssetPlatformCacheTTL).To(Equal(72*time.Hour), "the cache TTL should be parsed")
+			Expect(syncConfig.Coingecko.AssetPlatforms).To(HaveLen(1), "there should be one asset platform")
+
+			assetPlatform := syncConfig.Coingecko.AssetPlatforms[0]
+			Expect(assetPlatform.ChainID).To(Equal(uint64(534352)), "the chain ID should be parsed")
+			Expect(assetPlatform.AssetPlatformID).To(Equal("scroll"), "the asset platform ID should be parsed")
+			Expect(assetPlatform.NativeCoinID).To(Equal("ethereum"), "the native coin ID should be parsed")
+		})
+	})
+
+	Context("coingecko plan", func() {
+		DescribeTable("resolving the plan", func(configuration coingeckoconfig.Configuration, expectedPlan coingeckoconfig.APIPlan) {
+			plan, err := configuration.Plan()
+			Expect(err).ToNot(HaveOccurred(), "resolving the plan should not fail")
+			Expect(plan).To(Equal(expectedPlan), "the correct plan should be resolved")
+		},
+			Entry("no API key", coingeckoconfig.Configuration{}, coingeckoconfig.APIPlanPublic),
+			Entry("API key without a plan", coingeckoconfig.Configuration{APIKey: "key"}, coingeckoconfig.APIPlanDemo),
+			Entry("pro plan", coingeckoconfig.Configuration{APIKey: "key", APIPlan: "pro"}, coingeckoconfig.APIPlanPro))
+
+		DescribeTable("invalid plans", func(configuration coingeckoconfig.Configuration) {
+			_, err := configuration.Plan()
+			Expect(err).To(HaveOccurred(), "resolving the plan should fail")
+		},
+			Entry("pro plan without an API key", coingeckoconfig.Configuration{APIPlan: "pro"}),
+			Entry("unknown plan", coingeckoconfig.Configuration{APIKey: "key", APIPlan: "enterprise"}))
+	})
+
+	Context("pricing", func() {
+		It("successfully deserializes the pricing configuration", func() {
+			configYAML := `
+pricing:
+  providers:
+    - defillama
+    - chainlink
+  chainlink_feeds:
+    - chain_name: "ethereum"
+      token_address: "0x514910771AF9Ca656af840dff83E8264EcF986CA"
+      feed_address: "0x2c1d072e956AFFC0D435Cb7AC38EF18d24d9127c"
+      currency: "USD"
+  dex_pools:
+    - chain_name: "base"
+      token_address: "0x4ed4E862860beD51a9570b96d89aF5E1B0Efefed"
+      pool_address: "0xc9034c3E7F58003E6ae0C8438e7c8f4598d5ACAA"
+      pool_type: "uniswap_v3"
+      twap_period: "30m"
+  defillama_chains:
+    - chain_id: 534352
+      chain: "scroll"
+  max_quote_age: "1h"
+  max_rate_change_percent: 25.5
+  rate_change_action: "flag"
+  quote_cache_ttl: "2m"
+`
+
+			syncConfig, err := config.FromYAML(bytes.NewBufferString(configYAML))
+			Expect(err).ToNot(HaveOccurred(), "deserializing the pricing configuration should not fail")
+
+			providers, err := syncConfig.Pricing.ProviderOrder()
+			Expect(err).ToNot(HaveOccurred(), "resolving the provider order should not fail")
+			Expect(providers).To(Equal([]priceconfig.ProviderName{priceconfig.ProviderDefiLlama, priceconfig.ProviderChainlink}), "the configured provider order should be used")
+
+			Expect(syncConfig.Pricing.ChainlinkFeeds).To(Equal([]priceconfig.ChainlinkFeed{
+				{
+					ChainName:    "ethereum",
+					TokenAddress: "0x514910771AF9Ca656af840dff83E8264EcF986CA",
+					FeedAddress:  "0x2c1d072e956AFFC0D435Cb7AC38EF18d24d9127c",
+					Currency:     "USD",
+				},
+			}), "the Chainlink feeds should be parsed")
+			Expect(syncConfig.Pricing.DEXPools).To(Equal([]priceconfig.DEXPool{
+				{
+					ChainName:    "base",
+					TokenAddress: "0x4ed4E862860beD51a9570b96d89aF5E1B0Efefed",
+					PoolAddress:  "0xc9034c3E7F58003E6ae0C8438e7c8f4598d5ACAA",
+					PoolType:     priceconfig.DEXPoolTypeUniswapV3,
+					TWAPPeriod:   30 * time.Minute,
+				},
+			}), "the DEX pools should be parsed")
+			Expect(syncConfig.Pricing.DefiLlamaChains).To(Equal([]priceconfig.DefiLlamaChain{{ChainID: 534352, Chain: "scroll"}}), "the DefiLlama chains should be parsed")
+			Expect(syncConfig.Pricing.MaxQuoteAge).To(Equal(time.Hour), "the maximum quote age should be parsed")
+			Expect(syncConfig.Pricing.MaxRateChangePercent).To(Equal(25.5), "the maximum rate change should be parsed")
+			Expect(syncConfig.Pricing.QuoteCacheTTL).To(Equal(2*time.Minute), "the quote cache TTL should be parsed")
+
+			rateChangeAction, err := syncConfig.Pricing.RateChangeActionOrDefault()
+			Expect(err).ToNot(HaveOccurred(), "resolving the rate change action should not fail")
+			Expect(rateChangeAction).To(Equal(priceconfig.RateChangeActionFlag), "the configured rate change action should be used")
+		})
+
+		It("uses the default provider order if none is configured", func() {
+			providers, err := priceconfig.Configuration{}.ProviderOrder()
+			Expect(err).ToNot(HaveOccurred(), "resolving the provider order should not fail")
+			Expect(providers).To(Equal(priceconfig.DefaultProviders()), "the default provider order should be used")
+			Expect(providers).ToNot(ContainElement(priceconfig.ProviderDefiLlama), "DefiLlama should only be consulted if configured")
+		})
+
+		It("rejects unknown providers", func() {
+			_, err := priceconfig.Configuration{Providers: []priceconfig.ProviderName{"unknown"}}.ProviderOrder()
+			Expect(err).To(HaveOccurred(), "an unknown provider should be rejected")
+		})
+
+		It("skips accounts whose rates change too much if no action is configured", func() {
+			rateChangeAction, err := priceconfig.Configuration{}.RateChangeActionOrDefault()
+			Expect(err).ToNot(HaveOccurred(), "resolving the rate change action should not fail")
+			Expect(rateChangeAction).To(Equal(priceconfig.RateChangeActionSkip), "accounts should be skipped by default")
+		})
+
+		DescribeTable("rejects invalid rate change guards", func(configuration priceconfig.Configuration) {
+			_, err := configuration.RateChangeActionOrDefault()
+			Expect(err).To(HaveOccurred(), "resolving the rate change action should fail")
+		},
+			Entry("unknown action", priceconfig.Configuration{RateChangeAction: "ignore"}),
+			Entry("negative maximum change", priceconfig.Configuration{MaxRateChangePercent: -1}))
 	})
 
 	Context("ynab_accounts", func() {
@@ -147,6 +357,89 @@ var _ = Describe("SyncConfig", func() {
 			})
 		})
 
+		Context("ERC462 accounts with legacy vault types", func() {
+			var vaultAccountProperties config.AccountProperties
+
+			BeforeEach(func() {
+				vaultAccountProperties = config.AccountProperties{
+					"account_name":   "Test Vault Account",
+					"payee_name":     "Test Vault Payee",
+					"wallet_address": "0x1234567890123456789012345678901234567890",
+					"address_type":   "erc4626",
+					"chain_name":     "ethereum",
+					"vault_address":  "0xdA816459F1AB5631232FE5e97a05BBBb94970c95",
+				}
+			})
+
+			It("uses the ERC4626 functions by default", func() {
+				erc4626Account, err := vaultAccountProperties.AsERC4626Account()
+				Expect(err).ToNot(HaveOccurred(), "resolving the vault account should not fail")
+				Expect(erc4626Account.AssetFunction).To(BeEmpty(), "the ERC4626 asset function should be used")
+				Expect(erc4626Account.Conversion).To(BeNil(), "the ERC4626 conversion should be used")
+			})
+
+			It("applies the preset of a Yearn v2 vault", func() {
+				vaultAccountProperties["vault_type"] = "yearn_v2"
+
+				erc4626Account, err := vaultAccountProperties.AsERC4626Account()
+				Expect(err).ToNot(HaveOccurred(), "resolving the vault account should not fail")
+				Expect(erc4626Account.AssetFunction).To(Equal("token"), "the asset should be read from the vault's token")
+				Expect(erc4626Account.Conversion).ToNot(BeNil(), "a conversion should be set")
+				Expect(erc4626Account.Conversion.Function).To(Equal("pricePerShare"), "the shares should be converted by their price")
+				Expect(erc4626Account.Conversion.Argument).To(Equal(config.VaultConversionArgumentNone), "the price should take no arguments")
+				Expect(erc4626Account.Conversion.Decimals).To(BeNil(), "the price should be expressed in the vault's decimals")
+			})
+
+			It("applies the preset of a Beefy vault", func() {
+				vaultAccountProperties["vault_type"] = "beefy"
+
+				erc4626Account, err := vaultAccountProperties.AsERC4626Account()
+				Expect(err).ToNot(HaveOccurred(), "resolving the vault account should not fail")
+				Expect(erc4626Account.AssetFunction).To(Equal("want"), "the asset should be read from the vault's want")
+				Expect(erc4626Account.Conversion).ToNot(BeNil(), "a conversion should be set")
+				Expect(erc4626Account.Conversion.Function).To(Equal("getPricePerFullShare"), "the shares should be converted by their price")
+				Expect(erc4626Account.Conversion.Decimals).ToNot(BeNil(), "the decimals of the price should be set")
+				Expect(*erc4626Account.Conversion.Decimals).To(Equal(18), "the price should be expressed in 18 decimals")
+			})
+
+			It("overrides the preset with configured conversion properties", func() {
+				vaultAccountProperties["vault_type"] = "beefy"
+				vaultAccountProperties["asset_function"] = "underlying"
+				vaultAccountProperties["conversion_function"] = "getPricePerShare"
+				vaultAccountProperties["conversion_decimals"] = 6
+
+				erc4626Account, err := vaultAccountProperties.AsERC4626Account()
+				Expect(err).ToNot(HaveOccurred(), "resolving the vault account should not fail")
+				Expect(erc4626Account.AssetFunction).To(Equal("underlying"), "the configured asset function should be used")
+				Expect(erc4626Account.Conversion.Function).To(Equal("getPricePerShare"), "the configured conversion function should be used")
+				Expect(erc4626Account.Conversion.Argument).To(Equal(config.VaultConversionArgumentNone), "the argument of the preset should be kept")
+				Expect(*erc4626Account.Conversion.Decimals).To(Equal(6), "the configured decimals should be used")
+			})
+
+			It("converts shares as arguments when only a conversion function is configured", func() {
+				vaultAccountProperties["conversion_function"] = "previewRedeem"
+
+				erc4626Account, err := vaultAccountProperties.AsERC4626Account()
+				Expect(err).ToNot(HaveOccurred(), "resolving the vault account should not fail")
+				Expect(erc4626Account.Conversion.Function).To(Equal("previewRedeem"), "the configured conversion function should be used")
+				Expect(erc4626Account.Conversion.Argument).To(Equal(config.VaultConversionArgumentShares), "the shares should be passed to the function")
+			})
+
+			It("rejects an unknown vault type", func() {
+				vaultAccountProperties["vault_type"] = "unknown"
+
+				_, err := vaultAccountProperties.AsERC4626Account()
+				Expect(err).To(HaveOccurred(), "an unknown vault type should be rejected")
+			})
+
+			It("rejects an unknown conversion argument", func() {
+				vaultAccountProperties["conversion_argument"] = "assets"
+
+				_, err := vaultAccountProperties.AsERC4626Account()
+				Expect(err).To(HaveOccurred(), "an unknown conversion argument should be rejected")
+			})
+		})
+
 		Context("ERC20 wrapper accounts", func() {
 			It("successfully deserializes the ERC20 wrapper account", func() {
 				erc20WrapperAccountYAML := map[string]any{
@@ -186,5 +479,603 @@ var _ = Describe("SyncConfig", func() {
 				Expect(erc20WrapperAccount.BaseTokenAddressFunction).To(Equal("0x7890123456789012345678901234567890"), "the base token address function should be successfully parsed")
 			})
 		})
+
+		Context("native accounts", func() {
+			It("successfully deserializes the native account", func() {
+				nativeAccountYAML := map[string]any{
+					"account_name":              "Test Native Account",
+					"payee_name":                "Test Native Payee",
+					"transaction_category_name": "Test Native Transaction Category",
+					"wallet_address":            "0x1234567890123456789012345678901234567890",
+					"address_type":              "native",
+					"chain_name":                "polygon",
+				}
+
+				yamlBytes, err := yaml.Marshal(map[string]any{
+					"ynab_accounts": []any{nativeAccountYAML},
+				})
+				Expect(err).ToNot(HaveOccurred(), "serializing the native account should not fail")
+
+				syncConfig, err := config.FromYAML(bytes.NewBuffer(yamlBytes))
+				Expect(err).ToNot(HaveOccurred(), "deserializing the native account should not fail")
+
+				Expect(syncConfig.Accounts).To(HaveLen(1), "there should be one native account")
+
+				account := syncConfig.Accounts[0]
+
+				Expect(account.GetAddressType()).To(Equal(config.AddressTypeNative), "the address type should be successfully parsed")
+
+				nativeAccount, err := account.AsNativeAccount()
+				Expect(err).ToNot(HaveOccurred(), "resolving the native account should not fail")
+
+				Expect(nativeAccount.AccountName).To(Equal("Test Native Account"), "the account name should be successfully parsed")
+				Expect(nativeAccount.PayeeName).To(Equal("Test Native Payee"), "the payee name should be successfully parsed")
+				Expect(nativeAccount.TransactionCategoryName).To(Equal("Test Native Transaction Category"), "the transaction category name should be successfully parsed")
+				Expect(nativeAccount.WalletAddress).To(Equal("0x1234567890123456789012345678901234567890"), "the wallet address should be successfully parsed")
+				Expect(nativeAccount.ChainName).To(Equal("polygon"), "the chain name should be successfully parsed")
+			})
+		})
+
+		Context("Aave supply accounts", func() {
+			It("successfully deserializes the Aave supply account", func() {
+				syncConfig, err := config.FromYAML(bytes.NewBufferString(`
+ynab_accounts:
+  - account_name: "Test Aave Supply Account"
+    payee_name: "Test Aave Supply Payee"
+    transaction_category_name: "Test Aave Supply Transaction Category"
+    wallet_address: "0x1234567890123456789012345678901234567890"
+    address_type: "aave_supply"
+    chain_name: "arbitrum"
+    token_address: "0x4567890123456789012345678901234567890"
+`))
+				Expect(err).ToNot(HaveOccurred(), "deserializing the Aave supply account should not fail")
+				Expect(syncConfig.Accounts).To(HaveLen(1), "there should be one Aave supply account")
+
+				account := syncConfig.Accounts[0]
+
+				Expect(account.GetAddressType()).To(Equal(config.AddressTypeAaveSupply), "the address type should be successfully parsed")
+
+				aaveSupplyAccount, err := account.AsAaveSupplyAccount()
+				Expect(err).ToNot(HaveOccurred(), "resolving the Aave supply account should not fail")
+
+				Expect(aaveSupplyAccount.AccountName).To(Equal("Test Aave Supply Account"), "the account name should be successfully parsed")
+				Expect(aaveSupplyAccount.WalletAddress).To(Equal("0x1234567890123456789012345678901234567890"), "the wallet address should be successfully parsed")
+				Expect(aaveSupplyAccount.ChainName).To(Equal("arbitrum"), "the chain name should be successfully parsed")
+				Expect(aaveSupplyAccount.ATokenAddress).To(Equal("0x4567890123456789012345678901234567890"), "the aToken address should be successfully parsed")
+			})
+		})
+
+		Context("Comet supply accounts", func() {
+			It("successfully deserializes the Comet supply account", func() {
+				syncConfig, err := config.FromYAML(bytes.NewBufferString(`
+ynab_accounts:
+  - account_name: "Test Comet Supply Account"
+    payee_name: "Test Comet Supply Payee"
+    transaction_category_name: "Test Comet Supply Transaction Category"
+    wallet_address: "0x1234567890123456789012345678901234567890"
+    address_type: "comet_supply"
+    chain_name: "base"
+    comet_address: "0x4567890123456789012345678901234567890"
+`))
+				Expect(err).ToNot(HaveOccurred(), "deserializing the Comet supply account should not fail")
+				Expect(syncConfig.Accounts).To(HaveLen(1), "there should be one Comet supply account")
+
+				account := syncConfig.Accounts[0]
+
+				Expect(account.GetAddressType()).To(Equal(config.AddressTypeCometSupply), "the address type should be successfully parsed")
+
+				cometSupplyAccount, err := account.AsCometSupplyAccount()
+				Expect(err).ToNot(HaveOccurred(), "resolving the Comet supply account should not fail")
+
+				Expect(cometSupplyAccount.AccountName).To(Equal("Test Comet Supply Account"), "the account name should be successfully parsed")
+				Expect(cometSupplyAccount.WalletAddress).To(Equal("0x1234567890123456789012345678901234567890"), "the wallet address should be successfully parsed")
+				Expect(cometSupplyAccount.ChainName).To(Equal("base"), "the chain name should be successfully parsed")
+				Expect(cometSupplyAccount.CometAddress).To(Equal("0x4567890123456789012345678901234567890"), "the Comet address should be successfully parsed")
+
+				_, err = account.AsCometDebtAccount()
+				Expect(err).To(HaveOccurred(), "a supply account should not be resolvable as a debt account")
+			})
+		})
+
+		Context("LP V2 accounts", func() {
+			It("successfully deserializes the LP V2 account", func() {
+				syncConfig, err := config.FromYAML(bytes.NewBufferString(`
+ynab_accounts:
+  - account_name: "Test LP V2 Account"
+    payee_name: "Test LP V2 Payee"
+    transaction_category_name: "Test LP V2 Transaction Category"
+    wallet_address: "0x1234567890123456789012345678901234567890"
+    address_type: "lp_v2"
+    chain_name: "ethereum"
+    pair_address: "0x4567890123456789012345678901234567890"
+`))
+				Expect(err).ToNot(HaveOccurred(), "deserializing the LP V2 account should not fail")
+				Expect(syncConfig.Accounts).To(HaveLen(1), "there should be one LP V2 account")
+
+				account := syncConfig.Accounts[0]
+
+				Expect(account.GetAddressType()).To(Equal(config.AddressTypeLPV2), "the address type should be successfully parsed")
+
+				lpV2Account, err := account.AsLPV2Account()
+				Expect(err).ToNot(HaveOccurred(), "resolving the LP V2 account should not fail")
+
+				Expect(lpV2Account.AccountName).To(Equal("Test LP V2 Account"), "the account name should be successfully parsed")
+				Expect(lpV2Account.WalletAddress).To(Equal("0x1234567890123456789012345678901234567890"), "the wallet address should be successfully parsed")
+				Expect(lpV2Account.ChainName).To(Equal("ethereum"), "the chain name should be successfully parsed")
+				Expect(lpV2Account.PairAddress).To(Equal("0x4567890123456789012345678901234567890"), "the pair address should be successfully parsed")
+			})
+
+			It("requires the pair address", func() {
+				_, err := config.AccountProperties{
+					"account_name":   "Test LP V2 Account",
+					"payee_name":     "Test LP V2 Payee",
+					"wallet_address": "0x1234567890123456789012345678901234567890",
+					"address_type":   "lp_v2",
+					"chain_name":     "ethereum",
+				}.AsLPV2Account()
+				Expect(err).To(HaveOccurred(), "an LP V2 account without a pair address should be rejected")
+			})
+		})
+
+		Context("liquid staking accounts", func() {
+			It("successfully deserializes the liquid staking account", func() {
+				syncConfig, err := config.FromYAML(bytes.NewBufferString(`
+ynab_accounts:
+  - account_name: "Test Liquid Staking Account"
+    payee_name: "Test Liquid Staking Payee"
+    transaction_category_name: "Test Liquid Staking Transaction Category"
+    wallet_address: "0x1234567890123456789012345678901234567890"
+    address_type: "liquid_staking"
+    chain_name: "ethereum"
+    token_address: "0x7f39C581F595B53c5cb19bD0b3f8dA6c935E2Ca0"
+    rate_function: "stEthPerToken"
+    rate_decimals: 27
+    underlying_token_address: "0xae7ab96520DE3A18E5e111B5EaAb095312D7fE84"
+`))
+				Expect(err).ToNot(HaveOccurred(), "deserializing the liquid staking account should not fail")
+				Expect(syncConfig.Accounts).To(HaveLen(1), "there should be one liquid staking account")
+
+				account := syncConfig.Accounts[0]
+
+				Expect(account.GetAddressType()).To(Equal(config.AddressTypeLiquidStaking), "the address type should be successfully parsed")
+
+				liquidStakingAccount, err := account.AsLiquidStakingAccount()
+				Expect(err).ToNot(HaveOccurred(), "resolving the liquid staking account should not fail")
+
+				Expect(liquidStakingAccount.AccountName).To(Equal("Test Liquid Staking Account"), "the account name should be successfully parsed")
+				Expect(liquidStakingAccount.TokenAddress).To(Equal("0x7f39C581F595B53c5cb19bD0b3f8dA6c935E2Ca0"), "the token address should be successfully parsed")
+				Expect(liquidStakingAccount.RateFunction).To(Equal("stEthPerToken"), "the rate function should be successfully parsed")
+				Expect(liquidStakingAccount.RateDecimals).To(Equal(27), "the rate decimals should be successfully parsed")
+				Expect(liquidStakingAccount.UnderlyingTokenAddress).ToNot(BeNil(), "the underlying token address should be set")
+				Expect(*liquidStakingAccount.UnderlyingTokenAddress).To(Equal("0xae7ab96520DE3A18E5e111B5EaAb095312D7fE84"), "the underlying token address should be successfully parsed")
+			})
+
+			It("defaults to an 18-decimal rate into the native coin", func() {
+				liquidStakingAccount, err := config.AccountProperties{
+					"account_name":   "Test Liquid Staking Account",
+					"payee_name":     "Test Liquid Staking Payee",
+					"wallet_address": "0x1234567890123456789012345678901234567890",
+					"address_type":   "liquid_staking",
+					"chain_name":     "ethereum",
+					"token_address":  "0xae78736Cd615f374D3085123A210448E74Fc6393",
+					"rate_function":  "getExchangeRate",
+				}.AsLiquidStakingAccount()
+				Expect(err).ToNot(HaveOccurred(), "resolving the liquid staking account should not fail")
+				Expect(liquidStakingAccount.RateDecimals).To(Equal(18), "the rate should default to 18 decimals")
+				Expect(liquidStakingAccount.UnderlyingTokenAddress).To(BeNil(), "the underlying asset should default to the native coin")
+			})
+
+			It("requires the rate function", func() {
+				_, err := config.AccountProperties{
+					"account_name":   "Test Liquid Staking Account",
+					"payee_name":     "Test Liquid Staking Payee",
+					"wallet_address": "0x1234567890123456789012345678901234567890",
+					"address_type":   "liquid_staking",
+					"chain_name":     "ethereum",
+					"token_address":  "0xae78736Cd615f374D3085123A210448E74Fc6393",
+				}.AsLiquidStakingAccount()
+				Expect(err).To(HaveOccurred(), "a liquid staking account without a rate function should be rejected")
+			})
+
+			It("rejects negative rate decimals", func() {
+				_, err := config.AccountProperties{
+					"account_name":   "Test Liquid Staking Account",
+					"payee_name":     "Test Liquid Staking Payee",
+					"wallet_address": "0x1234567890123456789012345678901234567890",
+					"address_type":   "liquid_staking",
+					"chain_name":     "ethereum",
+					"token_address":  "0xae78736Cd615f374D3085123A210448E74Fc6393",
+					"rate_function":  "getExchangeRate",
+					"rate_decimals":  -1,
+				}.AsLiquidStakingAccount()
+				Expect(err).To(HaveOccurred(), "negative rate decimals should be rejected")
+			})
+		})
+
+		Context("virtual price LP accounts", func() {
+			It("successfully deserializes the virtual price LP account", func() {
+				syncConfig, err := config.FromYAML(bytes.NewBufferString(`
+ynab_accounts:
+  - account_name: "Test Virtual Price LP Account"
+    payee_name: "Test Virtual Price LP Payee"
+    transaction_category_name: "Test Virtual Price LP Transaction Category"
+    wallet_address: "0x1234567890123456789012345678901234567890"
+    address_type: "virtual_price_lp"
+    chain_name: "ethereum"
+    token_address: "0x6c3F90f043a72FA612cbac8115EE7e52BDe6E490"
+    pool_address: "0xbEbc44782C7dB0a1A60Cb6fe97d0b483032FF1C7"
+    reference_asset:
+      contract_address: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"
+`))
+				Expect(err).ToNot(HaveOccurred(), "deserializing the virtual price LP account should not fail")
+				Expect(syncConfig.Accounts).To(HaveLen(1), "there should be one virtual price LP account")
+
+				account := syncConfig.Accounts[0]
+
+				Expect(account.GetAddressType()).To(Equal(config.AddressTypeVirtualPriceLP), "the address type should be successfully parsed")
+
+				virtualPriceLPAccount, err := account.AsVirtualPriceLPAccount()
+				Expect(err).ToNot(HaveOccurred(), "resolving the virtual price LP account should not fail")
+
+				Expect(virtualPriceLPAccount.AccountName).To(Equal("Test Virtual Price LP Account"), "the account name should be successfully parsed")
+				Expect(virtualPriceLPAccount.TokenAddress).To(Equal("0x6c3F90f043a72FA612cbac8115EE7e52BDe6E490"), "the token address should be successfully parsed")
+				Expect(virtualPriceLPAccount.PoolAddress).To(Equal("0xbEbc44782C7dB0a1A60Cb6fe97d0b483032FF1C7"), "the pool address should be successfully parsed")
+				Expect(virtualPriceLPAccount.RateFunction).To(Equal("get_virtual_price"), "the rate function should default to that of Curve pools")
+				Expect(virtualPriceLPAccount.RateDecimals).To(Equal(18), "the rate decimals should default to 18")
+				Expect(virtualPriceLPAccount.ReferenceTokenAddress).ToNot(BeNil(), "the reference token address should be set")
+				Expect(*virtualPriceLPAccount.ReferenceTokenAddress).To(Equal("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"), "the reference token address should be successfully parsed")
+			})
+
+			It("defaults the pool to the LP token and allows a native reference asset", func() {
+				virtualPriceLPAccount, err := config.AccountProperties{
+					"account_name":   "Test Virtual Price LP Account",
+					"payee_name":     "Test Virtual Price LP Payee",
+					"wallet_address": "0x1234567890123456789012345678901234567890",
+					"address_type":   "virtual_price_lp",
+					"chain_name":     "ethereum",
+					"token_address":  "0x32296969Ef14EB0c6d29669C550D4a0449130230",
+					"rate_function":  "getRate",
+					"reference_asset": map[string]any{
+						"contract_address": "",
+					},
+				}.AsVirtualPriceLPAccount()
+				Expect(err).ToNot(HaveOccurred(), "resolving the virtual price LP account should not fail")
+				Expect(virtualPriceLPAccount.PoolAddress).To(Equal("0x32296969Ef14EB0c6d29669C550D4a0449130230"), "the pool should default to the LP token")
+				Expect(virtualPriceLPAccount.RateFunction).To(Equal("getRate"), "the rate function should be successfully parsed")
+				Expect(virtualPriceLPAccount.ReferenceTokenAddress).To(BeNil(), "a blank contract address should describe the native coin")
+			})
+
+			It("requires the reference asset", func() {
+				_, err := config.AccountProperties{
+					"account_name":   "Test Virtual Price LP Account",
+					"payee_name":     "Test Virtual Price LP Payee",
+					"wallet_address": "0x1234567890123456789012345678901234567890",
+					"address_type":   "virtual_price_lp",
+					"chain_name":     "ethereum",
+					"token_address":  "0x6c3F90f043a72FA612cbac8115EE7e52BDe6E490",
+				}.AsVirtualPriceLPAccount()
+				Expect(err).To(HaveOccurred(), "a virtual price LP account without a reference asset should be rejected")
+			})
+		})
+
+		Context("Uniswap V3 accounts", func() {
+			It("successfully deserializes the Uniswap V3 account", func() {
+				syncConfig, err := config.FromYAML(bytes.NewBufferString(`
+ynab_accounts:
+  - account_name: "Test Uniswap V3 Account"
+    payee_name: "Test Uniswap V3 Payee"
+    transaction_category_name: "Test Uniswap V3 Transaction Category"
+    wallet_address: "0x1234567890123456789012345678901234567890"
+    address_type: "uniswap_v3"
+    chain_name: "base"
+    position_manager_address: "0x03a520b32C04BF3bEEf7BEb72E919cf822Ed34f1"
+    include_uncollected_fees: true
+`))
+				Expect(err).ToNot(HaveOccurred(), "deserializing the Uniswap V3 account should not fail")
+				Expect(syncConfig.Accounts).To(HaveLen(1), "there should be one Uniswap V3 account")
+
+				account := syncConfig.Accounts[0]
+
+				Expect(account.GetAddressType()).To(Equal(config.AddressTypeUniswapV3), "the address type should be successfully parsed")
+
+				uniswapV3Account, err := account.AsUniswapV3Account()
+				Expect(err).ToNot(HaveOccurred(), "resolving the Uniswap V3 account should not fail")
+
+				Expect(uniswapV3Account.AccountName).To(Equal("Test Uniswap V3 Account"), "the account name should be successfully parsed")
+				Expect(uniswapV3Account.WalletAddress).To(Equal("0x1234567890123456789012345678901234567890"), "the wallet address should be successfully parsed")
+				Expect(uniswapV3Account.ChainName).To(Equal("base"), "the chain name should be successfully parsed")
+				Expect(uniswapV3Account.PositionManagerAddress).To(Equal("0x03a520b32C04BF3bEEf7BEb72E919cf822Ed34f1"), "the position manager address should be successfully parsed")
+				Expect(uniswapV3Account.IncludeUncollectedFees).To(BeTrue(), "whether uncollected fees are included should be successfully parsed")
+			})
+
+			It("excludes uncollected fees by default", func() {
+				uniswapV3Account, err := config.AccountProperties{
+					"account_name":             "Test Uniswap V3 Account",
+					"payee_name":               "Test Uniswap V3 Payee",
+					"wallet_address":           "0x1234567890123456789012345678901234567890",
+					"address_type":             "uniswap_v3",
+					"chain_name":               "base",
+					"position_manager_address": "0x03a520b32C04BF3bEEf7BEb72E919cf822Ed34f1",
+				}.AsUniswapV3Account()
+				Expect(err).ToNot(HaveOccurred(), "resolving the Uniswap V3 account should not fail")
+				Expect(uniswapV3Account.IncludeUncollectedFees).To(BeFalse(), "uncollected fees should not be included unless configured")
+			})
+
+			It("requires the position manager address", func() {
+				_, err := config.AccountProperties{
+					"account_name":   "Test Uniswap V3 Account",
+					"payee_name":     "Test Uniswap V3 Payee",
+					"wallet_address": "0x1234567890123456789012345678901234567890",
+					"address_type":   "uniswap_v3",
+					"chain_name":     "base",
+				}.AsUniswapV3Account()
+				Expect(err).To(HaveOccurred(), "a Uniswap V3 account without a position manager address should be rejected")
+			})
+
+			It("rejects a non-boolean fee inclusion", func() {
+				_, err := config.AccountProperties{
+					"account_name":             "Test Uniswap V3 Account",
+					"payee_name":               "Test Uniswap V3 Payee",
+					"wallet_address":           "0x1234567890123456789012345678901234567890",
+					"address_type":             "uniswap_v3",
+					"chain_name":               "base",
+					"position_manager_address": "0x03a520b32C04BF3bEEf7BEb72E919cf822Ed34f1",
+					"include_uncollected_fees": "yes",
+				}.AsUniswapV3Account()
+				Expect(err).To(HaveOccurred(), "a fee inclusion that is not a boolean should be rejected")
+			})
+		})
+
+		Context("Aave debt accounts", func() {
+			It("successfully deserializes the Aave debt account", func() {
+				aaveDebtAccountYAML := map[string]any{
+					"account_name":   "Test Aave Debt Account",
+					"payee_name":     "Test Aave Debt Payee",
+					"wallet_address": "0x1234567890123456789012345678901234567890",
+					"address_type":   "aave_debt",
+					"chain_name":     "ethereum",
+					"token_address":  "0x4567890123456789012345678901234567890",
+				}
+
+				yamlBytes, err := yaml.Marshal(map[string]any{
+					"ynab_accounts": []any{aaveDebtAccountYAML},
+				})
+				Expect(err).ToNot(HaveOccurred(), "serializing the Aave debt account should not fail")
+
+				syncConfig, err := config.FromYAML(bytes.NewBuffer(yamlBytes))
+				Expect(err).ToNot(HaveOccurred(), "deserializing the Aave debt account should not fail")
+
+				Expect(syncConfig.Accounts).To(HaveLen(1), "there should be one Aave debt account")
+
+				account := syncConfig.Accounts[0]
+
+				Expect(account.GetAddressType()).To(Equal(config.AddressTypeAaveDebt), "the address type should be successfully parsed")
+
+				aaveDebtAccount, err := account.AsAaveDebtAccount()
+				Expect(err).ToNot(HaveOccurred(), "resolving the Aave debt account should not fail")
+
+				Expect(aaveDebtAccount.AccountName).To(Equal("Test Aave Debt Account"), "the account name should be successfully parsed")
+				Expect(aaveDebtAccount.PayeeName).To(Equal("Test Aave Debt Payee"), "the payee name should be successfully parsed")
+				Expect(aaveDebtAccount.TransactionCategoryName).To(BeEmpty(), "the transaction category name should be optional")
+				Expect(aaveDebtAccount.WalletAddress).To(Equal("0x1234567890123456789012345678901234567890"), "the wallet address should be successfully parsed")
+				Expect(aaveDebtAccount.ChainName).To(Equal("ethereum"), "the chain name should be successfully parsed")
+				Expect(aaveDebtAccount.DebtTokenAddress).To(Equal("0x4567890123456789012345678901234567890"), "the debt token address should be successfully parsed")
+			})
+		})
+
+		Context("Comet debt accounts", func() {
+			var cometDebtAccountYAML map[string]any
+
+			BeforeEach(func() {
+				cometDebtAccountYAML = map[string]any{
+					"account_name":              "Test Comet Debt Account",
+					"payee_name":                "Test Comet Debt Payee",
+					"transaction_category_name": "Test Comet Debt Transaction Category",
+					"wallet_address":            "0x1234567890123456789012345678901234567890",
+					"address_type":              "comet_debt",
+					"chain_name":                "base",
+					"comet_address":             "0x4567890123456789012345678901234567890",
+				}
+			})
+
+			It("successfully deserializes the Comet debt account", func() {
+				yamlBytes, err := yaml.Marshal(map[string]any{
+					"ynab_accounts": []any{cometDebtAccountYAML},
+				})
+				Expect(err).ToNot(HaveOccurred(), "serializing the Comet debt account should not fail")
+
+				syncConfig, err := config.FromYAML(bytes.NewBuffer(yamlBytes))
+				Expect(err).ToNot(HaveOccurred(), "deserializing the Comet debt account should not fail")
+
+				Expect(syncConfig.Accounts).To(HaveLen(1), "there should be one Comet debt account")
+
+				account := syncConfig.Accounts[0]
+
+				Expect(account.GetAddressType()).To(Equal(config.AddressTypeCometDebt), "the address type should be successfully parsed")
+
+				cometDebtAccount, err := account.AsCometDebtAccount()
+				Expect(err).ToNot(HaveOccurred(), "resolving the Comet debt account should not fail")
+
+				Expect(cometDebtAccount.AccountName).To(Equal("Test Comet Debt Account"), "the account name should be successfully parsed")
+				Expect(cometDebtAccount.PayeeName).To(Equal("Test Comet Debt Payee"), "the payee name should be successfully parsed")
+				Expect(cometDebtAccount.TransactionCategoryName).To(Equal("Test Comet Debt Transaction Category"), "the transaction category name should be successfully parsed")
+				Expect(cometDebtAccount.WalletAddress).To(Equal("0x1234567890123456789012345678901234567890"), "the wallet address should be successfully parsed")
+				Expect(cometDebtAccount.ChainName).To(Equal("base"), "the chain name should be successfully parsed")
+				Expect(cometDebtAccount.CometAddress).To(Equal("0x4567890123456789012345678901234567890"), "the Comet address should be successfully parsed")
+			})
+
+			It("requires the Comet address", func() {
+				delete(cometDebtAccountYAML, "comet_address")
+
+				_, err := config.AccountProperties(cometDebtAccountYAML).AsCometDebtAccount()
+				Expect(err).To(HaveOccurred(), "a Comet debt account without a Comet address should be rejected")
+			})
+		})
+
+		Context("account prices", func() {
+			parseAccount := func(accountYAML string) config.AccountProperties {
+				syncConfig, err := config.FromYAML(bytes.NewBufferString("ynab_accounts:\n" + accountYAML))
+				Expect(err).ToNot(HaveOccurred(), "deserializing the account should not fail")
+				Expect(syncConfig.Accounts).To(HaveLen(1), "there should be one account")
+
+				return syncConfig.Accounts[0]
+			}
+
+			It("has no price if none is configured", func() {
+				accountPrice, err := parseAccount(`  - account_name: "Test Account"`).GetPrice()
+				Expect(err).ToNot(HaveOccurred(), "resolving the price should not fail")
+				Expect(accountPrice).To(BeNil(), "there should be no price")
+			})
+
+			It("parses a fixed price", func() {
+				accountPrice, err := parseAccount(`
+  - account_name: "Test Account"
+    price:
+      fixed: 1.05
+`).GetPrice()
+				Expect(err).ToNot(HaveOccurred(), "resolving the price should not fail")
+				Expect(accountPrice.FixedPrice).To(HaveValue(Equal("1.05")), "the fixed price should be parsed")
+			})
+
+			It("parses a pegged asset", func() {
+				accountPrice, err := parseAccount(`
+  - account_name: "Test Account"
+    price:
+      pegged_to:
+        chain_id: 1
+        token_address: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"
+`).GetPrice()
+				Expect(err).ToNot(HaveOccurred(), "resolving the price should not fail")
+				Expect(accountPrice.PeggedTo).ToNot(BeNil(), "there should be a pegged asset")
+				Expect(accountPrice.PeggedTo.ChainID).To(HaveValue(Equal(uint64(1))), "the chain ID should be parsed")
+				Expect(accountPrice.PeggedTo.ChainName).To(BeEmpty(), "no chain name should be parsed")
+				Expect(accountPrice.PeggedTo.TokenAddress).To(HaveValue(Equal("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")), "the token address should be parsed")
+			})
+
+			It("parses a pegged native coin", func() {
+				accountPrice, err := parseAccount(`
+  - account_name: "Test Account"
+    price:
+      pegged_to:
+        chain_name: "ethereum"
+`).GetPrice()
+				Expect(err).ToNot(HaveOccurred(), "resolving the price should not fail")
+				Expect(accountPrice.PeggedTo.ChainName).To(Equal("ethereum"), "the chain name should be parsed")
+				Expect(accountPrice.PeggedTo.TokenAddress).To(BeNil(), "there should be no token address for a native coin")
+			})
+
+			It("parses a Coingecko coin ID", func() {
+				accountPrice, err := parseAccount(`
+  - account_name: "Test Account"
+    price:
+      coingecko_coin_id: "usd-coin"
+`).GetPrice()
+				Expect(err).ToNot(HaveOccurred(), "resolving the price should not fail")
+				Expect(accountPrice.CoingeckoCoinID).To(Equal("usd-coin"), "the coin ID should be parsed")
+			})
+
+			DescribeTable("invalid prices", func(priceYAML string) {
+				_, err := parseAccount("  - account_name: \"Test Account\"\n    price:\n" + priceYAML).GetPrice()
+				Expect(err).To(HaveOccurred(), "resolving the price should fail")
+			},
+				Entry("no price", "      {}\n"),
+				Entry("more than one price", "      fixed: \"1\"\n      coingecko_coin_id: \"usd-coin\"\n"),
+				Entry("pegged without a chain", "      pegged_to:\n        token_address: \"0x1234\"\n"),
+				Entry("pegged with both a chain name and ID", "      pegged_to:\n        chain_name: \"ethereum\"\n        chain_id: 1\n"))
+		})
+
+		Context("holdings", func() {
+			It("has no holdings if none are configured", func() {
+				holdings, err := config.AccountProperties{
+					"account_name": "Test Account",
+				}.GetHoldings()
+				Expect(err).ToNot(HaveOccurred(), "resolving the holdings should not fail")
+				Expect(holdings).To(BeNil(), "there should be no holdings")
+			})
+
+			It("inherits the properties of the account that the holdings do not set", func() {
+				syncConfig, err := config.FromYAML(bytes.NewBufferString(`
+ynab_accounts:
+  - account_name: "Test Aggregate Account"
+    payee_name: "Test Aggregate Payee"
+    wallet_address: "0x1234567890123456789012345678901234567890"
+    address_type: "erc20"
+    holdings:
+      - chain_name: "ethereum"
+        token_address: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"
+      - chain_name: "base"
+        token_address: "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913"
+        wallet_address: "0x0987654321098765432109876543210987654321"
+`))
+				Expect(err).ToNot(HaveOccurred(), "deserializing the aggregate account should not fail")
+				Expect(syncConfig.Accounts).To(HaveLen(1), "there should be one aggregate account")
+
+				holdings, err := syncConfig.Accounts[0].GetHoldings()
+				Expect(err).ToNot(HaveOccurred(), "resolving the holdings should not fail")
+				Expect(holdings).To(HaveLen(2), "there should be two holdings")
+
+				for _, holding := range holdings {
+					Expect(holding.GetAddressType()).To(Equal(config.AddressTypeERC20), "the address type should be inherited")
+					Expect(holding.GetHoldings()).To(BeNil(), "the holdings should not inherit the holdings of the account")
+				}
+
+				ethereumAccount, err := holdings[0].AsERC20Account()
+				Expect(err).ToNot(HaveOccurred(), "resolving the first holding should not fail")
+				Expect(ethereumAccount.AccountName).To(Equal("Test Aggregate Account"), "the account name should be inherited")
+				Expect(ethereumAccount.PayeeName).To(Equal("Test Aggregate Payee"), "the payee name should be inherited")
+				Expect(ethereumAccount.ChainName).To(Equal("ethereum"), "the chain name should be that of the holding")
+				Expect(ethereumAccount.WalletAddress).To(Equal("0x1234567890123456789012345678901234567890"), "the wallet address should be inherited")
+
+				baseAccount, err := holdings[1].AsERC20Account()
+				Expect(err).ToNot(HaveOccurred(), "resolving the second holding should not fail")
+				Expect(baseAccount.ChainName).To(Equal("base"), "the chain name should be that of the holding")
+				Expect(baseAccount.WalletAddress).To(Equal("0x0987654321098765432109876543210987654321"), "the wallet address of the holding should override that of the account")
+			})
+
+			DescribeTable("invalid holdings", func(holdingsAny any) {
+				_, err := config.AccountProperties{
+					"account_name": "Test Account",
+					"holdings":     holdingsAny,
+				}.GetHoldings()
+				Expect(err).To(HaveOccurred(), "resolving the holdings should fail")
+			},
+				Entry("no holdings", []any{}),
+				Entry("not a list", "0x1234567890123456789012345678901234567890"),
+				Entry("a holding that is not a map", []any{"0x1234567890123456789012345678901234567890"}),
+				Entry("nested holdings", []any{map[string]any{"holdings": []any{map[string]any{"chain_name": "base"}}}}),
+				Entry("a holding that sets the account name", []any{map[string]any{"account_name": "Other Account"}}),
+				Entry("a holding that sets the payee name", []any{map[string]any{"payee_name": "Other Payee"}}),
+				Entry("a holding that sets the category", []any{map[string]any{"transaction_category_name": "Other Category"}}))
+
+			It("resolves the YNAB account of the aggregate from the account itself", func() {
+				syncableAccount, err := config.AccountProperties{
+					"account_name":              "Test Aggregate Account",
+					"payee_name":                "Test Aggregate Payee",
+					"transaction_category_name": "Test Aggregate Category",
+					"holdings":                  []any{map[string]any{"address_type": "native", "chain_name": "base"}},
+				}.AsSyncableAccount()
+				Expect(err).ToNot(HaveOccurred(), "resolving the YNAB account should not fail")
+				Expect(syncableAccount.AccountName).To(Equal("Test Aggregate Account"), "the account name should be parsed")
+				Expect(syncableAccount.PayeeName).To(Equal("Test Aggregate Payee"), "the payee name should be parsed")
+				Expect(syncableAccount.TransactionCategoryName).To(Equal("Test Aggregate Category"), "the category should be parsed")
+			})
+
+			It("rejects a YNAB account configured by more than one entry", func() {
+				_, err := config.FromYAML(bytes.NewBufferString(`
+ynab_accounts:
+  - account_name: "Test Duplicated Account"
+    payee_name: "Test Payee"
+    wallet_address: "0x1234567890123456789012345678901234567890"
+    address_type: "native"
+    chain_name: "ethereum"
+  - account_name: "Test Duplicated Account"
+    payee_name: "Test Payee"
+    wallet_address: "0x1234567890123456789012345678901234567890"
+    address_type: "native"
+    chain_name: "base"
+`))
+				Expect(err).To(MatchError(ContainSubstring("list its holdings within a single entry")), "an account configured by two entries should be rejected")
+			})
+		})
 	})
 })
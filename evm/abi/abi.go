@@ -0,0 +1,46 @@
+// Package abi implements encoding and decoding of values per the Solidity contract ABI specification.
+package abi
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Selector computes the 4-byte function selector for the given function name and argument types.
+func Selector(functionName string, argTypes []Type) []byte {
+	argSignatures := make([]string, len(argTypes))
+	for i, argType := range argTypes {
+		argSignatures[i] = argType.String()
+	}
+
+	signature := functionName + "(" + strings.Join(argSignatures, ",") + ")"
+	return crypto.Keccak256([]byte(signature))[:4]
+}
+
+// EncodeCall builds the calldata for invoking the given function with the given arguments.
+func EncodeCall(functionName string, argTypes []Type, args []any) ([]byte, error) {
+	encodedArgs, err := Encode(argTypes, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode arguments for %s: %w", functionName, err)
+	}
+
+	return append(Selector(functionName, argTypes), encodedArgs...), nil
+}
+
+// DecodeHex decodes the given 0x-prefixed hex data, such as the result of an eth_call, according to the given type signatures.
+func DecodeHex(typeSignatures []string, hexData string) ([]any, error) {
+	types, err := ParseTypes(typeSignatures)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := hex.DecodeString(strings.TrimPrefix(hexData, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid hex data '%s': %w", hexData, err)
+	}
+
+	return Decode(types, data)
+}
@@ -0,0 +1,13 @@
+package abi_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestABI(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "ABI Suite")
+}
@@ -0,0 +1,164 @@
+package abi_test
+
+import (
+	"encoding/hex"
+	"math/big"
+	"strings"
+
+	"github.com/jrh3k5/cryptonabber-sync/v3/evm/abi"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ABI", func() {
+	mustParseTypes := func(signatures ...string) []abi.Type {
+		types, err := abi.ParseTypes(signatures)
+		Expect(err).ToNot(HaveOccurred(), "parsing the types should not fail")
+		return types
+	}
+
+	// joinWords joins the given hex words, which are listed separately to keep the expectations readable
+	joinWords := func(words ...string) string {
+		return strings.Join(words, "")
+	}
+
+	Context("ParseType", func() {
+		DescribeTable("canonical signatures", func(signature string, expected string) {
+			parsed, err := abi.ParseType(signature)
+			Expect(err).ToNot(HaveOccurred(), "parsing the type should not fail")
+			Expect(parsed.String()).To(Equal(expected), "the canonical signature should be rendered")
+		},
+			Entry("uint alias", "uint", "uint256"),
+			Entry("int alias", "int", "int256"),
+			Entry("sized integer", "uint112", "uint112"),
+			Entry("fixed bytes", "bytes32", "bytes32"),
+			Entry("dynamic array", "address[]", "address[]"),
+			Entry("fixed array of dynamic arrays", "uint256[][2]", "uint256[][2]"),
+			Entry("tuple", "(address, uint256)", "(address,uint256)"),
+			Entry("tuple keyword", "tuple(bool,(string,bytes))[]", "(bool,(string,bytes))[]"))
+
+		DescribeTable("invalid signatures", func(signature string) {
+			_, err := abi.ParseType(signature)
+			Expect(err).To(HaveOccurred(), "parsing the type should fail")
+		},
+			Entry("unknown type", "uint7"),
+			Entry("oversized fixed bytes", "bytes33"),
+			Entry("unbalanced tuple", "(address,uint256"),
+			Entry("invalid array length", "uint256[x]"))
+	})
+
+	Context("EncodeCall", func() {
+		It("encodes static arguments", func() {
+			callData, err := abi.EncodeCall("baz", mustParseTypes("uint32", "bool"), []any{69, true})
+			Expect(err).ToNot(HaveOccurred(), "encoding the call should not fail")
+			Expect(hex.EncodeToString(callData)).To(Equal(joinWords(
+				"cdcd77c0",
+				"0000000000000000000000000000000000000000000000000000000000000045",
+				"0000000000000000000000000000000000000000000000000000000000000001",
+			)), "the call data should match the Solidity specification")
+		})
+
+		It("encodes dynamic arguments", func() {
+			callData, err := abi.EncodeCall("sam", mustParseTypes("bytes", "bool", "uint256[]"), []any{[]byte("dave"), true, []int{1, 2, 3}})
+			Expect(err).ToNot(HaveOccurred(), "encoding the call should not fail")
+			Expect(hex.EncodeToString(callData)).To(Equal(joinWords(
+				"a5643bf2",
+				"0000000000000000000000000000000000000000000000000000000000000060",
+				"0000000000000000000000000000000000000000000000000000000000000001",
+				"00000000000000000000000000000000000000000000000000000000000000a0",
+				"0000000000000000000000000000000000000000000000000000000000000004",
+				"6461766500000000000000000000000000000000000000000000000000000000",
+				"0000000000000000000000000000000000000000000000000000000000000003",
+				"0000000000000000000000000000000000000000000000000000000000000001",
+				"0000000000000000000000000000000000000000000000000000000000000002",
+				"0000000000000000000000000000000000000000000000000000000000000003",
+			)), "the call data should match the Solidity specification")
+		})
+
+		It("encodes mixed static and dynamic arguments", func() {
+			callData, err := abi.EncodeCall("f", mustParseTypes("uint256", "uint32[]", "bytes10", "bytes"), []any{
+				big.NewInt(0x123),
+				[]any{0x456, 0x789},
+				[]byte("1234567890"),
+				[]byte("Hello, world!"),
+			})
+			Expect(err).ToNot(HaveOccurred(), "encoding the call should not fail")
+			Expect(hex.EncodeToString(callData)).To(Equal(joinWords(
+				"8be65246",
+				"0000000000000000000000000000000000000000000000000000000000000123",
+				"0000000000000000000000000000000000000000000000000000000000000080",
+				"3132333435363738393000000000000000000000000000000000000000000000",
+				"00000000000000000000000000000000000000000000000000000000000000e0",
+				"0000000000000000000000000000000000000000000000000000000000000002",
+				"0000000000000000000000000000000000000000000000000000000000000456",
+				"0000000000000000000000000000000000000000000000000000000000000789",
+				"000000000000000000000000000000000000000000000000000000000000000d",
+				"48656c6c6f2c20776f726c642100000000000000000000000000000000000000",
+			)), "the call data should match the Solidity specification")
+		})
+
+		It("encodes negative signed integers as two's complement", func() {
+			callData, err := abi.EncodeCall("observe", mustParseTypes("int24"), []any{-1})
+			Expect(err).ToNot(HaveOccurred(), "encoding the call should not fail")
+			Expect(hex.EncodeToString(callData[4:])).To(Equal(strings.Repeat("f", 64)), "the value should be sign-extended across the word")
+		})
+
+		It("rejects values that overflow their type", func() {
+			_, err := abi.EncodeCall("f", mustParseTypes("uint8"), []any{256})
+			Expect(err).To(MatchError(ContainSubstring("overflows uint8")), "the overflow should be reported")
+		})
+
+		It("rejects a mismatched number of arguments", func() {
+			_, err := abi.EncodeCall("f", mustParseTypes("uint8", "address"), []any{1})
+			Expect(err).To(HaveOccurred(), "a missing argument should be reported")
+		})
+	})
+
+	Context("Decode", func() {
+		It("round-trips nested dynamic values", func() {
+			types := mustParseTypes("address", "(string,uint112[2])", "bytes[]", "int16")
+			values := []any{
+				"0x4838B106FCe9647Bdf1E7877BF73cE8B0BAD5f97",
+				[]any{"reserves", []any{big.NewInt(1000), big.NewInt(2000)}},
+				[]any{[]byte{0x01, 0x02}, []byte{}},
+				big.NewInt(-300),
+			}
+
+			encoded, err := abi.Encode(types, values)
+			Expect(err).ToNot(HaveOccurred(), "encoding the values should not fail")
+
+			decoded, err := abi.Decode(types, encoded)
+			Expect(err).ToNot(HaveOccurred(), "decoding the values should not fail")
+			Expect(decoded).To(Equal(values), "the decoded values should match the encoded values")
+		})
+
+		It("decodes multiple static return values", func() {
+			decoded, err := abi.DecodeHex([]string{"uint112", "uint112", "uint32"}, "0x"+joinWords(
+				"00000000000000000000000000000000000000000000000000000000000003e8",
+				"00000000000000000000000000000000000000000000000000000000000007d0",
+				"0000000000000000000000000000000000000000000000000000000065f0a1b2",
+			))
+			Expect(err).ToNot(HaveOccurred(), "decoding the values should not fail")
+			Expect(decoded).To(HaveLen(3), "all values should be decoded")
+			Expect(decoded[0]).To(Equal(big.NewInt(1000)), "the first value should be decoded")
+			Expect(decoded[1]).To(Equal(big.NewInt(2000)), "the second value should be decoded")
+			Expect(decoded[2]).To(Equal(big.NewInt(0x65f0a1b2)), "the third value should be decoded")
+		})
+
+		It("decodes addresses with checksum casing", func() {
+			decoded, err := abi.DecodeHex([]string{"address"}, "0x0000000000000000000000004838b106fce9647bdf1e7877bf73ce8b0bad5f97")
+			Expect(err).ToNot(HaveOccurred(), "decoding the address should not fail")
+			Expect(decoded[0]).To(Equal("0x4838B106FCe9647Bdf1E7877BF73cE8B0BAD5f97"), "the address should be checksummed")
+		})
+
+		It("rejects truncated data", func() {
+			_, err := abi.DecodeHex([]string{"uint256"}, "0x")
+			Expect(err).To(HaveOccurred(), "decoding empty data should fail")
+		})
+
+		It("rejects out-of-bounds offsets", func() {
+			_, err := abi.DecodeHex([]string{"string"}, "0x00000000000000000000000000000000000000000000000000000000000000ff")
+			Expect(err).To(HaveOccurred(), "decoding an out-of-bounds offset should fail")
+		})
+	})
+})
@@ -0,0 +1,147 @@
+package abi
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Decode decodes the given data, such as the return data of a function call, according to the given types.
+//
+// Values are decoded into the following Go types:
+//   - uint<M>, int<M>: *big.Int
+//   - address: a checksummed hex string (e.g., "0x4838B106FCe9647Bdf1E7877BF73cE8B0BAD5f97")
+//   - bool: bool
+//   - bytes<M>, bytes: []byte
+//   - string: string
+//   - T[k], T[], tuples: []any
+func Decode(types []Type, data []byte) ([]any, error) {
+	return decodeSequence(types, data)
+}
+
+// decodeSequence decodes a tuple-like sequence of values whose head begins at the start of the given data.
+func decodeSequence(types []Type, data []byte) ([]any, error) {
+	values := make([]any, len(types))
+	headOffset := 0
+	for i, t := range types {
+		var value any
+		var err error
+		if t.IsDynamic() {
+			var tailOffset int
+			tailOffset, err = readLength(data, headOffset)
+			if err == nil {
+				if tailOffset > len(data) {
+					err = fmt.Errorf("offset %d exceeds data length %d", tailOffset, len(data))
+				} else {
+					value, err = decodeValue(t, data[tailOffset:])
+				}
+			}
+		} else {
+			if headOffset+t.headSize() > len(data) {
+				err = fmt.Errorf("value requires %d bytes at offset %d, but data is only %d bytes", t.headSize(), headOffset, len(data))
+			} else {
+				value, err = decodeValue(t, data[headOffset:])
+			}
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode value %d as %s: %w", i, t, err)
+		}
+
+		values[i] = value
+		headOffset += t.headSize()
+	}
+
+	return values, nil
+}
+
+// decodeValue decodes a single value of the given type that begins at the start of the given data.
+func decodeValue(t Type, data []byte) (any, error) {
+	switch t.Kind {
+	case KindUint, KindInt, KindAddress, KindBool, KindFixedBytes:
+		if len(data) < wordSize {
+			return nil, fmt.Errorf("expected a %d-byte word, but only %d bytes remain", wordSize, len(data))
+		}
+		return decodeWord(t, data[:wordSize])
+	case KindBytes, KindString:
+		length, err := readLength(data, 0)
+		if err != nil {
+			return nil, err
+		} else if wordSize+length > len(data) {
+			return nil, fmt.Errorf("length %d exceeds remaining data length %d", length, len(data)-wordSize)
+		}
+
+		content := make([]byte, length)
+		copy(content, data[wordSize:wordSize+length])
+		if t.Kind == KindString {
+			return string(content), nil
+		}
+		return content, nil
+	case KindSlice:
+		length, err := readLength(data, 0)
+		if err != nil {
+			return nil, err
+		} else if length > (len(data)-wordSize)/max(t.Elem.headSize(), 1) {
+			return nil, fmt.Errorf("slice length %d exceeds remaining data length %d", length, len(data)-wordSize)
+		}
+		return decodeSequence(repeatType(*t.Elem, length), data[wordSize:])
+	case KindArray:
+		return decodeSequence(repeatType(*t.Elem, t.Size), data)
+	case KindTuple:
+		return decodeSequence(t.Components, data)
+	}
+
+	return nil, fmt.Errorf("unsupported type kind: %d", t.Kind)
+}
+
+func decodeWord(t Type, word []byte) (any, error) {
+	switch t.Kind {
+	case KindUint:
+		value := new(big.Int).SetBytes(word)
+		if value.BitLen() > t.Size {
+			return nil, fmt.Errorf("value %s overflows %s", value, t)
+		}
+		return value, nil
+	case KindInt:
+		value := new(big.Int).SetBytes(word)
+		if word[0]&0x80 != 0 {
+			value.Sub(value, new(big.Int).Lsh(big.NewInt(1), wordSize*8))
+		}
+
+		limit := new(big.Int).Lsh(big.NewInt(1), uint(t.Size-1))
+		if value.Cmp(limit) >= 0 || value.Cmp(new(big.Int).Neg(limit)) < 0 {
+			return nil, fmt.Errorf("value %s overflows %s", value, t)
+		}
+		return value, nil
+	case KindAddress:
+		return common.BytesToAddress(word[wordSize-20:]).Hex(), nil
+	case KindBool:
+		value := new(big.Int).SetBytes(word)
+		if value.BitLen() > 1 {
+			return nil, errors.New("invalid boolean value")
+		}
+		return value.Sign() == 1, nil
+	case KindFixedBytes:
+		fixedBytes := make([]byte, t.Size)
+		copy(fixedBytes, word[:t.Size])
+		return fixedBytes, nil
+	}
+
+	return nil, fmt.Errorf("type %s is not a single-word type", t)
+}
+
+// readLength reads an offset or length word at the given position within the data.
+func readLength(data []byte, position int) (int, error) {
+	if position+wordSize > len(data) {
+		return 0, fmt.Errorf("expected a %d-byte word at offset %d, but data is only %d bytes", wordSize, position, len(data))
+	}
+
+	value := new(big.Int).SetBytes(data[position : position+wordSize])
+	if !value.IsInt64() || value.Int64() > int64(len(data)) {
+		return 0, fmt.Errorf("offset or length %s exceeds data length %d", value, len(data))
+	}
+
+	return int(value.Int64()), nil
+}
@@ -0,0 +1,290 @@
+package abi
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+)
+
+// Encode encodes the given values according to the given types, as they would be encoded as the arguments of a function call.
+//
+// Values are expected to be of the following Go types:
+//   - uint<M>, int<M>: *big.Int or any Go integer type
+//   - address: a hex string (e.g., "0x4838B106FCe9647Bdf1E7877BF73cE8B0BAD5f97") or a 20-byte []byte
+//   - bool: bool
+//   - bytes<M>, bytes: []byte or a 0x-prefixed hex string
+//   - string: string
+//   - T[k], T[]: a slice or array of values for T
+//   - tuples: a slice or array of values for each component
+func Encode(types []Type, values []any) ([]byte, error) {
+	if len(types) != len(values) {
+		return nil, fmt.Errorf("mismatched number of types (%d) and values (%d)", len(types), len(values))
+	}
+
+	return encodeSequence(types, values)
+}
+
+// encodeSequence encodes the given values as a tuple: a head of static values and offsets, followed by a tail of dynamic values.
+func encodeSequence(types []Type, values []any) ([]byte, error) {
+	headLength := 0
+	for _, t := range types {
+		headLength += t.headSize()
+	}
+
+	var head []byte
+	var tail []byte
+	for i, t := range types {
+		encoded, err := encodeValue(t, values[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode value %d as %s: %w", i, t, err)
+		}
+
+		if t.IsDynamic() {
+			head = append(head, encodeUint(big.NewInt(int64(headLength+len(tail))))...)
+			tail = append(tail, encoded...)
+		} else {
+			head = append(head, encoded...)
+		}
+	}
+
+	return append(head, tail...), nil
+}
+
+func encodeValue(t Type, value any) ([]byte, error) {
+	switch t.Kind {
+	case KindUint, KindInt:
+		integer, err := toBigInt(value)
+		if err != nil {
+			return nil, err
+		}
+		return encodeInteger(t, integer)
+	case KindAddress:
+		address, err := toAddressBytes(value)
+		if err != nil {
+			return nil, err
+		}
+		return leftPad(address), nil
+	case KindBool:
+		boolean, isBool := value.(bool)
+		if !isBool {
+			return nil, fmt.Errorf("expected bool, got %T", value)
+		}
+		if boolean {
+			return encodeUint(big.NewInt(1)), nil
+		}
+		return encodeUint(big.NewInt(0)), nil
+	case KindFixedBytes:
+		fixedBytes, err := toBytes(value)
+		if err != nil {
+			return nil, err
+		} else if len(fixedBytes) > t.Size {
+			return nil, fmt.Errorf("value of %d bytes exceeds size of %s", len(fixedBytes), t)
+		}
+		return rightPad(fixedBytes), nil
+	case KindBytes:
+		dynamicBytes, err := toBytes(value)
+		if err != nil {
+			return nil, err
+		}
+		return encodeDynamicBytes(dynamicBytes), nil
+	case KindString:
+		stringValue, isString := value.(string)
+		if !isString {
+			return nil, fmt.Errorf("expected string, got %T", value)
+		}
+		return encodeDynamicBytes([]byte(stringValue)), nil
+	case KindSlice:
+		elements, err := toSlice(value)
+		if err != nil {
+			return nil, err
+		}
+
+		encodedElements, err := encodeSequence(repeatType(*t.Elem, len(elements)), elements)
+		if err != nil {
+			return nil, err
+		}
+
+		return append(encodeUint(big.NewInt(int64(len(elements)))), encodedElements...), nil
+	case KindArray:
+		elements, err := toSlice(value)
+		if err != nil {
+			return nil, err
+		} else if len(elements) != t.Size {
+			return nil, fmt.Errorf("expected %d elements, got %d", t.Size, len(elements))
+		}
+
+		return encodeSequence(repeatType(*t.Elem, len(elements)), elements)
+	case KindTuple:
+		components, err := toSlice(value)
+		if err != nil {
+			return nil, err
+		} else if len(components) != len(t.Components) {
+			return nil, fmt.Errorf("expected %d tuple components, got %d", len(t.Components), len(components))
+		}
+
+		return encodeSequence(t.Components, components)
+	}
+
+	return nil, fmt.Errorf("unsupported type kind: %d", t.Kind)
+}
+
+func encodeInteger(t Type, integer *big.Int) ([]byte, error) {
+	if t.Kind == KindUint {
+		if integer.Sign() < 0 {
+			return nil, fmt.Errorf("negative value %s cannot be encoded as %s", integer, t)
+		} else if integer.BitLen() > t.Size {
+			return nil, fmt.Errorf("value %s overflows %s", integer, t)
+		}
+		return encodeUint(integer), nil
+	}
+
+	limit := new(big.Int).Lsh(big.NewInt(1), uint(t.Size-1))
+	if integer.Cmp(limit) >= 0 || integer.Cmp(new(big.Int).Neg(limit)) < 0 {
+		return nil, fmt.Errorf("value %s overflows %s", integer, t)
+	}
+
+	if integer.Sign() >= 0 {
+		return encodeUint(integer), nil
+	}
+
+	// Two's complement across the full word
+	twosComplement := new(big.Int).Add(new(big.Int).Lsh(big.NewInt(1), wordSize*8), integer)
+	return encodeUint(twosComplement), nil
+}
+
+func encodeUint(integer *big.Int) []byte {
+	return integer.FillBytes(make([]byte, wordSize))
+}
+
+func encodeDynamicBytes(value []byte) []byte {
+	encoded := encodeUint(big.NewInt(int64(len(value))))
+	for start := 0; start < len(value); start += wordSize {
+		end := min(start+wordSize, len(value))
+		encoded = append(encoded, rightPad(value[start:end])...)
+	}
+
+	return encoded
+}
+
+func leftPad(value []byte) []byte {
+	padded := make([]byte, wordSize)
+	copy(padded[wordSize-len(value):], value)
+	return padded
+}
+
+func rightPad(value []byte) []byte {
+	padded := make([]byte, wordSize)
+	copy(padded, value)
+	return padded
+}
+
+func repeatType(t Type, count int) []Type {
+	types := make([]Type, count)
+	for i := range types {
+		types[i] = t
+	}
+	return types
+}
+
+func toBigInt(value any) (*big.Int, error) {
+	switch v := value.(type) {
+	case *big.Int:
+		if v == nil {
+			return nil, fmt.Errorf("integer value is nil")
+		}
+		return v, nil
+	case int:
+		return big.NewInt(int64(v)), nil
+	case int8:
+		return big.NewInt(int64(v)), nil
+	case int16:
+		return big.NewInt(int64(v)), nil
+	case int32:
+		return big.NewInt(int64(v)), nil
+	case int64:
+		return big.NewInt(v), nil
+	case uint:
+		return new(big.Int).SetUint64(uint64(v)), nil
+	case uint8:
+		return new(big.Int).SetUint64(uint64(v)), nil
+	case uint16:
+		return new(big.Int).SetUint64(uint64(v)), nil
+	case uint32:
+		return new(big.Int).SetUint64(uint64(v)), nil
+	case uint64:
+		return new(big.Int).SetUint64(v), nil
+	}
+
+	return nil, fmt.Errorf("expected an integer, got %T", value)
+}
+
+func toAddressBytes(value any) ([]byte, error) {
+	switch v := value.(type) {
+	case string:
+		hexAddress := strings.TrimPrefix(strings.TrimPrefix(v, "0x"), "0X")
+		if len(hexAddress) != 40 {
+			return nil, fmt.Errorf("invalid address '%s'", v)
+		}
+
+		address, err := hex.DecodeString(hexAddress)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address '%s': %w", v, err)
+		}
+
+		return address, nil
+	case []byte:
+		if len(v) != 20 {
+			return nil, fmt.Errorf("address must be 20 bytes, got %d", len(v))
+		}
+		return v, nil
+	}
+
+	return nil, fmt.Errorf("expected an address, got %T", value)
+}
+
+func toBytes(value any) ([]byte, error) {
+	switch v := value.(type) {
+	case []byte:
+		return v, nil
+	case string:
+		if !strings.HasPrefix(v, "0x") {
+			return nil, fmt.Errorf("byte strings must be 0x-prefixed hex")
+		}
+
+		decoded, err := hex.DecodeString(v[2:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid hex bytes '%s': %w", v, err)
+		}
+
+		return decoded, nil
+	}
+
+	reflected := reflect.ValueOf(value)
+	if reflected.Kind() == reflect.Array && reflected.Type().Elem().Kind() == reflect.Uint8 {
+		byteArray := make([]byte, reflected.Len())
+		reflect.Copy(reflect.ValueOf(byteArray), reflected)
+		return byteArray, nil
+	}
+
+	return nil, fmt.Errorf("expected bytes, got %T", value)
+}
+
+func toSlice(value any) ([]any, error) {
+	if anySlice, isAnySlice := value.([]any); isAnySlice {
+		return anySlice, nil
+	}
+
+	reflected := reflect.ValueOf(value)
+	if reflected.Kind() != reflect.Slice && reflected.Kind() != reflect.Array {
+		return nil, fmt.Errorf("expected a slice or array, got %T", value)
+	}
+
+	elements := make([]any, reflected.Len())
+	for i := range elements {
+		elements[i] = reflected.Index(i).Interface()
+	}
+
+	return elements, nil
+}
@@ -0,0 +1,270 @@
+package abi
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Kind is the kind of a Solidity ABI type.
+type Kind int
+
+const (
+	KindUint       Kind = iota // uint<M>
+	KindInt                    // int<M>
+	KindAddress                // address
+	KindBool                   // bool
+	KindFixedBytes             // bytes<M>
+	KindBytes                  // bytes
+	KindString                 // string
+	KindSlice                  // T[]
+	KindArray                  // T[k]
+	KindTuple                  // (T1,T2,...,Tn)
+)
+
+// wordSize is the size, in bytes, of a single ABI word.
+const wordSize = 32
+
+// Type describes a Solidity ABI type.
+type Type struct {
+	Kind       Kind
+	Size       int    // the bit size of an integer type, the byte size of a fixed bytes type, or the length of a fixed-size array
+	Elem       *Type  // the element type of an array or slice type
+	Components []Type // the component types of a tuple type
+}
+
+// ParseType parses a Solidity type signature (e.g., "uint256", "address[]", "(address,uint112[2])") into a Type.
+func ParseType(signature string) (Type, error) {
+	parsed, err := parseType(strings.TrimSpace(signature))
+	if err != nil {
+		return Type{}, fmt.Errorf("invalid type '%s': %w", signature, err)
+	}
+
+	return parsed, nil
+}
+
+// ParseTypes parses each of the given Solidity type signatures into a Type.
+func ParseTypes(signatures []string) ([]Type, error) {
+	types := make([]Type, len(signatures))
+	for i, signature := range signatures {
+		parsed, err := ParseType(signature)
+		if err != nil {
+			return nil, err
+		}
+
+		types[i] = parsed
+	}
+
+	return types, nil
+}
+
+// String renders the canonical signature of this type, as used when computing function selectors.
+func (t Type) String() string {
+	switch t.Kind {
+	case KindUint:
+		return fmt.Sprintf("uint%d", t.Size)
+	case KindInt:
+		return fmt.Sprintf("int%d", t.Size)
+	case KindAddress:
+		return "address"
+	case KindBool:
+		return "bool"
+	case KindFixedBytes:
+		return fmt.Sprintf("bytes%d", t.Size)
+	case KindBytes:
+		return "bytes"
+	case KindString:
+		return "string"
+	case KindSlice:
+		return t.Elem.String() + "[]"
+	case KindArray:
+		return fmt.Sprintf("%s[%d]", t.Elem, t.Size)
+	case KindTuple:
+		componentSignatures := make([]string, len(t.Components))
+		for i, component := range t.Components {
+			componentSignatures[i] = component.String()
+		}
+
+		return "(" + strings.Join(componentSignatures, ",") + ")"
+	}
+
+	return fmt.Sprintf("unknown(%d)", t.Kind)
+}
+
+// IsDynamic returns true if values of this type have a variable-length encoding.
+func (t Type) IsDynamic() bool {
+	switch t.Kind {
+	case KindBytes, KindString, KindSlice:
+		return true
+	case KindArray:
+		return t.Elem.IsDynamic()
+	case KindTuple:
+		for _, component := range t.Components {
+			if component.IsDynamic() {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// headSize is the number of bytes this type occupies in the head of an enclosing tuple.
+func (t Type) headSize() int {
+	if t.IsDynamic() {
+		return wordSize
+	}
+
+	switch t.Kind {
+	case KindArray:
+		return t.Size * t.Elem.headSize()
+	case KindTuple:
+		size := 0
+		for _, component := range t.Components {
+			size += component.headSize()
+		}
+		return size
+	}
+
+	return wordSize
+}
+
+func parseType(signature string) (Type, error) {
+	if signature == "" {
+		return Type{}, fmt.Errorf("type signature is empty")
+	}
+
+	// Array suffixes bind the most loosely, so peel off the last one first
+	if strings.HasSuffix(signature, "]") {
+		openIndex := strings.LastIndex(signature, "[")
+		if openIndex < 0 {
+			return Type{}, fmt.Errorf("unbalanced array brackets")
+		}
+
+		elem, err := parseType(signature[:openIndex])
+		if err != nil {
+			return Type{}, err
+		}
+
+		lengthString := signature[openIndex+1 : len(signature)-1]
+		if lengthString == "" {
+			return Type{Kind: KindSlice, Elem: &elem}, nil
+		}
+
+		length, err := strconv.Atoi(lengthString)
+		if err != nil || length <= 0 {
+			return Type{}, fmt.Errorf("invalid array length '%s'", lengthString)
+		}
+
+		return Type{Kind: KindArray, Size: length, Elem: &elem}, nil
+	}
+
+	if strings.HasPrefix(signature, "tuple(") {
+		signature = strings.TrimPrefix(signature, "tuple")
+	}
+
+	if strings.HasPrefix(signature, "(") {
+		if !strings.HasSuffix(signature, ")") {
+			return Type{}, fmt.Errorf("unbalanced tuple parentheses")
+		}
+
+		componentSignatures, err := splitTupleComponents(signature[1 : len(signature)-1])
+		if err != nil {
+			return Type{}, err
+		}
+
+		components := make([]Type, len(componentSignatures))
+		for i, componentSignature := range componentSignatures {
+			component, err := parseType(strings.TrimSpace(componentSignature))
+			if err != nil {
+				return Type{}, err
+			}
+			components[i] = component
+		}
+
+		return Type{Kind: KindTuple, Components: components}, nil
+	}
+
+	switch signature {
+	case "address":
+		return Type{Kind: KindAddress, Size: 160}, nil
+	case "bool":
+		return Type{Kind: KindBool}, nil
+	case "string":
+		return Type{Kind: KindString}, nil
+	case "bytes":
+		return Type{Kind: KindBytes}, nil
+	case "uint":
+		return Type{Kind: KindUint, Size: 256}, nil
+	case "int":
+		return Type{Kind: KindInt, Size: 256}, nil
+	}
+
+	if sizeString, isUint := strings.CutPrefix(signature, "uint"); isUint {
+		size, err := parseIntegerSize(sizeString)
+		if err != nil {
+			return Type{}, err
+		}
+		return Type{Kind: KindUint, Size: size}, nil
+	}
+
+	if sizeString, isInt := strings.CutPrefix(signature, "int"); isInt {
+		size, err := parseIntegerSize(sizeString)
+		if err != nil {
+			return Type{}, err
+		}
+		return Type{Kind: KindInt, Size: size}, nil
+	}
+
+	if sizeString, isBytes := strings.CutPrefix(signature, "bytes"); isBytes {
+		size, err := strconv.Atoi(sizeString)
+		if err != nil || size < 1 || size > wordSize {
+			return Type{}, fmt.Errorf("invalid fixed bytes size '%s'", sizeString)
+		}
+		return Type{Kind: KindFixedBytes, Size: size}, nil
+	}
+
+	return Type{}, fmt.Errorf("unsupported type")
+}
+
+func parseIntegerSize(sizeString string) (int, error) {
+	size, err := strconv.Atoi(sizeString)
+	if err != nil || size < 8 || size > 256 || size%8 != 0 {
+		return 0, fmt.Errorf("invalid integer size '%s'", sizeString)
+	}
+
+	return size, nil
+}
+
+// splitTupleComponents splits the inside of a tuple signature on its top-level commas.
+func splitTupleComponents(inner string) ([]string, error) {
+	if strings.TrimSpace(inner) == "" {
+		return nil, nil
+	}
+
+	var components []string
+	depth := 0
+	start := 0
+	for i, r := range inner {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth < 0 {
+				return nil, fmt.Errorf("unbalanced tuple parentheses")
+			}
+		case ',':
+			if depth == 0 {
+				components = append(components, inner[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	if depth != 0 {
+		return nil, fmt.Errorf("unbalanced tuple parentheses")
+	}
+
+	return append(components, inner[start:]), nil
+}
@@ -2,8 +2,11 @@ package evm
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/big"
+	"strings"
+	"sync"
 
 	"github.com/jrh3k5/cryptonabber-sync/v3/config/chain"
 	rpcconfig "github.com/jrh3k5/cryptonabber-sync/v3/config/rpc"
@@ -13,25 +16,41 @@ import (
 
 // ChainIDFetcher describes a means of retrieving a chain ID.
 type ChainIDFetcher interface {
-	// GetChainID gets the chain ID.
-	GetChainID(ctx context.Context) (*big.Int, error)
+	// GetChainID gets the ID of the chain with the given name.
+	GetChainID(ctx context.Context, chainName string) (*big.Int, error)
 }
 
 // JSONRPCChainIDFetcher is a ChainIDFetcher that uses JSON RPC calls
 // to determine it.
+//
+// If the RPC configuration for a chain has a chain ID configured, the chain ID reported by each of the chain's nodes
+// is verified to match it; nodes that cannot be reached are not verified, but at least one node must report the chain ID.
+// The chain ID of each chain is retrieved only once; subsequent requests for it are served from memory.
 type JSONRPCChainIDFetcher struct {
 	rpcConfigurationResolver rpcconfig.ConfigurationResolver
 	doer                     synchttp.Doer
+
+	chainIDsMutex sync.Mutex
+	chainIDs      map[string]*big.Int // chain name -> chain ID
 }
 
 func NewJSONRPCChainIDFetcher(rpcConfigurationResolver rpcconfig.ConfigurationResolver, doer synchttp.Doer) *JSONRPCChainIDFetcher {
 	return &JSONRPCChainIDFetcher{
 		rpcConfigurationResolver: rpcConfigurationResolver,
 		doer:                     doer,
+		chainIDs:                 make(map[string]*big.Int),
 	}
 }
 
 func (j *JSONRPCChainIDFetcher) GetChainID(ctx context.Context, chainName string) (*big.Int, error) {
+	j.chainIDsMutex.Lock()
+	cachedChainID, hasCachedChainID := j.chainIDs[chainName]
+	j.chainIDsMutex.Unlock()
+
+	if hasCachedChainID {
+		return cachedChainID, nil
+	}
+
 	rpcConfiguration, hasURL, err := j.rpcConfigurationResolver.ResolveConfiguration(ctx, chainName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to resolve RPC URL: %w", err)
@@ -39,21 +58,74 @@ func (j *JSONRPCChainIDFetcher) GetChainID(ctx context.Context, chainName string
 		return nil, fmt.Errorf("no RPC URL found for chain '%s'", chainName)
 	} else if rpcConfiguration.ChainType != chain.TypeEVM {
 		return nil, fmt.Errorf("invalid chain type for chain '%s': %s", chainName, rpcConfiguration.ChainType)
+	} else if rpcConfiguration.PrimaryURL() == "" {
+		return nil, fmt.Errorf("no RPC URL found for chain '%s'", chainName)
+	}
+
+	var chainID *big.Int
+	if rpcConfiguration.ChainID == nil {
+		chainID, err = j.fetchChainID(ctx, rpcConfiguration.PrimaryURL())
+		if err != nil {
+			return nil, fmt.Errorf("failed to get chain ID for chain '%s': %w", chainName, err)
+		}
+	} else {
+		chainID, err = j.verifyChainID(ctx, chainName, rpcConfiguration, new(big.Int).SetUint64(*rpcConfiguration.ChainID))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	j.chainIDsMutex.Lock()
+	j.chainIDs[chainName] = chainID
+	j.chainIDsMutex.Unlock()
+
+	return chainID, nil
+}
+
+// verifyChainID verifies that each reachable node of the given chain reports the given expected chain ID.
+func (j *JSONRPCChainIDFetcher) verifyChainID(ctx context.Context, chainName string, rpcConfiguration rpcconfig.Configuration, expectedChainID *big.Int) (*big.Int, error) {
+	var errs []error
+	var isVerified bool
+	for _, endpoint := range rpcConfiguration.Endpoints() {
+		chainID, err := j.fetchChainID(ctx, endpoint.URL)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
+
+			errs = append(errs, fmt.Errorf("RPC node '%s' failed: %w", endpoint.URL, err))
+			continue
+		}
+
+		if expectedChainID.Cmp(chainID) != 0 {
+			return nil, fmt.Errorf("RPC node '%s' for chain '%s' reports chain ID %v, but chain ID %v is configured; is the RPC URL correct?", endpoint.URL, chainName, chainID, expectedChainID)
+		}
+
+		isVerified = true
+	}
+
+	if !isVerified {
+		return nil, fmt.Errorf("failed to verify chain ID for chain '%s': %w", chainName, errors.Join(errs...))
 	}
 
-	rpcRequest := &rpc.Request{
+	return expectedChainID, nil
+}
+
+// fetchChainID gets the chain ID reported by the node at the given URL.
+func (j *JSONRPCChainIDFetcher) fetchChainID(ctx context.Context, nodeURL string) (*big.Int, error) {
+	rpcResponse, err := rpc.ExecuteRequest(ctx, j.doer, nodeURL, &rpc.Request{
 		ID:      1,
 		JSONRPC: "2.0",
 		Method:  "eth_chainId",
-	}
-
-	rpcResponse, err := rpc.ExecuteRequest(ctx, j.doer, rpcConfiguration.RPCURL, rpcRequest)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute eth_chainId: %w", err)
 	}
 
-	result := rpcResponse.Result
-	chainID := big.NewInt(0)
-	chainID.SetString(result[2:], 16)
+	chainID, isValid := new(big.Int).SetString(strings.TrimPrefix(rpcResponse.Result, "0x"), 16)
+	if !isValid {
+		return nil, fmt.Errorf("invalid chain ID returned by eth_chainId: '%s'", rpcResponse.Result)
+	}
+
 	return chainID, nil
 }
@@ -2,9 +2,13 @@ package evm_test
 
 import (
 	"context"
+	"errors"
 	"math/big"
 	"net/http"
 
+	"github.com/jarcoal/httpmock"
+	"github.com/jrh3k5/cryptonabber-sync/v3/config/chain"
+	rpcconfig "github.com/jrh3k5/cryptonabber-sync/v3/config/rpc"
 	"github.com/jrh3k5/cryptonabber-sync/v3/evm"
 	"github.com/jrh3k5/cryptonabber-sync/v3/http/json/rpc"
 	. "github.com/onsi/ginkgo/v2"
@@ -33,4 +37,101 @@ var _ = Describe("ChainID", func() {
 		Expect(err).ToNot(HaveOccurred(), "getting the chain ID should not fail")
 		Expect(retrievedChainID.Int64()).To(Equal(chainID), "the correct chain ID should be retrieved")
 	})
+
+	It("retrieves the chain ID only once per chain", func() {
+		var callCount int
+		evmNode.RegisterRPCMethodCall("eth_chainId", func(methodName string) (rpc.MockEVMNodeRPCResult, *rpc.MockEVMNodeRPCError, error) {
+			callCount++
+			return rpc.NewMockEVMNodeRPCNumericResult(big.NewInt(8453)), nil, nil
+		})
+
+		for range 3 {
+			retrievedChainID, err := fetcher.GetChainID(ctx, chainName)
+			Expect(err).ToNot(HaveOccurred(), "getting the chain ID should not fail")
+			Expect(retrievedChainID.Int64()).To(Equal(int64(8453)), "the correct chain ID should be retrieved")
+		}
+
+		Expect(callCount).To(Equal(1), "the chain ID should only be requested once")
+	})
+
+	When("a chain ID is configured", func() {
+		configuredChainID := uint64(8453)
+
+		BeforeEach(func() {
+			fetcher = evm.NewJSONRPCChainIDFetcher(rpcconfig.NewDefaultConfigurationResolver([]rpcconfig.Configuration{
+				{
+					RPCURL:    evmNode.URL(),
+					ChainName: chainName,
+					ChainType: chain.TypeEVM,
+					ChainID:   &configuredChainID,
+				},
+			}), http.DefaultClient)
+		})
+
+		It("returns the chain ID if the node reports it", func() {
+			evmNode.RegisterRPCMethodCall("eth_chainId", func(methodName string) (rpc.MockEVMNodeRPCResult, *rpc.MockEVMNodeRPCError, error) {
+				return rpc.NewMockEVMNodeRPCNumericResult(big.NewInt(8453)), nil, nil
+			})
+
+			retrievedChainID, err := fetcher.GetChainID(ctx, chainName)
+			Expect(err).ToNot(HaveOccurred(), "getting the chain ID should not fail")
+			Expect(retrievedChainID.Int64()).To(Equal(int64(8453)), "the correct chain ID should be retrieved")
+		})
+
+		It("fails if the node reports a different chain ID", func() {
+			evmNode.RegisterRPCMethodCall("eth_chainId", func(methodName string) (rpc.MockEVMNodeRPCResult, *rpc.MockEVMNodeRPCError, error) {
+				return rpc.NewMockEVMNodeRPCNumericResult(big.NewInt(1)), nil, nil
+			})
+
+			_, err := fetcher.GetChainID(ctx, chainName)
+			Expect(err).To(HaveOccurred(), "the mismatched chain ID should fail")
+			Expect(err.Error()).To(ContainSubstring("8453"), "the configured chain ID should be described")
+		})
+
+		When("the chain has failover nodes", func() {
+			const (
+				otherChainURL  = "http://other-chain.localhost/rpc"
+				unreachableURL = "http://unreachable.localhost/rpc"
+			)
+
+			BeforeEach(func() {
+				evmNode.RegisterRPCMethodCall("eth_chainId", func(methodName string) (rpc.MockEVMNodeRPCResult, *rpc.MockEVMNodeRPCError, error) {
+					return rpc.NewMockEVMNodeRPCNumericResult(big.NewInt(8453)), nil, nil
+				})
+
+				httpmock.RegisterResponder(http.MethodPost, unreachableURL, httpmock.NewErrorResponder(errors.New("connection refused")))
+				httpmock.RegisterResponder(http.MethodPost, otherChainURL, func(_ *http.Request) (*http.Response, error) {
+					return httpmock.NewJsonResponse(http.StatusOK, &rpc.Response{
+						ID:      "1",
+						JSONRPC: "2.0",
+						Result:  "0x1",
+					})
+				})
+			})
+
+			newFetcher := func(failoverURL string) *evm.JSONRPCChainIDFetcher {
+				return evm.NewJSONRPCChainIDFetcher(rpcconfig.NewDefaultConfigurationResolver([]rpcconfig.Configuration{
+					{
+						RPCURL:    evmNode.URL(),
+						RPCURLs:   []rpcconfig.Endpoint{{URL: failoverURL}},
+						ChainName: chainName,
+						ChainType: chain.TypeEVM,
+						ChainID:   &configuredChainID,
+					},
+				}), http.DefaultClient)
+			}
+
+			It("fails if a failover node reports a different chain ID", func() {
+				_, err := newFetcher(otherChainURL).GetChainID(ctx, chainName)
+				Expect(err).To(HaveOccurred(), "the mismatched chain ID of the failover node should fail")
+				Expect(err.Error()).To(ContainSubstring(otherChainURL), "the mismatched node should be described")
+			})
+
+			It("does not fail if a failover node cannot be reached", func() {
+				retrievedChainID, err := newFetcher(unreachableURL).GetChainID(ctx, chainName)
+				Expect(err).ToNot(HaveOccurred(), "an unreachable failover node should not fail the verification")
+				Expect(retrievedChainID.Int64()).To(Equal(int64(8453)), "the correct chain ID should be retrieved")
+			})
+		})
+	})
 })
@@ -0,0 +1,126 @@
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	synchttp "github.com/jrh3k5/cryptonabber-sync/v3/http"
+)
+
+// errBatchNotSupported is returned by executeBatch when the node rejects the batch as a whole, as nodes that do not support batching do.
+var errBatchNotSupported = errors.New("node does not support batch requests")
+
+// BatchResult is the outcome of a single request within a batch.
+type BatchResult struct {
+	Response *Response // the response to the request; nil if Err is set
+	Err      error     // the error for the request; this is an RPCCallError if the node returned an error for the request
+}
+
+// ExecuteBatchRequest executes the given requests as a single JSON-RPC 2.0 batch.
+// Each request is assigned its own ID for the batch (any ID already on the request is ignored), and responses are matched
+// to their requests by ID regardless of the order in which the node returns them. The returned results are in the same order as the given requests.
+//
+// If the node does not support batching or omits responses for some of the requests, the affected requests are executed individually.
+// Any other failure of the batch (e.g., a rate limit or a server error) is returned, rather than retried as a request per request of the batch.
+// A single request is always executed on its own.
+func ExecuteBatchRequest(ctx context.Context, doer synchttp.Doer, requestURL string, rpcRequests []*Request) ([]*BatchResult, error) {
+	if len(rpcRequests) == 0 {
+		return nil, nil
+	}
+
+	if len(rpcRequests) == 1 {
+		response, err := ExecuteRequest(ctx, doer, requestURL, rpcRequests[0])
+		return []*BatchResult{{Response: response, Err: err}}, nil
+	}
+
+	batchRequests := make([]*Request, len(rpcRequests))
+	for i, rpcRequest := range rpcRequests {
+		batchRequest := *rpcRequest
+		batchRequest.ID = int64(i + 1)
+		batchRequests[i] = &batchRequest
+	}
+
+	results := make([]*BatchResult, len(rpcRequests))
+
+	responses, batchErr := executeBatch(ctx, doer, requestURL, batchRequests)
+	if batchErr != nil && !errors.Is(batchErr, errBatchNotSupported) {
+		return nil, fmt.Errorf("failed to execute batch request: %w", batchErr)
+	}
+
+	if batchErr == nil {
+		for _, response := range responses {
+			responseID, err := strconv.ParseInt(response.ID.String(), 10, 64)
+			if err != nil || responseID < 1 || responseID > int64(len(results)) || results[responseID-1] != nil {
+				// Don't trust a response that can't be unambiguously matched to a request
+				continue
+			}
+
+			if response.Error.Code != 0 {
+				results[responseID-1] = &BatchResult{Err: NewRPCCallError(response.Error.Code, response.Error.Message)}
+			} else {
+				results[responseID-1] = &BatchResult{Response: response}
+			}
+		}
+	}
+
+	// Fall back to sequential requests for anything the batch did not answer
+	for i, result := range results {
+		if result != nil {
+			continue
+		}
+
+		response, err := ExecuteRequest(ctx, doer, requestURL, rpcRequests[i])
+		results[i] = &BatchResult{Response: response, Err: err}
+	}
+
+	return results, nil
+}
+
+func executeBatch(ctx context.Context, doer synchttp.Doer, requestURL string, rpcRequests []*Request) ([]*Response, error) {
+	requestBodyBytes, err := json.Marshal(rpcRequests)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal JSON request body: %w", err)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, requestURL, bytes.NewBuffer(requestBodyBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+
+	response, err := doer.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer func() {
+		_ = response.Body.Close()
+	}()
+
+	if response.StatusCode != http.StatusOK {
+		statusErr := synchttp.BuildUnexpectedStatusErr(response)
+		return nil, statusErr
+	}
+
+	responseBytes, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	// Nodes that do not support batching typically respond with a single error object rather than an array
+	var results []*Response
+	if unmarshalErr := json.Unmarshal(responseBytes, &results); unmarshalErr != nil {
+		var singleResponse *Response
+		if json.Unmarshal(responseBytes, &singleResponse) == nil && singleResponse != nil {
+			return nil, fmt.Errorf("%w: %s", errBatchNotSupported, singleResponse.Error.Message)
+		}
+
+		return nil, fmt.Errorf("failed to unmarshal batch response body: %w", unmarshalErr)
+	}
+
+	return results, nil
+}
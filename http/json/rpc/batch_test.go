@@ -0,0 +1,138 @@
+package rpc_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net/http"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/jrh3k5/cryptonabber-sync/v3/http/json/rpc"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ExecuteBatchRequest", func() {
+	var ctx context.Context
+
+	tokenAddresses := []string{
+		"0x1e4b7A6b903680eab0c5dAbcb8fD429cD2a9598c",
+		"0x2A8e1E676Ec238d8A992307B495b45B3fEAa5e86",
+		"0x3c3a81e81dc49A522A592e7622A7E711c06bf354",
+	}
+
+	buildRequests := func(addresses []string) []*rpc.Request {
+		rpcRequests := make([]*rpc.Request, len(addresses))
+		for i, address := range addresses {
+			callData, err := rpc.EncodeEthCallData("decimals")
+			Expect(err).ToNot(HaveOccurred(), "encoding the call data should not fail")
+
+			rpcRequests[i] = &rpc.Request{
+				ID:      1,
+				JSONRPC: "2.0",
+				Method:  "eth_call",
+				Params: []any{
+					map[string]string{
+						"to":   address,
+						"data": callData,
+					},
+					"latest",
+				},
+			}
+		}
+
+		return rpcRequests
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		for i, tokenAddress := range tokenAddresses {
+			tokenDecimals := int64(6 + i)
+			evmNode.RegisterETHCallCall("decimals", tokenAddress, nil, func(_ string, _ []string) (rpc.MockEVMNodeRPCResult, *rpc.MockEVMNodeRPCError, error) {
+				return rpc.NewMockEVMNodeRPCNumericResult(big.NewInt(tokenDecimals)), nil, nil
+			})
+		}
+	})
+
+	It("executes the requests in a single HTTP request", func() {
+		requestCountBefore := evmNode.RequestCount()
+
+		results, err := rpc.ExecuteBatchRequest(ctx, http.DefaultClient, evmNode.URL(), buildRequests(tokenAddresses))
+		Expect(err).ToNot(HaveOccurred(), "executing the batch should not fail")
+		Expect(results).To(HaveLen(len(tokenAddresses)), "there should be a result for each request")
+
+		for i, result := range results {
+			Expect(result.Err).ToNot(HaveOccurred(), "request %d should not fail", i)
+			Expect(result.Response.Result).To(Equal(rpc.NewMockEVMNodeRPCNumericResult(big.NewInt(int64(6+i))).ReturnValue()), "request %d should be matched to its own response", i)
+		}
+
+		Expect(evmNode.RequestCount()-requestCountBefore).To(Equal(1), "all of the requests should have been sent together")
+	})
+
+	It("does not modify the given requests", func() {
+		rpcRequests := buildRequests(tokenAddresses)
+
+		_, err := rpc.ExecuteBatchRequest(ctx, http.DefaultClient, evmNode.URL(), rpcRequests)
+		Expect(err).ToNot(HaveOccurred(), "executing the batch should not fail")
+
+		for i, rpcRequest := range rpcRequests {
+			Expect(rpcRequest.ID).To(Equal(int64(1)), "the ID of request %d should not be changed", i)
+		}
+	})
+
+	When("a request within the batch fails", func() {
+		It("fails only that request", func() {
+			revertingAddress := "0x4b1D0a5e0c4f69a2C03aB30b5e8c7A8e7D5F2b19"
+			evmNode.RegisterContractExistence(revertingAddress)
+
+			results, err := rpc.ExecuteBatchRequest(ctx, http.DefaultClient, evmNode.URL(), buildRequests(append([]string{revertingAddress}, tokenAddresses...)))
+			Expect(err).ToNot(HaveOccurred(), "executing the batch should not fail")
+
+			var rpcCallErr *rpc.RPCCallError
+			Expect(errors.As(results[0].Err, &rpcCallErr)).To(BeTrue(), "the failed request should surface the node's error")
+			Expect(rpcCallErr.Code).To(Equal(int64(-32000)), "the node's error code should be surfaced")
+
+			for i, result := range results[1:] {
+				Expect(result.Err).ToNot(HaveOccurred(), "request %d should not fail", i+1)
+				Expect(result.Response.Result).To(Equal(rpc.NewMockEVMNodeRPCNumericResult(big.NewInt(int64(6+i))).ReturnValue()), "request %d should receive its result", i+1)
+			}
+		})
+	})
+
+	When("the node rejects batch requests", func() {
+		BeforeEach(func() {
+			evmNode.SetRejectBatchRequests(true)
+			DeferCleanup(func() {
+				evmNode.SetRejectBatchRequests(false)
+			})
+		})
+
+		It("executes the requests sequentially", func() {
+			requestCountBefore := evmNode.RequestCount()
+
+			results, err := rpc.ExecuteBatchRequest(ctx, http.DefaultClient, evmNode.URL(), buildRequests(tokenAddresses))
+			Expect(err).ToNot(HaveOccurred(), "executing the batch should not fail")
+
+			for i, result := range results {
+				Expect(result.Err).ToNot(HaveOccurred(), "request %d should not fail", i)
+				Expect(result.Response.Result).To(Equal(rpc.NewMockEVMNodeRPCNumericResult(big.NewInt(int64(6+i))).ReturnValue()), "request %d should receive its result", i)
+			}
+
+			Expect(evmNode.RequestCount()-requestCountBefore).To(Equal(1+len(tokenAddresses)), "each request should have been retried on its own after the rejected batch")
+		})
+	})
+
+	When("the node fails the batch for another reason", func() {
+		It("returns the error without retrying each request", func() {
+			overloadedURL := "http://overloaded.batch.node"
+			httpmock.RegisterResponder(http.MethodPost, overloadedURL, httpmock.NewStringResponder(http.StatusTooManyRequests, "slow down"))
+			callCountKey := http.MethodPost + " " + overloadedURL
+			callsBefore := httpmock.GetCallCountInfo()[callCountKey]
+
+			_, err := rpc.ExecuteBatchRequest(ctx, http.DefaultClient, overloadedURL, buildRequests(tokenAddresses))
+			Expect(err).To(HaveOccurred(), "the failure of the batch should be returned")
+			Expect(httpmock.GetCallCountInfo()[callCountKey]-callsBefore).To(Equal(1), "the requests should not be retried individually against the node")
+		})
+	})
+})
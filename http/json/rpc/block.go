@@ -0,0 +1,262 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	rpcconfig "github.com/jrh3k5/cryptonabber-sync/v3/config/rpc"
+	synchttp "github.com/jrh3k5/cryptonabber-sync/v3/http"
+)
+
+// BlockResolver describes a means of resolving the block at which reads against a node are to be made.
+type BlockResolver interface {
+	// ResolveBlock resolves the number of the block at which reads against the node at the given URL are to be made.
+	// A nil block number indicates that reads are to be made against the latest block.
+	ResolveBlock(ctx context.Context, nodeURL string) (*big.Int, error)
+}
+
+// BlockTag formats the given block number as a block parameter for a JSON-RPC request.
+// A nil block number is formatted as "latest".
+func BlockTag(blockNumber *big.Int) string {
+	if blockNumber == nil {
+		return "latest"
+	}
+
+	return "0x" + blockNumber.Text(16)
+}
+
+// LatestBlockResolver is a BlockResolver that has all reads made against the latest block.
+type LatestBlockResolver struct{}
+
+// NewLatestBlockResolver builds a LatestBlockResolver.
+func NewLatestBlockResolver() *LatestBlockResolver {
+	return &LatestBlockResolver{}
+}
+
+func (*LatestBlockResolver) ResolveBlock(_ context.Context, _ string) (*big.Int, error) {
+	return nil, nil
+}
+
+// PinnedBlockResolver is a BlockResolver that resolves a single block for each node the first time it is asked to,
+// so that all reads made against a node are made against the same block.
+//
+// The block resolved for a node is the block configured for the chain whose primary URL is the node's URL, if any;
+// the latest block as of the time of the configured timestamp, if any; or, otherwise, the latest block.
+//
+// As reads against a chain's primary URL may be failed over to the chain's other nodes, the latest block of a chain
+// is the lowest of the latest blocks reported by its reachable nodes, so that a lagging node can still serve reads against the resolved block.
+type PinnedBlockResolver struct {
+	doer synchttp.Doer
+	pins map[string]blockPin // primary URL -> configured block
+
+	blocksMutex sync.Mutex
+	blocks      map[string]*pinnedBlock // node URL -> resolved block
+}
+
+// NewPinnedBlockResolver builds a PinnedBlockResolver that honors the blocks configured in the given RPC configurations.
+func NewPinnedBlockResolver(doer synchttp.Doer, configurations []rpcconfig.Configuration) *PinnedBlockResolver {
+	pins := make(map[string]blockPin)
+	for _, configuration := range configurations {
+		endpoints := configuration.Endpoints()
+		endpointURLs := make([]string, len(endpoints))
+		for i, endpoint := range endpoints {
+			endpointURLs[i] = endpoint.URL
+		}
+
+		pin := blockPin{
+			timestamp:    configuration.BlockTimestamp,
+			endpointURLs: endpointURLs,
+		}
+
+		if configuration.BlockNumber != nil {
+			pin.blockNumber = new(big.Int).SetUint64(*configuration.BlockNumber)
+		}
+
+		pins[configuration.PrimaryURL()] = pin
+	}
+
+	return &PinnedBlockResolver{
+		doer:   doer,
+		pins:   pins,
+		blocks: make(map[string]*pinnedBlock),
+	}
+}
+
+func (p *PinnedBlockResolver) ResolveBlock(ctx context.Context, nodeURL string) (*big.Int, error) {
+	p.blocksMutex.Lock()
+	block, hasBlock := p.blocks[nodeURL]
+	if !hasBlock {
+		block = &pinnedBlock{}
+		p.blocks[nodeURL] = block
+	}
+	p.blocksMutex.Unlock()
+
+	block.resolveOnce.Do(func() {
+		block.blockNumber, block.err = p.resolveBlock(context.WithoutCancel(ctx), nodeURL)
+	})
+
+	return block.blockNumber, block.err
+}
+
+func (p *PinnedBlockResolver) resolveBlock(ctx context.Context, nodeURL string) (*big.Int, error) {
+	pin := p.pins[nodeURL]
+	if pin.blockNumber != nil && pin.timestamp != nil {
+		return nil, fmt.Errorf("only one of a block number or a block timestamp may be configured for node '%s'", nodeURL)
+	}
+
+	if pin.blockNumber != nil {
+		return pin.blockNumber, nil
+	}
+
+	latestBlockNumber, err := p.getLowestLatestBlockNumber(ctx, nodeURL, pin.endpointURLs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest block number: %w", err)
+	}
+
+	if pin.timestamp == nil {
+		return latestBlockNumber, nil
+	}
+
+	blockNumber, err := p.findBlockAtTimestamp(ctx, nodeURL, latestBlockNumber, *pin.timestamp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find block as of %v: %w", pin.timestamp, err)
+	}
+
+	return blockNumber, nil
+}
+
+// findBlockAtTimestamp finds the number of the last block at or before the given timestamp by binary search.
+func (p *PinnedBlockResolver) findBlockAtTimestamp(ctx context.Context, nodeURL string, latestBlockNumber *big.Int, timestamp time.Time) (*big.Int, error) {
+	targetTimestamp := timestamp.Unix()
+
+	latestTimestamp, err := p.getBlockTimestamp(ctx, nodeURL, latestBlockNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	if latestTimestamp <= targetTimestamp {
+		return latestBlockNumber, nil
+	}
+
+	low := big.NewInt(0)
+	lowTimestamp, err := p.getBlockTimestamp(ctx, nodeURL, low)
+	if err != nil {
+		return nil, err
+	}
+
+	if lowTimestamp > targetTimestamp {
+		return nil, fmt.Errorf("timestamp precedes the first block of the chain")
+	}
+
+	// Invariant: the block at low is at or before the timestamp, and the block at high is after it
+	high := new(big.Int).Set(latestBlockNumber)
+	one := big.NewInt(1)
+	for new(big.Int).Sub(high, low).Cmp(one) > 0 {
+		middle := new(big.Int).Add(low, high)
+		middle.Rsh(middle, 1)
+
+		middleTimestamp, err := p.getBlockTimestamp(ctx, nodeURL, middle)
+		if err != nil {
+			return nil, err
+		}
+
+		if middleTimestamp <= targetTimestamp {
+			low = middle
+		} else {
+			high = middle
+		}
+	}
+
+	return low, nil
+}
+
+// getLowestLatestBlockNumber gets the lowest latest block number reported by the given node and the given endpoints of its chain.
+// Endpoints that cannot be reached are ignored, so long as at least one node reports its latest block.
+func (p *PinnedBlockResolver) getLowestLatestBlockNumber(ctx context.Context, nodeURL string, endpointURLs []string) (*big.Int, error) {
+	if len(endpointURLs) <= 1 {
+		return p.getLatestBlockNumber(ctx, nodeURL)
+	}
+
+	var lowestBlockNumber *big.Int
+	var errs []error
+	for _, endpointURL := range endpointURLs {
+		blockNumber, err := p.getLatestBlockNumber(ctx, endpointURL)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
+
+			errs = append(errs, fmt.Errorf("RPC node '%s' failed: %w", endpointURL, err))
+			continue
+		}
+
+		if lowestBlockNumber == nil || blockNumber.Cmp(lowestBlockNumber) < 0 {
+			lowestBlockNumber = blockNumber
+		}
+	}
+
+	if lowestBlockNumber == nil {
+		return nil, errors.Join(errs...)
+	}
+
+	return lowestBlockNumber, nil
+}
+
+func (p *PinnedBlockResolver) getLatestBlockNumber(ctx context.Context, nodeURL string) (*big.Int, error) {
+	rpcResponse, err := ExecuteRequest(ctx, p.doer, nodeURL, &Request{
+		ID:      1,
+		JSONRPC: "2.0",
+		Method:  "eth_blockNumber",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute eth_blockNumber: %w", err)
+	}
+
+	blockNumber, isValid := new(big.Int).SetString(strings.TrimPrefix(rpcResponse.Result, "0x"), 16)
+	if !isValid {
+		return nil, fmt.Errorf("invalid block number returned by eth_blockNumber: '%s'", rpcResponse.Result)
+	}
+
+	return blockNumber, nil
+}
+
+func (p *PinnedBlockResolver) getBlockTimestamp(ctx context.Context, nodeURL string, blockNumber *big.Int) (int64, error) {
+	var block struct {
+		Timestamp string `json:"timestamp"`
+	}
+
+	err := ExecuteObjectRequest(ctx, p.doer, nodeURL, &Request{
+		ID:      1,
+		JSONRPC: "2.0",
+		Method:  "eth_getBlockByNumber",
+		Params:  []any{BlockTag(blockNumber), false},
+	}, &block)
+	if err != nil {
+		return 0, fmt.Errorf("failed to execute eth_getBlockByNumber for block %v: %w", blockNumber, err)
+	}
+
+	timestamp, isValid := new(big.Int).SetString(strings.TrimPrefix(block.Timestamp, "0x"), 16)
+	if !isValid || !timestamp.IsInt64() {
+		return 0, fmt.Errorf("invalid timestamp returned for block %v: '%s'", blockNumber, block.Timestamp)
+	}
+
+	return timestamp.Int64(), nil
+}
+
+// blockPin describes the block configured to be read from for a chain.
+type blockPin struct {
+	blockNumber  *big.Int
+	timestamp    *time.Time
+	endpointURLs []string // in order of priority
+}
+
+type pinnedBlock struct {
+	resolveOnce sync.Once
+	blockNumber *big.Int
+	err         error
+}
@@ -0,0 +1,251 @@
+package rpc_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	rpcconfig "github.com/jrh3k5/cryptonabber-sync/v3/config/rpc"
+	"github.com/jrh3k5/cryptonabber-sync/v3/http/json/rpc"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("BlockTag", func() {
+	It("formats a block number as hex", func() {
+		Expect(rpc.BlockTag(big.NewInt(19000000))).To(Equal("0x121eac0"), "the block number should be hex-encoded")
+	})
+
+	It("formats a nil block number as the latest block", func() {
+		Expect(rpc.BlockTag(nil)).To(Equal("latest"), "a nil block should be the latest block")
+	})
+})
+
+var _ = Describe("PinnedBlockResolver", func() {
+	const (
+		latestBlockNumber = 100
+		genesisTimestamp  = 1700000000
+		blockTime         = 12
+	)
+
+	var ctx context.Context
+	var blockNumberCallCount int
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		blockNumberCallCount = 0
+		evmNode.RegisterRPCMethodCall("eth_blockNumber", func(_ string) (rpc.MockEVMNodeRPCResult, *rpc.MockEVMNodeRPCError, error) {
+			blockNumberCallCount++
+			return rpc.NewMockEVMNodeRPCNumericResult(big.NewInt(latestBlockNumber)), nil, nil
+		})
+
+		evmNode.RegisterRPCMethodParamsCall("eth_getBlockByNumber", func(params []any) (any, *rpc.MockEVMNodeRPCError, error) {
+			blockNumber, isValid := new(big.Int).SetString(strings.TrimPrefix(params[0].(string), "0x"), 16)
+			if !isValid {
+				return nil, nil, fmt.Errorf("invalid block parameter: %v", params[0])
+			}
+
+			if blockNumber.Int64() > latestBlockNumber {
+				return nil, nil, nil
+			}
+
+			return map[string]any{
+				"number":    rpc.BlockTag(blockNumber),
+				"timestamp": fmt.Sprintf("0x%x", genesisTimestamp+blockTime*blockNumber.Int64()),
+			}, nil, nil
+		})
+	})
+
+	It("resolves the latest block once and reuses it", func() {
+		resolver := rpc.NewPinnedBlockResolver(http.DefaultClient, nil)
+
+		var waitGroup sync.WaitGroup
+		blockNumbers := make([]*big.Int, 5)
+		for i := range blockNumbers {
+			waitGroup.Add(1)
+			go func() {
+				defer waitGroup.Done()
+
+				blockNumber, err := resolver.ResolveBlock(ctx, evmNode.URL())
+				Expect(err).ToNot(HaveOccurred(), "resolving the block should not fail")
+				blockNumbers[i] = blockNumber
+			}()
+		}
+		waitGroup.Wait()
+
+		for i, blockNumber := range blockNumbers {
+			Expect(blockNumber.Int64()).To(Equal(int64(latestBlockNumber)), "resolution %d should be the latest block", i)
+		}
+		Expect(blockNumberCallCount).To(Equal(1), "the latest block should only be requested once")
+	})
+
+	When("the chain has a node that lags behind the primary node", func() {
+		const (
+			laggingURL         = "http://lagging.localhost/rpc"
+			unreachableURL     = "http://unreachable.localhost/rpc"
+			laggingBlockNumber = 80
+		)
+
+		BeforeEach(func() {
+			httpmock.RegisterResponder(http.MethodPost, unreachableURL, httpmock.NewErrorResponder(errors.New("connection refused")))
+			httpmock.RegisterResponder(http.MethodPost, laggingURL, func(request *http.Request) (*http.Response, error) {
+				var rpcRequest rpc.Request
+				if err := json.NewDecoder(request.Body).Decode(&rpcRequest); err != nil {
+					return nil, err
+				}
+
+				if rpcRequest.Method != "eth_blockNumber" {
+					return nil, fmt.Errorf("unexpected method: %s", rpcRequest.Method)
+				}
+
+				return httpmock.NewJsonResponse(http.StatusOK, &rpc.Response{
+					ID:      "1",
+					JSONRPC: "2.0",
+					Result:  rpc.BlockTag(big.NewInt(laggingBlockNumber)),
+				})
+			})
+		})
+
+		It("resolves the lowest latest block of the chain's nodes", func() {
+			configurations := []rpcconfig.Configuration{
+				{
+					ChainName: "ethereum",
+					RPCURL:    evmNode.URL(),
+					RPCURLs:   []rpcconfig.Endpoint{{URL: laggingURL}},
+				},
+			}
+			resolver := rpc.NewPinnedBlockResolver(rpc.NewFailoverDoer(http.DefaultClient, configurations), configurations)
+
+			blockNumber, err := resolver.ResolveBlock(ctx, evmNode.URL())
+			Expect(err).ToNot(HaveOccurred(), "resolving the block should not fail")
+			Expect(blockNumber.Int64()).To(Equal(int64(laggingBlockNumber)), "the block should be one that the lagging node can serve")
+		})
+
+		It("ignores nodes that cannot be reached", func() {
+			configurations := []rpcconfig.Configuration{
+				{
+					ChainName: "ethereum",
+					RPCURL:    evmNode.URL(),
+					RPCURLs:   []rpcconfig.Endpoint{{URL: unreachableURL}},
+				},
+			}
+			resolver := rpc.NewPinnedBlockResolver(rpc.NewFailoverDoer(http.DefaultClient, configurations), configurations)
+
+			blockNumber, err := resolver.ResolveBlock(ctx, evmNode.URL())
+			Expect(err).ToNot(HaveOccurred(), "resolving the block should not fail")
+			Expect(blockNumber.Int64()).To(Equal(int64(latestBlockNumber)), "the latest block of the reachable node should be used")
+		})
+	})
+
+	It("uses a configured block number", func() {
+		configuredBlockNumber := uint64(42)
+		resolver := rpc.NewPinnedBlockResolver(http.DefaultClient, []rpcconfig.Configuration{
+			{
+				RPCURL:      evmNode.URL(),
+				BlockNumber: &configuredBlockNumber,
+			},
+		})
+
+		blockNumber, err := resolver.ResolveBlock(ctx, evmNode.URL())
+		Expect(err).ToNot(HaveOccurred(), "resolving the block should not fail")
+		Expect(blockNumber.Int64()).To(Equal(int64(42)), "the configured block should be used")
+		Expect(blockNumberCallCount).To(BeZero(), "the latest block should not be requested")
+	})
+
+	It("resolves the last block at or before a configured timestamp", func() {
+		timestamp := time.Unix(genesisTimestamp+blockTime*42+blockTime/2, 0)
+		resolver := rpc.NewPinnedBlockResolver(http.DefaultClient, []rpcconfig.Configuration{
+			{
+				RPCURL:         evmNode.URL(),
+				BlockTimestamp: &timestamp,
+			},
+		})
+
+		blockNumber, err := resolver.ResolveBlock(ctx, evmNode.URL())
+		Expect(err).ToNot(HaveOccurred(), "resolving the block should not fail")
+		Expect(blockNumber.Int64()).To(Equal(int64(42)), "the block at or before the timestamp should be used")
+	})
+
+	It("resolves the exact block of a configured timestamp", func() {
+		timestamp := time.Unix(genesisTimestamp+blockTime*43, 0)
+		resolver := rpc.NewPinnedBlockResolver(http.DefaultClient, []rpcconfig.Configuration{
+			{
+				RPCURL:         evmNode.URL(),
+				BlockTimestamp: &timestamp,
+			},
+		})
+
+		blockNumber, err := resolver.ResolveBlock(ctx, evmNode.URL())
+		Expect(err).ToNot(HaveOccurred(), "resolving the block should not fail")
+		Expect(blockNumber.Int64()).To(Equal(int64(43)), "the block with the timestamp should be used")
+	})
+
+	It("resolves the latest block for a timestamp in the future", func() {
+		timestamp := time.Unix(genesisTimestamp+blockTime*latestBlockNumber*2, 0)
+		resolver := rpc.NewPinnedBlockResolver(http.DefaultClient, []rpcconfig.Configuration{
+			{
+				RPCURL:         evmNode.URL(),
+				BlockTimestamp: &timestamp,
+			},
+		})
+
+		blockNumber, err := resolver.ResolveBlock(ctx, evmNode.URL())
+		Expect(err).ToNot(HaveOccurred(), "resolving the block should not fail")
+		Expect(blockNumber.Int64()).To(Equal(int64(latestBlockNumber)), "the latest block should be used")
+	})
+
+	It("fails for a timestamp before the first block", func() {
+		timestamp := time.Unix(genesisTimestamp-1, 0)
+		resolver := rpc.NewPinnedBlockResolver(http.DefaultClient, []rpcconfig.Configuration{
+			{
+				RPCURL:         evmNode.URL(),
+				BlockTimestamp: &timestamp,
+			},
+		})
+
+		_, err := resolver.ResolveBlock(ctx, evmNode.URL())
+		Expect(err).To(HaveOccurred(), "resolving the block should fail")
+	})
+
+	It("fails if both a block number and a timestamp are configured", func() {
+		configuredBlockNumber := uint64(42)
+		timestamp := time.Unix(genesisTimestamp, 0)
+		resolver := rpc.NewPinnedBlockResolver(http.DefaultClient, []rpcconfig.Configuration{
+			{
+				RPCURL:         evmNode.URL(),
+				BlockNumber:    &configuredBlockNumber,
+				BlockTimestamp: &timestamp,
+			},
+		})
+
+		_, err := resolver.ResolveBlock(ctx, evmNode.URL())
+		Expect(err).To(HaveOccurred(), "resolving the block should fail")
+	})
+
+	It("has eth_calls made against the resolved block", func() {
+		configuredBlockNumber := uint64(42)
+		resolver := rpc.NewPinnedBlockResolver(http.DefaultClient, []rpcconfig.Configuration{
+			{
+				RPCURL:      evmNode.URL(),
+				BlockNumber: &configuredBlockNumber,
+			},
+		})
+
+		tokenAddress := "0x5f1f5e4B7a3c3B0E5a6B6c9a8D9f7A2e1C0b3D4e"
+		evmNode.RegisterETHCallCall("decimals", tokenAddress, nil, func(_ string, _ []string) (rpc.MockEVMNodeRPCResult, *rpc.MockEVMNodeRPCError, error) {
+			return rpc.NewMockEVMNodeRPCNumericResult(big.NewInt(18)), nil, nil
+		})
+
+		_, err := rpc.NewDirectEthCaller(http.DefaultClient, resolver).EthCall(ctx, evmNode.URL(), "decimals", tokenAddress)
+		Expect(err).ToNot(HaveOccurred(), "the call should not fail")
+		Expect(evmNode.LastETHCallBlockTag()).To(Equal("0x2a"), "the call should be made against the resolved block")
+	})
+})
@@ -0,0 +1,38 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+
+	synchttp "github.com/jrh3k5/cryptonabber-sync/v3/http"
+)
+
+// EthCaller describes a means of executing eth_call invocations against a node.
+type EthCaller interface {
+	// EthCall calls the given method with the given arguments against the given contract address using the node at the given URL.
+	// The raw, hex-encoded result is returned; it can be decoded using abi.DecodeHex.
+	EthCall(ctx context.Context, nodeURL string, methodName string, contractAddress string, args ...*EthCallArgument) (string, error)
+}
+
+// DirectEthCaller is an EthCaller that executes each call as its own JSON-RPC request.
+type DirectEthCaller struct {
+	doer          synchttp.Doer
+	blockResolver BlockResolver
+}
+
+// NewDirectEthCaller builds a DirectEthCaller that makes calls against the blocks resolved by the given BlockResolver.
+func NewDirectEthCaller(doer synchttp.Doer, blockResolver BlockResolver) *DirectEthCaller {
+	return &DirectEthCaller{
+		doer:          doer,
+		blockResolver: blockResolver,
+	}
+}
+
+func (d *DirectEthCaller) EthCall(ctx context.Context, nodeURL string, methodName string, contractAddress string, args ...*EthCallArgument) (string, error) {
+	blockNumber, err := d.blockResolver.ResolveBlock(ctx, nodeURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve block for node: %w", err)
+	}
+
+	return ExecuteEthCall(ctx, d.doer, nodeURL, blockNumber, methodName, contractAddress, args...)
+}
@@ -0,0 +1,224 @@
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	rpcconfig "github.com/jrh3k5/cryptonabber-sync/v3/config/rpc"
+	synchttp "github.com/jrh3k5/cryptonabber-sync/v3/http"
+)
+
+// FailoverDoer is a synchttp.Doer that fails requests made to the primary URL of a chain's RPC configuration
+// over to the chain's other RPC nodes, in order of priority, if the node is unreachable or responds with a 429 or 5xx status.
+//
+// A node that fails a request is considered unhealthy and is tried only after the chain's healthy nodes until it
+// successfully serves a request again. Requests to URLs that are not the primary URL of an RPC configuration are passed through unchanged.
+type FailoverDoer struct {
+	doer   synchttp.Doer
+	chains map[string]*failoverChain // primary URL -> chain
+}
+
+// NewFailoverDoer builds a FailoverDoer that fails over between the nodes of the given RPC configurations, using the given doer to execute requests.
+func NewFailoverDoer(doer synchttp.Doer, configurations []rpcconfig.Configuration) *FailoverDoer {
+	chains := make(map[string]*failoverChain)
+	for _, configuration := range configurations {
+		endpoints := configuration.Endpoints()
+		if len(endpoints) == 0 {
+			continue
+		}
+
+		endpointURLs := make([]string, len(endpoints))
+		for i, endpoint := range endpoints {
+			endpointURLs[i] = endpoint.URL
+		}
+
+		chains[configuration.PrimaryURL()] = &failoverChain{
+			chainName:         configuration.ChainName,
+			endpointURLs:      endpointURLs,
+			crossCheckChainID: configuration.CrossCheckChainID,
+			unhealthyURLs:     make(map[string]bool),
+		}
+	}
+
+	return &FailoverDoer{
+		doer:   doer,
+		chains: chains,
+	}
+}
+
+func (f *FailoverDoer) Do(request *http.Request) (*http.Response, error) {
+	failoverChain, hasChain := f.chains[request.URL.String()]
+	if !hasChain {
+		return f.doer.Do(request)
+	}
+
+	if failoverChain.crossCheckChainID {
+		failoverChain.crossCheckOnce.Do(func() {
+			failoverChain.crossCheckErr = f.crossCheckChainID(request.Context(), failoverChain)
+		})
+
+		if failoverChain.crossCheckErr != nil {
+			return nil, failoverChain.crossCheckErr
+		}
+	}
+
+	if len(failoverChain.endpointURLs) == 1 {
+		return f.doer.Do(request)
+	}
+
+	var requestBody []byte
+	if request.Body != nil {
+		var err error
+		requestBody, err = io.ReadAll(request.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+		_ = request.Body.Close()
+	}
+
+	var errs []error
+	endpointURLs := failoverChain.orderedEndpointURLs()
+	for endpointIndex, endpointURL := range endpointURLs {
+		endpointRequest, err := cloneRequest(request, endpointURL, requestBody)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build request for RPC node '%s': %w", endpointURL, err)
+		}
+
+		response, err := f.doer.Do(endpointRequest)
+		if err != nil {
+			if ctxErr := request.Context().Err(); ctxErr != nil {
+				return nil, err
+			}
+
+			failoverChain.setHealthy(endpointURL, false)
+			errs = append(errs, fmt.Errorf("RPC node '%s' failed: %w", endpointURL, err))
+			continue
+		}
+
+		isLastEndpoint := endpointIndex == len(endpointURLs)-1
+		if isFailoverStatus(response.StatusCode) {
+			failoverChain.setHealthy(endpointURL, false)
+			if !isLastEndpoint {
+				_ = response.Body.Close()
+				continue
+			}
+		} else {
+			failoverChain.setHealthy(endpointURL, true)
+		}
+
+		return response, nil
+	}
+
+	return nil, fmt.Errorf("all RPC nodes for chain '%s' failed: %w", failoverChain.chainName, errors.Join(errs...))
+}
+
+// crossCheckChainID verifies that all of the reachable nodes for the given chain report the same chain ID.
+// Nodes that cannot be reached are marked as unhealthy rather than failing the check.
+func (f *FailoverDoer) crossCheckChainID(ctx context.Context, failoverChain *failoverChain) error {
+	var referenceURL string
+	var referenceChainID *big.Int
+	for _, endpointURL := range failoverChain.endpointURLs {
+		rpcResponse, err := ExecuteRequest(ctx, f.doer, endpointURL, &Request{
+			ID:      1,
+			JSONRPC: "2.0",
+			Method:  "eth_chainId",
+		})
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return fmt.Errorf("failed to cross-check chain ID for chain '%s': %w", failoverChain.chainName, ctxErr)
+			}
+
+			failoverChain.setHealthy(endpointURL, false)
+			continue
+		}
+
+		chainID, isValid := new(big.Int).SetString(strings.TrimPrefix(rpcResponse.Result, "0x"), 16)
+		if !isValid {
+			return fmt.Errorf("RPC node '%s' for chain '%s' returned an invalid chain ID: '%s'", endpointURL, failoverChain.chainName, rpcResponse.Result)
+		}
+
+		if referenceChainID == nil {
+			referenceURL = endpointURL
+			referenceChainID = chainID
+		} else if referenceChainID.Cmp(chainID) != 0 {
+			return fmt.Errorf("RPC nodes for chain '%s' disagree on the chain ID: '%s' reports %v, but '%s' reports %v", failoverChain.chainName, referenceURL, referenceChainID, endpointURL, chainID)
+		}
+	}
+
+	return nil
+}
+
+// isFailoverStatus determines whether the given HTTP status code indicates that a request should be retried against another node.
+func isFailoverStatus(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode >= http.StatusInternalServerError
+}
+
+// cloneRequest builds a copy of the given request that is sent to the given URL with the given body.
+func cloneRequest(request *http.Request, requestURL string, requestBody []byte) (*http.Request, error) {
+	parsedURL, err := url.Parse(requestURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse URL: %w", err)
+	}
+
+	clonedRequest := request.Clone(request.Context())
+	clonedRequest.URL = parsedURL
+	clonedRequest.Host = ""
+	clonedRequest.Body = io.NopCloser(bytes.NewReader(requestBody))
+	clonedRequest.ContentLength = int64(len(requestBody))
+	clonedRequest.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(requestBody)), nil
+	}
+
+	return clonedRequest, nil
+}
+
+// failoverChain tracks the RPC nodes for a chain and their health.
+type failoverChain struct {
+	chainName         string
+	endpointURLs      []string // in order of priority
+	crossCheckChainID bool
+
+	crossCheckOnce sync.Once
+	crossCheckErr  error
+
+	healthMutex   sync.Mutex
+	unhealthyURLs map[string]bool
+}
+
+// orderedEndpointURLs returns the URLs of the chain's nodes in the order in which they should be tried:
+// healthy nodes in order of priority, followed by unhealthy nodes in order of priority.
+func (c *failoverChain) orderedEndpointURLs() []string {
+	c.healthMutex.Lock()
+	defer c.healthMutex.Unlock()
+
+	ordered := make([]string, 0, len(c.endpointURLs))
+	var unhealthy []string
+	for _, endpointURL := range c.endpointURLs {
+		if c.unhealthyURLs[endpointURL] {
+			unhealthy = append(unhealthy, endpointURL)
+		} else {
+			ordered = append(ordered, endpointURL)
+		}
+	}
+
+	return append(ordered, unhealthy...)
+}
+
+func (c *failoverChain) setHealthy(endpointURL string, isHealthy bool) {
+	c.healthMutex.Lock()
+	defer c.healthMutex.Unlock()
+
+	if isHealthy {
+		delete(c.unhealthyURLs, endpointURL)
+	} else {
+		c.unhealthyURLs[endpointURL] = true
+	}
+}
@@ -0,0 +1,140 @@
+package rpc_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net/http"
+
+	"github.com/jarcoal/httpmock"
+	rpcconfig "github.com/jrh3k5/cryptonabber-sync/v3/config/rpc"
+	"github.com/jrh3k5/cryptonabber-sync/v3/http/json/rpc"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FailoverDoer", func() {
+	const (
+		unreachableURL = "http://unreachable.localhost/rpc"
+		overloadedURL  = "http://overloaded.localhost/rpc"
+		otherChainURL  = "http://other-chain.localhost/rpc"
+		chainIDMethod  = "eth_chainId"
+	)
+
+	var ctx context.Context
+
+	executeChainID := func(doer *rpc.FailoverDoer, requestURL string) (*rpc.Response, error) {
+		return rpc.ExecuteRequest(ctx, doer, requestURL, &rpc.Request{
+			ID:      1,
+			JSONRPC: "2.0",
+			Method:  chainIDMethod,
+		})
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		evmNode.RegisterRPCMethodCall(chainIDMethod, func(_ string) (rpc.MockEVMNodeRPCResult, *rpc.MockEVMNodeRPCError, error) {
+			return rpc.NewMockEVMNodeRPCNumericResult(big.NewInt(1)), nil, nil
+		})
+
+		httpmock.RegisterResponder(http.MethodPost, unreachableURL, httpmock.NewErrorResponder(errors.New("connection refused")))
+		httpmock.RegisterResponder(http.MethodPost, overloadedURL, httpmock.NewStringResponder(http.StatusTooManyRequests, "slow down"))
+		httpmock.RegisterResponder(http.MethodPost, otherChainURL, func(_ *http.Request) (*http.Response, error) {
+			return httpmock.NewJsonResponse(http.StatusOK, &rpc.Response{
+				ID:      "1",
+				JSONRPC: "2.0",
+				Result:  "0x2105",
+			})
+		})
+	})
+
+	It("fails over to the next node if a node is unreachable", func() {
+		doer := rpc.NewFailoverDoer(http.DefaultClient, []rpcconfig.Configuration{
+			{
+				ChainName: "ethereum",
+				RPCURL:    unreachableURL,
+				RPCURLs:   []rpcconfig.Endpoint{{URL: evmNode.URL()}},
+			},
+		})
+
+		response, err := executeChainID(doer, unreachableURL)
+		Expect(err).ToNot(HaveOccurred(), "the request should be failed over")
+		Expect(response.Result).To(Equal(rpc.NewMockEVMNodeRPCNumericResult(big.NewInt(1)).ReturnValue()), "the response of the next node should be returned")
+	})
+
+	It("fails over to the next node if a node is rate-limiting requests, and prefers healthy nodes afterward", func() {
+		doer := rpc.NewFailoverDoer(http.DefaultClient, []rpcconfig.Configuration{
+			{
+				ChainName: "ethereum",
+				RPCURLs: []rpcconfig.Endpoint{
+					{URL: evmNode.URL(), Priority: 2},
+					{URL: overloadedURL, Priority: 1},
+				},
+			},
+		})
+
+		callCountKey := http.MethodPost + " " + overloadedURL
+		overloadedCallsBefore := httpmock.GetCallCountInfo()[callCountKey]
+
+		for range 2 {
+			_, err := executeChainID(doer, overloadedURL)
+			Expect(err).ToNot(HaveOccurred(), "the request should be failed over")
+		}
+
+		Expect(httpmock.GetCallCountInfo()[callCountKey]-overloadedCallsBefore).To(Equal(1), "the unhealthy node should not be tried first once it has failed")
+	})
+
+	It("returns the last node's response if all of the nodes fail", func() {
+		doer := rpc.NewFailoverDoer(http.DefaultClient, []rpcconfig.Configuration{
+			{
+				ChainName: "ethereum",
+				RPCURL:    unreachableURL,
+				RPCURLs:   []rpcconfig.Endpoint{{URL: overloadedURL}},
+			},
+		})
+
+		_, err := executeChainID(doer, unreachableURL)
+		Expect(err).To(HaveOccurred(), "the request should fail")
+		Expect(err.Error()).To(ContainSubstring("429"), "the status of the last node should be surfaced")
+	})
+
+	It("passes through requests to URLs that are not configured", func() {
+		doer := rpc.NewFailoverDoer(http.DefaultClient, nil)
+
+		response, err := executeChainID(doer, evmNode.URL())
+		Expect(err).ToNot(HaveOccurred(), "the request should succeed")
+		Expect(response.Result).To(Equal(rpc.NewMockEVMNodeRPCNumericResult(big.NewInt(1)).ReturnValue()), "the response of the node should be returned")
+	})
+
+	When("chain IDs are cross-checked", func() {
+		It("fails if the nodes disagree on the chain ID", func() {
+			doer := rpc.NewFailoverDoer(http.DefaultClient, []rpcconfig.Configuration{
+				{
+					ChainName:         "ethereum",
+					RPCURL:            evmNode.URL(),
+					RPCURLs:           []rpcconfig.Endpoint{{URL: otherChainURL, Priority: 1}},
+					CrossCheckChainID: true,
+				},
+			})
+
+			_, err := executeChainID(doer, evmNode.URL())
+			Expect(err).To(HaveOccurred(), "the disagreement should fail the request")
+			Expect(err.Error()).To(ContainSubstring("disagree on the chain ID"), "the disagreement should be described")
+		})
+
+		It("ignores unreachable nodes", func() {
+			doer := rpc.NewFailoverDoer(http.DefaultClient, []rpcconfig.Configuration{
+				{
+					ChainName:         "ethereum",
+					RPCURL:            unreachableURL,
+					RPCURLs:           []rpcconfig.Endpoint{{URL: evmNode.URL(), Priority: 1}},
+					CrossCheckChainID: true,
+				},
+			})
+
+			_, err := executeChainID(doer, unreachableURL)
+			Expect(err).ToNot(HaveOccurred(), "the request should be failed over")
+		})
+	})
+})
@@ -1,31 +1,48 @@
 package rpc
 
 import (
+	"bytes"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"math/big"
 	"net/http"
 	"strings"
+	"sync"
 
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/jarcoal/httpmock"
+	"github.com/jrh3k5/cryptonabber-sync/v3/evm/abi"
 )
 
 const (
-	lengthFunctionSelector   = 10
-	lengthFunctionArgPadding = 24
+	lengthFunctionSelector = 10
+	lengthABIWord          = 64
 )
 
 // MockEVMNodeRPCResult is the result of an RPC call.
 type MockEVMRPCMethodCallHandler func(methodName string) (MockEVMNodeRPCResult, *MockEVMNodeRPCError, error)
 
+// MockEVMRPCMethodParamsCallHandler is a function that handles a call to an RPC method using its parameters.
+// The returned result can be any value that can be marshaled to JSON.
+type MockEVMRPCMethodParamsCallHandler func(params []any) (any, *MockEVMNodeRPCError, error)
+
 // MockEVMNodeETHCallCallHandler is a function that handles a function call.
+// Each of the given parameters is the hex-encoded (without a 0x prefix) 32-byte ABI word of the calldata, in order.
 type MockEVMNodeETHCallCallHandler func(functionSelector string, params []string) (MockEVMNodeRPCResult, *MockEVMNodeRPCError, error)
 
 // MockEVMNode is a mock EVM node.
 type MockEVMNode struct {
 	methodCallHandles map[string]MockEVMRPCMethodCallHandler              // method name -> handler
-	ethCallHandlers   map[string]map[string]MockEVMNodeETHCallCallHandler // token address -> function selector -> handler
+	paramsCallHandles map[string]MockEVMRPCMethodParamsCallHandler        // method name -> handler
+	ethCallHandlers   map[string]map[string]MockEVMNodeETHCallCallHandler // lowercased contract address -> function selector -> handler
+
+	stateMutex          sync.Mutex
+	ethCallCount        int
+	lastETHCallBlockTag string
+	requestCount        int
+	rejectBatches       bool
 }
 
 // StartMockEVMNode starts a mock EVM node.
@@ -34,100 +51,109 @@ func StartMockEVMNode() *MockEVMNode {
 
 	evmNode := &MockEVMNode{
 		methodCallHandles: make(map[string]MockEVMRPCMethodCallHandler),
+		paramsCallHandles: make(map[string]MockEVMRPCMethodParamsCallHandler),
 		ethCallHandlers:   make(map[string]map[string]MockEVMNodeETHCallCallHandler),
 	}
 
 	httpmock.RegisterResponder(http.MethodPost, evmNode.URL(), func(request *http.Request) (*http.Response, error) {
+		evmNode.countRequest()
+
+		requestBytes, err := io.ReadAll(request.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+
+		if trimmedBytes := bytes.TrimSpace(requestBytes); len(trimmedBytes) > 0 && trimmedBytes[0] == '[' {
+			return evmNode.handleBatchRequest(trimmedBytes)
+		}
+
 		requestBody := &Request{}
-		if unmarshalErr := json.NewDecoder(request.Body).Decode(requestBody); unmarshalErr != nil {
+		if unmarshalErr := json.Unmarshal(requestBytes, requestBody); unmarshalErr != nil {
 			return nil, fmt.Errorf("failed to unmarshal request body: %w", unmarshalErr)
 		}
 
-		methodHandler, hasMethodHandler := evmNode.methodCallHandles[requestBody.Method]
-		if hasMethodHandler {
-			methodResult, rpcError, err := methodHandler(requestBody.Method)
-			if err != nil {
-				return nil, fmt.Errorf("failed to handle method call: %w", err)
-			}
+		response, err := evmNode.handleRequest(requestBody)
+		if err != nil {
+			return nil, err
+		}
 
-			response := &Response{
-				ID:      json.Number(fmt.Sprintf("%d", requestBody.ID)),
-				JSONRPC: requestBody.JSONRPC,
-				Result:  methodResult.ReturnValue(),
-			}
+		return httpmock.NewJsonResponse(http.StatusOK, response)
+	})
 
-			if rpcError != nil {
-				response.Error = ResponseError{
-					Code:    rpcError.Code,
-					Message: rpcError.Message,
-				}
-			}
+	return evmNode
+}
 
-			return httpmock.NewJsonResponse(http.StatusOK, response)
-		}
+// handleBatchRequest handles a JSON-RPC batch request.
+// The responses are returned in the reverse of the order of their requests, as nodes are not obligated to preserve the order.
+func (m *MockEVMNode) handleBatchRequest(requestBytes []byte) (*http.Response, error) {
+	m.stateMutex.Lock()
+	rejectBatches := m.rejectBatches
+	m.stateMutex.Unlock()
+
+	if rejectBatches {
+		return httpmock.NewJsonResponse(http.StatusOK, &Response{
+			JSONRPC: "2.0",
+			Error: ResponseError{
+				Code:    -32600,
+				Message: "batch requests are not supported",
+			},
+		})
+	}
 
-		if requestBody.Method != "eth_call" {
-			return nil, fmt.Errorf("unexpected request method: %s", requestBody.Method)
-		}
+	var requestBodies []*Request
+	if unmarshalErr := json.Unmarshal(requestBytes, &requestBodies); unmarshalErr != nil {
+		return nil, fmt.Errorf("failed to unmarshal batch request body: %w", unmarshalErr)
+	}
 
-		if len(requestBody.Params) != 2 {
-			return nil, fmt.Errorf("unexpected number of request parameters: %d", len(requestBody.Params))
+	responses := make([]any, len(requestBodies))
+	for i, requestBody := range requestBodies {
+		response, err := m.handleRequest(requestBody)
+		if err != nil {
+			return nil, err
 		}
 
-		funcArgs := requestBody.Params[0].(map[string]any)
-		targetAddress, targetAddressIsString := funcArgs["to"].(string)
-		if !targetAddressIsString {
-			return nil, fmt.Errorf("unexpected request parameter type: %T", funcArgs["to"])
-		}
+		responses[len(responses)-1-i] = response
+	}
 
-		contractHandlers, hasContract := evmNode.ethCallHandlers[targetAddress]
-		if !hasContract {
-			return httpmock.NewJsonResponse(http.StatusOK, &Response{
-				ID:      json.Number(fmt.Sprintf("%d", requestBody.ID)),
-				JSONRPC: "2.0",
-				Result:  "0x",
-			})
-		}
+	return httpmock.NewJsonResponse(http.StatusOK, responses)
+}
 
-		data, dataIsString := funcArgs["data"].(string)
-		if !dataIsString {
-			return nil, fmt.Errorf("unexpected request parameter type: %T", funcArgs["data"])
+// handleRequest resolves the response to a single JSON-RPC request.
+func (m *MockEVMNode) handleRequest(requestBody *Request) (any, error) {
+	paramsHandler, hasParamsHandler := m.paramsCallHandles[requestBody.Method]
+	if hasParamsHandler {
+		result, rpcError, err := paramsHandler(requestBody.Params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to handle method call: %w", err)
 		}
 
-		functionSelector := data[0:10]
-		handler, hasHandler := contractHandlers[functionSelector]
-		if !hasHandler {
-			// simulate a lack of the function being defined
-			return httpmock.NewJsonResponse(http.StatusOK, &Response{
-				ID:      json.Number(fmt.Sprintf("%d", requestBody.ID)),
-				JSONRPC: "2.0",
-				Error:   ResponseError{Code: -32000, Message: "execution reverted"},
-			})
+		response := map[string]any{
+			"id":      requestBody.ID,
+			"jsonrpc": "2.0",
 		}
 
-		// It's a no-arg call
-		var functionResult MockEVMNodeRPCResult
-		var rpcError *MockEVMNodeRPCError
-		if len(data) < lengthFunctionSelector+lengthFunctionArgPadding {
-			var err error
-			functionResult, rpcError, err = handler(data[0:10], nil)
-			if err != nil {
-				return nil, fmt.Errorf("failed to handle function call for selector '%s': %w", functionSelector, err)
+		if rpcError != nil {
+			response["error"] = ResponseError{
+				Code:    rpcError.Code,
+				Message: rpcError.Message,
 			}
 		} else {
-			parameter := data[lengthFunctionSelector+lengthFunctionArgPadding:]
-			// for now, only support single-arg function calls
-			var err error
-			functionResult, rpcError, err = handler(data[0:10], []string{parameter})
-			if err != nil {
-				return nil, fmt.Errorf("failed to handle function call for selector '%s': %w", functionSelector, err)
-			}
+			response["result"] = result
+		}
+
+		return response, nil
+	}
+
+	methodHandler, hasMethodHandler := m.methodCallHandles[requestBody.Method]
+	if hasMethodHandler {
+		methodResult, rpcError, err := methodHandler(requestBody.Method)
+		if err != nil {
+			return nil, fmt.Errorf("failed to handle method call: %w", err)
 		}
 
 		response := &Response{
 			ID:      json.Number(fmt.Sprintf("%d", requestBody.ID)),
 			JSONRPC: requestBody.JSONRPC,
-			Result:  functionResult.ReturnValue(),
 		}
 
 		if rpcError != nil {
@@ -135,17 +161,213 @@ func StartMockEVMNode() *MockEVMNode {
 				Code:    rpcError.Code,
 				Message: rpcError.Message,
 			}
+		} else {
+			response.Result = methodResult.ReturnValue()
 		}
 
-		return httpmock.NewJsonResponse(http.StatusOK, response)
+		return response, nil
+	}
+
+	if requestBody.Method == "eth_getCode" {
+		return m.handleGetCode(requestBody)
+	}
+
+	if requestBody.Method != "eth_call" {
+		return nil, fmt.Errorf("unexpected request method: %s", requestBody.Method)
+	}
+
+	if len(requestBody.Params) != 2 {
+		return nil, fmt.Errorf("unexpected number of request parameters: %d", len(requestBody.Params))
+	}
+
+	blockTag, _ := requestBody.Params[1].(string)
+	m.countETHCall(blockTag)
+
+	funcArgs := requestBody.Params[0].(map[string]any)
+	targetAddress, targetAddressIsString := funcArgs["to"].(string)
+	if !targetAddressIsString {
+		return nil, fmt.Errorf("unexpected request parameter type: %T", funcArgs["to"])
+	}
+
+	data, dataIsString := funcArgs["data"].(string)
+	if !dataIsString {
+		return nil, fmt.Errorf("unexpected request parameter type: %T", funcArgs["data"])
+	}
+
+	result, rpcError, err := m.handleETHCall(targetAddress, data)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &Response{
+		ID:      json.Number(fmt.Sprintf("%d", requestBody.ID)),
+		JSONRPC: "2.0",
+		Result:  result,
+	}
+
+	if rpcError != nil {
+		response.Error = ResponseError{
+			Code:    rpcError.Code,
+			Message: rpcError.Message,
+		}
+	}
+
+	return response, nil
+}
+
+// handleGetCode resolves the response to an eth_getCode request, which returns placeholder code for any address at which a contract is registered.
+func (m *MockEVMNode) handleGetCode(requestBody *Request) (any, error) {
+	if len(requestBody.Params) != 2 {
+		return nil, fmt.Errorf("unexpected number of request parameters: %d", len(requestBody.Params))
+	}
+
+	address, addressIsString := requestBody.Params[0].(string)
+	if !addressIsString {
+		return nil, fmt.Errorf("unexpected request parameter type: %T", requestBody.Params[0])
+	}
+
+	code := "0x"
+	if _, hasContract := m.ethCallHandlers[strings.ToLower(address)]; hasContract {
+		code = "0x6080604052"
+	}
+
+	return &Response{
+		ID:      json.Number(fmt.Sprintf("%d", requestBody.ID)),
+		JSONRPC: "2.0",
+		Result:  code,
+	}, nil
+}
+
+// handleETHCall resolves the result of an eth_call of the given calldata against the given address.
+func (m *MockEVMNode) handleETHCall(targetAddress string, data string) (string, *MockEVMNodeRPCError, error) {
+	contractHandlers, hasContract := m.ethCallHandlers[strings.ToLower(targetAddress)]
+	if !hasContract {
+		return "0x", nil, nil
+	}
+
+	functionSelector := data[0:lengthFunctionSelector]
+	handler, hasHandler := contractHandlers[functionSelector]
+	if !hasHandler {
+		// simulate a lack of the function being defined
+		return "", &MockEVMNodeRPCError{Code: -32000, Message: "execution reverted"}, nil
+	}
+
+	// Each parameter is handed to the handler as its hex-encoded 32-byte ABI word
+	var parameters []string
+	for argsData := data[lengthFunctionSelector:]; len(argsData) > 0; argsData = argsData[min(lengthABIWord, len(argsData)):] {
+		parameters = append(parameters, argsData[:min(lengthABIWord, len(argsData))])
+	}
+
+	functionResult, rpcError, err := handler(functionSelector, parameters)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to handle function call for selector '%s': %w", functionSelector, err)
+	}
+
+	if rpcError != nil {
+		return "", rpcError, nil
+	}
+
+	return functionResult.ReturnValue(), nil, nil
+}
+
+// RegisterMulticall3 registers a Multicall3 contract at its canonical address, which dispatches
+// each call within an aggregate3 call to the handlers registered on this node.
+func (m *MockEVMNode) RegisterMulticall3() {
+	m.RegisterETHCallCall("aggregate3", Multicall3Address, []string{"(address,bool,bytes)[]"}, func(_ string, params []string) (MockEVMNodeRPCResult, *MockEVMNodeRPCError, error) {
+		decodedArgs, err := abi.DecodeHex([]string{"(address,bool,bytes)[]"}, "0x"+strings.Join(params, ""))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to decode aggregate3 arguments: %w", err)
+		}
+
+		calls := decodedArgs[0].([]any)
+		callResults := make([]any, len(calls))
+		for i, callAny := range calls {
+			call := callAny.([]any)
+			allowFailure := call[1].(bool)
+
+			result, rpcError, err := m.handleETHCall(call[0].(string), "0x"+hex.EncodeToString(call[2].([]byte)))
+			if err != nil {
+				return nil, nil, err
+			}
+
+			if rpcError != nil {
+				if !allowFailure {
+					return nil, &MockEVMNodeRPCError{Code: -32000, Message: "execution reverted: Multicall3: call failed"}, nil
+				}
+
+				callResults[i] = []any{false, []byte{}}
+				continue
+			}
+
+			returnData, err := hex.DecodeString(strings.TrimPrefix(result, "0x"))
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to decode call result '%s': %w", result, err)
+			}
+
+			callResults[i] = []any{true, returnData}
+		}
+
+		return NewMockEVMNodeRPCABIResult([]string{"(bool,bytes)[]"}, callResults), nil, nil
 	})
+}
 
-	return evmNode
+// ETHCallCount returns the number of eth_call requests that this node has received.
+// Each eth_call within a batch request is counted individually.
+func (m *MockEVMNode) ETHCallCount() int {
+	m.stateMutex.Lock()
+	defer m.stateMutex.Unlock()
+
+	return m.ethCallCount
+}
+
+// LastETHCallBlockTag returns the block parameter of the last eth_call request that this node received.
+func (m *MockEVMNode) LastETHCallBlockTag() string {
+	m.stateMutex.Lock()
+	defer m.stateMutex.Unlock()
+
+	return m.lastETHCallBlockTag
+}
+
+func (m *MockEVMNode) countETHCall(blockTag string) {
+	m.stateMutex.Lock()
+	defer m.stateMutex.Unlock()
+
+	m.ethCallCount++
+	m.lastETHCallBlockTag = blockTag
+}
+
+// RequestCount returns the number of HTTP requests that this node has received.
+// A batch request is counted as a single request.
+func (m *MockEVMNode) RequestCount() int {
+	m.stateMutex.Lock()
+	defer m.stateMutex.Unlock()
+
+	return m.requestCount
+}
+
+func (m *MockEVMNode) countRequest() {
+	m.stateMutex.Lock()
+	defer m.stateMutex.Unlock()
+
+	m.requestCount++
+}
+
+// SetRejectBatchRequests sets whether this node rejects JSON-RPC batch requests, as nodes without batch support do.
+func (m *MockEVMNode) SetRejectBatchRequests(rejectBatches bool) {
+	m.stateMutex.Lock()
+	defer m.stateMutex.Unlock()
+
+	m.rejectBatches = rejectBatches
 }
 
 // RegisterContractExistence registers the existence of a contract.
 func (m *MockEVMNode) RegisterContractExistence(address string) {
-	m.ethCallHandlers[address] = make(map[string]MockEVMNodeETHCallCallHandler)
+	m.ethCallHandlers[strings.ToLower(address)] = make(map[string]MockEVMNodeETHCallCallHandler)
+}
+
+// UnregisterContract removes the contract at the given address, along with all of its registered function call handlers.
+func (m *MockEVMNode) UnregisterContract(address string) {
+	delete(m.ethCallHandlers, strings.ToLower(address))
 }
 
 // RegisterRPCMethodCall registers a function call handler for calls to RPC methods.
@@ -159,6 +381,17 @@ func (m *MockEVMNode) RegisterRPCMethodCall(
 	return nil
 }
 
+// RegisterRPCMethodParamsCall registers a function call handler for calls to RPC methods whose results depend on their parameters.
+// Handlers registered this way take precedence over those registered using RegisterRPCMethodCall.
+func (m *MockEVMNode) RegisterRPCMethodParamsCall(
+	methodName string,
+	callHandler MockEVMRPCMethodParamsCallHandler,
+) error {
+	m.paramsCallHandles[methodName] = callHandler
+
+	return nil
+}
+
 // RegisterETHCallCall registers a function call handler for calls to functions using eth_call.
 func (m *MockEVMNode) RegisterETHCallCall(
 	functionName string,
@@ -169,10 +402,10 @@ func (m *MockEVMNode) RegisterETHCallCall(
 	functionSignature := fmt.Sprintf("%s(%s)", functionName, strings.Join(parameterTypes, ","))
 	functionSelector := crypto.Keccak256Hash(([]byte(functionSignature))).String()[0:10]
 
-	contractHandlers, hasContract := m.ethCallHandlers[targetAddress]
+	contractHandlers, hasContract := m.ethCallHandlers[strings.ToLower(targetAddress)]
 	if !hasContract {
 		contractHandlers = make(map[string]MockEVMNodeETHCallCallHandler)
-		m.ethCallHandlers[targetAddress] = contractHandlers
+		m.ethCallHandlers[strings.ToLower(targetAddress)] = contractHandlers
 	}
 
 	contractHandlers[functionSelector] = callHandler
@@ -227,5 +460,30 @@ func NewMockEVMNodeRPCNumericResult(number *big.Int) *MockEVMNodeRPCNumericResul
 }
 
 func (n MockEVMNodeRPCNumericResult) ReturnValue() string {
-	return fmt.Sprintf("0x%x", n.Number)
+	return fmt.Sprintf("0x%064x", n.Number)
+}
+
+// MockEVMNodeRPCABIResult is the result of an RPC call that returns one or more ABI-encoded values.
+type MockEVMNodeRPCABIResult struct {
+	Types  []string
+	Values []any
+}
+
+// NewMockEVMNodeRPCABIResult builds a MockEVMNodeRPCABIResult instance that encodes the given values as the given types.
+func NewMockEVMNodeRPCABIResult(types []string, values ...any) *MockEVMNodeRPCABIResult {
+	return &MockEVMNodeRPCABIResult{Types: types, Values: values}
+}
+
+func (a MockEVMNodeRPCABIResult) ReturnValue() string {
+	types, err := abi.ParseTypes(a.Types)
+	if err != nil {
+		panic(fmt.Sprintf("invalid mock result types: %v", err))
+	}
+
+	encoded, err := abi.Encode(types, a.Values)
+	if err != nil {
+		panic(fmt.Sprintf("failed to encode mock result: %v", err))
+	}
+
+	return "0x" + hex.EncodeToString(encoded)
 }
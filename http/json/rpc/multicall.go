@@ -0,0 +1,244 @@
+package rpc
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jrh3k5/cryptonabber-sync/v3/evm/abi"
+	synchttp "github.com/jrh3k5/cryptonabber-sync/v3/http"
+)
+
+// Multicall3Address is the address at which the Multicall3 contract is deployed on most EVM chains.
+// See: https://www.multicall3.com/
+const Multicall3Address = "0xcA11bde05977b3631167028862bE2a173976CA11"
+
+// maxMulticallBatchSize is the maximum number of calls to be aggregated into a single Multicall3 invocation.
+const maxMulticallBatchSize = 100
+
+// MulticallEthCaller is an EthCaller that collects calls made against the same node within a short window of time
+// and executes them together as a single Multicall3 aggregate3 call.
+//
+// Each call within a batch is allowed to fail without failing the rest of the batch; a call that fails within
+// the batch is retried on its own so that its error is the same as it would have been had it not been batched.
+// If a node does not support Multicall3 (e.g., the contract is not deployed on its chain), calls against it
+// are sent as a JSON-RPC batch of individual eth_call requests instead. If a multicall fails for any other reason,
+// only the calls of that batch are sent individually.
+type MulticallEthCaller struct {
+	doer          synchttp.Doer
+	blockResolver BlockResolver
+	batchWindow   time.Duration
+
+	stateMutex       sync.Mutex
+	batches          map[string]*multicallBatch // node URL -> batch currently collecting calls
+	unsupportedNodes map[string]bool            // node URLs that have been found to not support Multicall3
+}
+
+// NewMulticallEthCaller builds a MulticallEthCaller that waits for the given window of time after receiving a call
+// to collect further calls to be batched with it. Calls are made against the blocks resolved by the given BlockResolver.
+func NewMulticallEthCaller(doer synchttp.Doer, blockResolver BlockResolver, batchWindow time.Duration) *MulticallEthCaller {
+	return &MulticallEthCaller{
+		doer:             doer,
+		blockResolver:    blockResolver,
+		batchWindow:      batchWindow,
+		batches:          make(map[string]*multicallBatch),
+		unsupportedNodes: make(map[string]bool),
+	}
+}
+
+func (m *MulticallEthCaller) EthCall(ctx context.Context, nodeURL string, methodName string, contractAddress string, args ...*EthCallArgument) (string, error) {
+	callData, err := EncodeEthCallData(methodName, args...)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode call data: %w", err)
+	}
+
+	call := &multicallCall{
+		contractAddress: contractAddress,
+		callData:        callData,
+		resultChan:      make(chan multicallResult, 1),
+	}
+
+	m.stateMutex.Lock()
+	batch, hasBatch := m.batches[nodeURL]
+	if !hasBatch {
+		batch = &multicallBatch{
+			ctx: context.WithoutCancel(ctx),
+		}
+		m.batches[nodeURL] = batch
+		time.AfterFunc(m.batchWindow, func() {
+			m.flush(nodeURL, batch)
+		})
+	}
+
+	batch.calls = append(batch.calls, call)
+	if len(batch.calls) >= maxMulticallBatchSize {
+		delete(m.batches, nodeURL)
+		go m.execute(nodeURL, batch)
+	}
+	m.stateMutex.Unlock()
+
+	select {
+	case result := <-call.resultChan:
+		return result.result, result.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// flush executes the given batch if it is still collecting calls.
+func (m *MulticallEthCaller) flush(nodeURL string, batch *multicallBatch) {
+	m.stateMutex.Lock()
+	if m.batches[nodeURL] != batch {
+		// The batch filled up and has already been executed
+		m.stateMutex.Unlock()
+		return
+	}
+	delete(m.batches, nodeURL)
+	m.stateMutex.Unlock()
+
+	m.execute(nodeURL, batch)
+}
+
+func (m *MulticallEthCaller) execute(nodeURL string, batch *multicallBatch) {
+	blockNumber, err := m.blockResolver.ResolveBlock(batch.ctx, nodeURL)
+	if err != nil {
+		for _, call := range batch.calls {
+			call.resultChan <- multicallResult{err: fmt.Errorf("failed to resolve block for node: %w", err)}
+		}
+		return
+	}
+	batch.blockNumber = blockNumber
+
+	m.stateMutex.Lock()
+	isUnsupported := m.unsupportedNodes[nodeURL]
+	m.stateMutex.Unlock()
+
+	if isUnsupported || len(batch.calls) == 1 {
+		m.executeIndividually(nodeURL, batch)
+		return
+	}
+
+	aggregateCalls := make([]any, len(batch.calls))
+	for i, call := range batch.calls {
+		aggregateCalls[i] = []any{call.contractAddress, true, call.callData}
+	}
+
+	aggregateResult, err := ExecuteEthCall(batch.ctx, m.doer, nodeURL, batch.blockNumber, "aggregate3", Multicall3Address, Arg("(address,bool,bytes)[]", aggregateCalls))
+	if err != nil {
+		var rpcCallErr *RPCCallError
+		if errors.As(err, &rpcCallErr) && !m.hasMulticall3(batch.ctx, nodeURL, batch.blockNumber) {
+			// The node answered, but has no Multicall3 contract with which to execute the multicall
+			m.markUnsupported(nodeURL)
+		}
+
+		m.executeIndividually(nodeURL, batch)
+		return
+	}
+
+	if strings.TrimPrefix(aggregateResult, "0x") == "" {
+		// A call to an address without a contract returns no data, which is typical of chains on which Multicall3 is not deployed
+		m.markUnsupported(nodeURL)
+		m.executeIndividually(nodeURL, batch)
+		return
+	}
+
+	decoded, err := abi.DecodeHex([]string{"(bool,bytes)[]"}, aggregateResult)
+	if err != nil {
+		m.executeIndividually(nodeURL, batch)
+		return
+	}
+
+	callResults := decoded[0].([]any)
+	if len(callResults) != len(batch.calls) {
+		m.executeIndividually(nodeURL, batch)
+		return
+	}
+
+	var failedCalls []*multicallCall
+	for i, call := range batch.calls {
+		callResult := callResults[i].([]any)
+		if success := callResult[0].(bool); !success {
+			failedCalls = append(failedCalls, call)
+			continue
+		}
+
+		call.resultChan <- multicallResult{result: "0x" + hex.EncodeToString(callResult[1].([]byte))}
+	}
+
+	if len(failedCalls) > 0 {
+		// Re-execute the failed calls on their own to surface the node's errors for them
+		m.executeIndividually(nodeURL, &multicallBatch{ctx: batch.ctx, blockNumber: batch.blockNumber, calls: failedCalls})
+	}
+}
+
+// executeIndividually executes each of the calls in the given batch as its own eth_call, sending them together as a JSON-RPC batch.
+func (m *MulticallEthCaller) executeIndividually(nodeURL string, batch *multicallBatch) {
+	rpcRequests := make([]*Request, len(batch.calls))
+	for i, call := range batch.calls {
+		rpcRequests[i] = buildEthCallRequest(batch.blockNumber, call.contractAddress, call.callData)
+	}
+
+	batchResults, err := ExecuteBatchRequest(batch.ctx, m.doer, nodeURL, rpcRequests)
+	if err != nil {
+		for _, call := range batch.calls {
+			call.resultChan <- multicallResult{err: err}
+		}
+		return
+	}
+
+	for i, call := range batch.calls {
+		batchResult := batchResults[i]
+		if batchResult.Err != nil {
+			call.resultChan <- multicallResult{err: fmt.Errorf("failed to execute request: %w", batchResult.Err)}
+			continue
+		}
+
+		call.resultChan <- multicallResult{result: batchResult.Response.Result}
+	}
+}
+
+// hasMulticall3 determines whether there is a contract deployed at the Multicall3 address on the node's chain as of the given block.
+// If that cannot be determined, the contract is assumed to be deployed, so that a transient error does not stop calls to the node from being batched.
+func (m *MulticallEthCaller) hasMulticall3(ctx context.Context, nodeURL string, blockNumber *big.Int) bool {
+	rpcResponse, err := ExecuteRequest(ctx, m.doer, nodeURL, &Request{
+		ID:      1,
+		JSONRPC: "2.0",
+		Method:  "eth_getCode",
+		Params:  []any{Multicall3Address, BlockTag(blockNumber)},
+	})
+	if err != nil {
+		return true
+	}
+
+	return strings.TrimPrefix(rpcResponse.Result, "0x") != ""
+}
+
+// markUnsupported records that the node at the given URL does not support Multicall3, so calls to it are no longer batched.
+func (m *MulticallEthCaller) markUnsupported(nodeURL string) {
+	m.stateMutex.Lock()
+	defer m.stateMutex.Unlock()
+
+	m.unsupportedNodes[nodeURL] = true
+}
+
+type multicallBatch struct {
+	ctx         context.Context
+	blockNumber *big.Int // the block against which the calls are made; resolved when the batch is executed
+	calls       []*multicallCall
+}
+
+type multicallCall struct {
+	contractAddress string
+	callData        string
+	resultChan      chan multicallResult
+}
+
+type multicallResult struct {
+	result string
+	err    error
+}
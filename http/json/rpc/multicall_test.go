@@ -0,0 +1,152 @@
+package rpc_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/jrh3k5/cryptonabber-sync/v3/evm/abi"
+	"github.com/jrh3k5/cryptonabber-sync/v3/http/json/rpc"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MulticallEthCaller", func() {
+	var caller *rpc.MulticallEthCaller
+
+	var ctx context.Context
+
+	tokenAddresses := []string{
+		"0xa83114A443dA1CecEFC50368531cACE9F37fCCcb",
+		"0x192850F437160A09A48a056Df3C2dacc68769d34",
+		"0x4838B106FCe9647Bdf1E7877BF73cE8B0BAD5f97",
+	}
+
+	// callConcurrently calls decimals() on each of the given token addresses at once, returning the decoded decimals and errors in order
+	callConcurrently := func(addresses []string) ([]int64, []error) {
+		decimals := make([]int64, len(addresses))
+		errs := make([]error, len(addresses))
+
+		var waitGroup sync.WaitGroup
+		for i, address := range addresses {
+			waitGroup.Add(1)
+			go func() {
+				defer waitGroup.Done()
+
+				result, err := caller.EthCall(ctx, evmNode.URL(), "decimals", address)
+				if err != nil {
+					errs[i] = err
+					return
+				}
+
+				decoded, err := abi.DecodeHex([]string{"uint8"}, result)
+				if err != nil {
+					errs[i] = err
+					return
+				}
+
+				decimals[i] = decoded[0].(*big.Int).Int64()
+			}()
+		}
+		waitGroup.Wait()
+
+		return decimals, errs
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		caller = rpc.NewMulticallEthCaller(http.DefaultClient, rpc.NewLatestBlockResolver(), 20*time.Millisecond)
+
+		evmNode.RegisterMulticall3()
+
+		for i, tokenAddress := range tokenAddresses {
+			tokenDecimals := int64(6 + i)
+			evmNode.RegisterETHCallCall("decimals", tokenAddress, nil, func(_ string, _ []string) (rpc.MockEVMNodeRPCResult, *rpc.MockEVMNodeRPCError, error) {
+				return rpc.NewMockEVMNodeRPCNumericResult(big.NewInt(tokenDecimals)), nil, nil
+			})
+		}
+	})
+
+	It("batches concurrent calls into a single eth_call", func() {
+		callCountBefore := evmNode.ETHCallCount()
+
+		decimals, errs := callConcurrently(tokenAddresses)
+		for i, err := range errs {
+			Expect(err).ToNot(HaveOccurred(), "call %d should not fail", i)
+		}
+
+		Expect(decimals).To(Equal([]int64{6, 7, 8}), "each call should receive its own result")
+		Expect(evmNode.ETHCallCount()-callCountBefore).To(Equal(1), "all of the calls should have been made in a single request")
+	})
+
+	When("a call within the batch fails", func() {
+		It("fails only that call", func() {
+			revertingAddress := "0x68d30f47F19c07bCCEf4Ac7FAE2Dc12FCa3e0dC9"
+			evmNode.RegisterContractExistence(revertingAddress)
+
+			decimals, errs := callConcurrently(append([]string{revertingAddress}, tokenAddresses...))
+
+			var rpcCallErr *rpc.RPCCallError
+			Expect(errors.As(errs[0], &rpcCallErr)).To(BeTrue(), "the failed call should surface the node's error")
+			for i, err := range errs[1:] {
+				Expect(err).ToNot(HaveOccurred(), "call %d should not fail", i+1)
+			}
+
+			Expect(decimals[1:]).To(Equal([]int64{6, 7, 8}), "the other calls should receive their results")
+		})
+	})
+
+	When("the node cannot execute the multicall", func() {
+		BeforeEach(func() {
+			evmNode.RegisterETHCallCall("aggregate3", rpc.Multicall3Address, []string{"(address,bool,bytes)[]"}, func(_ string, _ []string) (rpc.MockEVMNodeRPCResult, *rpc.MockEVMNodeRPCError, error) {
+				return nil, &rpc.MockEVMNodeRPCError{Code: -32000, Message: "execution reverted"}, nil
+			})
+		})
+
+		It("falls back to executing the calls of only that batch individually", func() {
+			decimals, errs := callConcurrently(tokenAddresses)
+			for i, err := range errs {
+				Expect(err).ToNot(HaveOccurred(), "call %d should not fail", i)
+			}
+
+			Expect(decimals).To(Equal([]int64{6, 7, 8}), "each call should receive its own result")
+
+			callCountBefore := evmNode.ETHCallCount()
+			_, errs = callConcurrently(tokenAddresses)
+			for i, err := range errs {
+				Expect(err).ToNot(HaveOccurred(), "call %d should not fail", i)
+			}
+
+			Expect(evmNode.ETHCallCount()-callCountBefore).To(Equal(len(tokenAddresses)+1), "the multicall should be attempted again, as the Multicall3 contract is deployed")
+		})
+	})
+
+	When("Multicall3 is not deployed on the node's chain", func() {
+		BeforeEach(func() {
+			evmNode.UnregisterContract(rpc.Multicall3Address)
+		})
+
+		It("falls back to executing all further calls individually in a JSON-RPC batch", func() {
+			decimals, errs := callConcurrently(tokenAddresses)
+			for i, err := range errs {
+				Expect(err).ToNot(HaveOccurred(), "call %d should not fail", i)
+			}
+
+			Expect(decimals).To(Equal([]int64{6, 7, 8}), "each call should receive its own result")
+
+			callCountBefore := evmNode.ETHCallCount()
+			requestCountBefore := evmNode.RequestCount()
+			_, errs = callConcurrently(tokenAddresses)
+			for i, err := range errs {
+				Expect(err).ToNot(HaveOccurred(), "call %d should not fail", i)
+			}
+
+			Expect(evmNode.ETHCallCount()-callCountBefore).To(Equal(len(tokenAddresses)), "no further multicalls should be attempted against the node")
+			Expect(evmNode.RequestCount()-requestCountBefore).To(Equal(1), "the individual calls should have been sent in a single batch")
+		})
+	})
+})
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 
@@ -29,6 +30,9 @@ type ResponseError struct {
 	Message string `json:"message"`
 }
 
+// ErrNullResult is returned when an RPC call that is expected to return an object returns null (e.g., when a requested block does not exist).
+var ErrNullResult = errors.New("RPC call returned a null result")
+
 type RPCCallError struct {
 	Code    int64
 	Message string
@@ -48,19 +52,59 @@ func (e *RPCCallError) Error() string {
 // ExecuteRequest executes the given RPC request and handles error checking.
 // This can return RPCCallError if the RPC call returned an error in the JSON response.
 func ExecuteRequest(ctx context.Context, doer synchttp.Doer, requestURL string, rpcRequest *Request) (*Response, error) {
+	var result *Response
+	if err := executeRequest(ctx, doer, requestURL, rpcRequest, &result); err != nil {
+		return nil, err
+	}
+
+	if result.Error.Code != 0 {
+		return nil, NewRPCCallError(result.Error.Code, result.Error.Message)
+	}
+
+	return result, nil
+}
+
+// ExecuteObjectRequest executes the given RPC request, whose result is expected to be a JSON object, and unmarshals the result into the given value.
+// This can return RPCCallError if the RPC call returned an error in the JSON response, and ErrNullResult if the result is null.
+func ExecuteObjectRequest(ctx context.Context, doer synchttp.Doer, requestURL string, rpcRequest *Request, result any) error {
+	var response struct {
+		Result json.RawMessage `json:"result"`
+		Error  ResponseError   `json:"error"`
+	}
+	if err := executeRequest(ctx, doer, requestURL, rpcRequest, &response); err != nil {
+		return err
+	}
+
+	if response.Error.Code != 0 {
+		return NewRPCCallError(response.Error.Code, response.Error.Message)
+	}
+
+	if len(response.Result) == 0 || string(response.Result) == "null" {
+		return ErrNullResult
+	}
+
+	if unmarshalErr := json.Unmarshal(response.Result, result); unmarshalErr != nil {
+		return fmt.Errorf("failed to unmarshal result: %w", unmarshalErr)
+	}
+
+	return nil
+}
+
+// executeRequest executes the given RPC request, unmarshaling the response body into the given value.
+func executeRequest(ctx context.Context, doer synchttp.Doer, requestURL string, rpcRequest *Request, responseBody any) error {
 	requestBodyBytes, err := json.Marshal(rpcRequest)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal JSON request body: %w", err)
+		return fmt.Errorf("failed to marshal JSON request body: %w", err)
 	}
 
 	request, err := http.NewRequestWithContext(ctx, http.MethodPost, requestURL, bytes.NewBuffer(requestBodyBytes))
 	if err != nil {
-		return nil, fmt.Errorf("failed to build request: %w", err)
+		return fmt.Errorf("failed to build request: %w", err)
 	}
 
 	response, err := doer.Do(request)
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %w", err)
+		return fmt.Errorf("failed to execute request: %w", err)
 	}
 	defer func() {
 		_ = response.Body.Close()
@@ -68,17 +112,12 @@ func ExecuteRequest(ctx context.Context, doer synchttp.Doer, requestURL string,
 
 	if response.StatusCode != http.StatusOK {
 		statusErr := synchttp.BuildUnexpectedStatusErr(response)
-		return nil, statusErr
+		return statusErr
 	}
 
-	var result *Response
-	if unmarshalErr := json.NewDecoder(response.Body).Decode(&result); unmarshalErr != nil {
-		return nil, fmt.Errorf("failed to unmarshal response body: %w", unmarshalErr)
+	if unmarshalErr := json.NewDecoder(response.Body).Decode(responseBody); unmarshalErr != nil {
+		return fmt.Errorf("failed to unmarshal response body: %w", unmarshalErr)
 	}
 
-	if result.Error.Code != 0 {
-		return nil, NewRPCCallError(result.Error.Code, result.Error.Message)
-	}
-
-	return result, nil
+	return nil
 }
@@ -2,18 +2,18 @@ package rpc
 
 import (
 	"context"
-	"errors"
+	"encoding/hex"
 	"fmt"
 	"math/big"
-	"strings"
 
-	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/jrh3k5/cryptonabber-sync/v3/evm/abi"
 	synchttp "github.com/jrh3k5/cryptonabber-sync/v3/http"
 )
 
+// EthCallArgument is an argument to be supplied to a function invoked via eth_call.
 type EthCallArgument struct {
-	Type  string
-	Value any
+	Type  string // the Solidity type of the argument (e.g., "address", "uint256", "(address,bytes)")
+	Value any    // the value of the argument; see abi.Encode for the supported Go types
 }
 
 func Arg(argType string, v any) *EthCallArgument {
@@ -23,41 +23,62 @@ func Arg(argType string, v any) *EthCallArgument {
 	}
 }
 
-// ExecuteEthCallNoArg calls the given method (assuming to accept no arguments) against the given contract address.
+// EncodeEthCallData builds the 0x-prefixed calldata for invoking the given method with the given arguments.
+func EncodeEthCallData(methodName string, args ...*EthCallArgument) (string, error) {
+	argTypes := make([]abi.Type, len(args))
+	argValues := make([]any, len(args))
+
+	for i, arg := range args {
+		argType, err := abi.ParseType(arg.Type)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse type of argument %d: %w", i, err)
+		}
+
+		argTypes[i] = argType
+		argValues[i] = arg.Value
+	}
+
+	callData, err := abi.EncodeCall(methodName, argTypes, argValues)
+	if err != nil {
+		return "", err
+	}
+
+	return "0x" + hex.EncodeToString(callData), nil
+}
+
+// ExecuteEthCall calls the given method with the given arguments against the given contract address as of the given block.
+// If the given block number is nil, the call is made against the latest block.
+// The raw, hex-encoded result is returned; it can be decoded using abi.DecodeHex.
 func ExecuteEthCall(
 	ctx context.Context,
 	doer synchttp.Doer,
 	nodeURL string,
+	blockNumber *big.Int,
 	methodName string,
 	contractAddress string,
 	args ...*EthCallArgument,
 ) (string, error) {
-	if len(args) > 1 {
-		return "", errors.New("only one argument is supported for eth_call calls")
+	data, err := EncodeEthCallData(methodName, args...)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode call data: %w", err)
 	}
 
-	argTypes := make([]string, len(args))
-	argValues := make([]string, len(args))
+	return executeEthCallData(ctx, doer, nodeURL, blockNumber, contractAddress, data)
+}
 
-	for i, arg := range args {
-		argTypes[i] = arg.Type
-		switch v := arg.Value.(type) {
-		case string:
-			if argTypes[i] != "address" {
-				return "", errors.New("only address arguments are supported for eth_call string parameters")
-			}
-			argValues[i] = v[2:]
-		case *big.Int:
-			argValues[i] = fmt.Sprintf("%040x", v)
-		}
+// executeEthCallData executes an eth_call with the given, already-encoded, calldata against the given contract address as of the given block.
+func executeEthCallData(ctx context.Context, doer synchttp.Doer, nodeURL string, blockNumber *big.Int, contractAddress string, data string) (string, error) {
+	result, err := ExecuteRequest(ctx, doer, nodeURL, buildEthCallRequest(blockNumber, contractAddress, data))
+	if err != nil {
+		return "", fmt.Errorf("failed to execute request: %w", err)
 	}
 
-	data := crypto.Keccak256Hash([]byte(methodName + "(" + strings.Join(argTypes, ",") + ")")).String()[0:10]
-	if len(args) > 0 {
-		data += "000000000000000000000000" + argValues[0]
-	}
+	return result.Result, nil
+}
 
-	rpcRequest := &Request{
+// buildEthCallRequest builds the request for an eth_call with the given, already-encoded, calldata against the given contract address as of the given block.
+func buildEthCallRequest(blockNumber *big.Int, contractAddress string, data string) *Request {
+	return &Request{
 		ID:      1,
 		JSONRPC: "2.0",
 		Method:  "eth_call",
@@ -66,14 +87,7 @@ func ExecuteEthCall(
 				"to":   contractAddress,
 				"data": data,
 			},
-			"latest",
+			BlockTag(blockNumber),
 		},
 	}
-
-	result, err := ExecuteRequest(ctx, doer, nodeURL, rpcRequest)
-	if err != nil {
-		return "", fmt.Errorf("failed to execute request: %w", err)
-	}
-
-	return result.Result, nil
 }
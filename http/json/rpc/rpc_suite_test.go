@@ -0,0 +1,22 @@
+package rpc_test
+
+import (
+	"testing"
+
+	"github.com/jrh3k5/cryptonabber-sync/v3/http/json/rpc"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var evmNode *rpc.MockEVMNode
+
+func TestRPC(t *testing.T) {
+	BeforeSuite(func() {
+		evmNode = rpc.StartMockEVMNode()
+
+		DeferCleanup(evmNode.Stop)
+	})
+
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "RPC Suite")
+}
@@ -0,0 +1,95 @@
+package money
+
+import "strings"
+
+// Currency describes a fiat currency in which amounts are expressed.
+type Currency struct {
+	Code             string // the ISO 4217 code of the currency (e.g., "USD")
+	Symbol           string // the symbol with which amounts are displayed (e.g., "$"); if blank, amounts are displayed without a symbol
+	SymbolFirst      bool   // true if the symbol is displayed before amounts; false if after
+	FractionDigits   int    // the number of fractional digits in which amounts are expressed (e.g., 2 for USD)
+	DecimalSeparator string // the separator between the whole and fractional parts of amounts; if blank, "." is used
+}
+
+// USD is the United States dollar.
+var USD = Currency{
+	Code:           "USD",
+	Symbol:         "$",
+	SymbolFirst:    true,
+	FractionDigits: 2,
+}
+
+// knownCurrencies are the currencies that can be described by their ISO 4217 code alone.
+var knownCurrencies = map[string]Currency{
+	"USD": USD,
+	"EUR": {Code: "EUR", Symbol: "€", SymbolFirst: true, FractionDigits: 2},
+	"GBP": {Code: "GBP", Symbol: "£", SymbolFirst: true, FractionDigits: 2},
+	"CAD": {Code: "CAD", Symbol: "CA$", SymbolFirst: true, FractionDigits: 2},
+	"AUD": {Code: "AUD", Symbol: "A$", SymbolFirst: true, FractionDigits: 2},
+	"NZD": {Code: "NZD", Symbol: "NZ$", SymbolFirst: true, FractionDigits: 2},
+	"CHF": {Code: "CHF", Symbol: "CHF", SymbolFirst: true, FractionDigits: 2},
+	"JPY": {Code: "JPY", Symbol: "¥", SymbolFirst: true, FractionDigits: 0},
+	"KRW": {Code: "KRW", Symbol: "₩", SymbolFirst: true, FractionDigits: 0},
+	"INR": {Code: "INR", Symbol: "₹", SymbolFirst: true, FractionDigits: 2},
+	"SEK": {Code: "SEK", Symbol: "kr", SymbolFirst: false, FractionDigits: 2},
+	"NOK": {Code: "NOK", Symbol: "kr", SymbolFirst: false, FractionDigits: 2},
+	"DKK": {Code: "DKK", Symbol: "kr", SymbolFirst: false, FractionDigits: 2},
+}
+
+// CurrencyForCode describes the currency with the given ISO 4217 code.
+// Currencies that are not known are displayed using their code as their symbol, with two fractional digits.
+func CurrencyForCode(code string) Currency {
+	code = strings.ToUpper(code)
+	if currency, isKnown := knownCurrencies[code]; isKnown {
+		return currency
+	}
+
+	return Currency{
+		Code:           code,
+		Symbol:         code,
+		SymbolFirst:    false,
+		FractionDigits: 2,
+	}
+}
+
+// QuoteCode returns the code used to request quotes in this currency from price providers (e.g., "usd").
+func (c Currency) QuoteCode() string {
+	return strings.ToLower(c.Code)
+}
+
+// Format renders the given amount, rounded to this currency's fractional digits, with this currency's symbol.
+func (c Currency) Format(amount Decimal) string {
+	return c.withSymbol(amount, amount.Abs().FloatString(c.FractionDigits))
+}
+
+// FormatRate renders the given per-token rate with this currency's symbol.
+// Rates of less than one currency unit are rendered in full so that the significant digits of low-priced tokens are not lost.
+func (c Currency) FormatRate(rate Decimal) string {
+	if rate.Abs().Cmp(NewDecimalFromInt(1)) < 0 {
+		return c.withSymbol(rate, rate.Abs().String())
+	}
+
+	return c.Format(rate)
+}
+
+// withSymbol decorates the given rendering of the absolute value of the given amount with its sign and this currency's symbol and decimal separator.
+func (c Currency) withSymbol(amount Decimal, absoluteAmount string) string {
+	if c.DecimalSeparator != "" && c.DecimalSeparator != "." {
+		absoluteAmount = strings.Replace(absoluteAmount, ".", c.DecimalSeparator, 1)
+	}
+
+	var sign string
+	if amount.Sign() < 0 && strings.ContainsAny(absoluteAmount, "123456789") {
+		// Amounts that round to zero are not rendered as negative
+		sign = "-"
+	}
+
+	switch {
+	case c.Symbol == "":
+		return sign + absoluteAmount
+	case c.SymbolFirst:
+		return sign + c.Symbol + absoluteAmount
+	default:
+		return sign + absoluteAmount + " " + c.Symbol
+	}
+}
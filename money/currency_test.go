@@ -0,0 +1,46 @@
+package money_test
+
+import (
+	"github.com/jrh3k5/cryptonabber-sync/v3/money"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Currency", func() {
+	mustParse := func(s string) money.Decimal {
+		decimal, err := money.ParseDecimal(s)
+		Expect(err).ToNot(HaveOccurred(), "parsing the decimal '%s' should not fail", s)
+		return decimal
+	}
+
+	Context("CurrencyForCode", func() {
+		It("describes known currencies", func() {
+			Expect(money.CurrencyForCode("eur")).To(Equal(money.Currency{Code: "EUR", Symbol: "€", SymbolFirst: true, FractionDigits: 2}), "the euro should be described")
+		})
+
+		It("describes unknown currencies using their code", func() {
+			Expect(money.CurrencyForCode("xyz")).To(Equal(money.Currency{Code: "XYZ", Symbol: "XYZ", FractionDigits: 2}), "the unknown currency should be described by its code")
+		})
+	})
+
+	It("provides a lowercase quote code", func() {
+		Expect(money.CurrencyForCode("CAD").QuoteCode()).To(Equal("cad"), "the quote code should be lowercase")
+	})
+
+	DescribeTable("Format", func(currency money.Currency, amount string, expected string) {
+		Expect(currency.Format(mustParse(amount))).To(Equal(expected), "the amount should be formatted")
+	},
+		Entry("symbol first", money.USD, "1234.565", "$1234.57"),
+		Entry("negative amount", money.USD, "-12.5", "-$12.50"),
+		Entry("negative amount that rounds to zero", money.USD, "-0.001", "$0.00"),
+		Entry("symbol last with a decimal separator", money.Currency{Code: "EUR", Symbol: "€", FractionDigits: 2, DecimalSeparator: ","}, "9.99", "9,99 €"),
+		Entry("no fractional digits", money.CurrencyForCode("JPY"), "1500.5", "¥1501"),
+		Entry("no symbol", money.Currency{Code: "CAD", FractionDigits: 2}, "3", "3.00"))
+
+	DescribeTable("FormatRate", func(currency money.Currency, rate string, expected string) {
+		Expect(currency.FormatRate(mustParse(rate))).To(Equal(expected), "the rate should be formatted")
+	},
+		Entry("rate above one unit", money.USD, "2999.456", "$2999.46"),
+		Entry("rate below one unit", money.USD, "0.00001234", "$0.00001234"),
+		Entry("rate below one unit with a decimal separator", money.Currency{Code: "EUR", Symbol: "€", FractionDigits: 2, DecimalSeparator: ","}, "0.5", "0,5 €"))
+})
@@ -0,0 +1,181 @@
+package money
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// milliunitDigits is the number of fractional digits in a YNAB milliunit amount.
+const milliunitDigits = 3
+
+// maxStringDigits is the maximum number of fractional digits rendered by String for values that have no finite decimal representation.
+const maxStringDigits = 18
+
+// Decimal is an arbitrary-precision decimal amount, such as a price or a fiat value.
+// It is backed by a big.Rat, so arithmetic on it is exact; precision is only ever lost
+// when a Decimal is rounded for presentation or storage.
+//
+// All rounding performed by Decimal uses the "round half away from zero" mode:
+// values are rounded to the nearest representable value and, when exactly halfway
+// between two values, are rounded to the one further from zero (e.g., 1.005 -> 1.01, -1.005 -> -1.01).
+//
+// The zero value of Decimal is a usable zero.
+type Decimal struct {
+	value *big.Rat
+}
+
+// Zero returns a Decimal of zero.
+func Zero() Decimal {
+	return Decimal{value: new(big.Rat)}
+}
+
+// ParseDecimal parses the given string (e.g., "123", "-0.0045", "1.2e-7") into a Decimal.
+func ParseDecimal(s string) (Decimal, error) {
+	value, isValid := new(big.Rat).SetString(strings.TrimSpace(s))
+	if !isValid {
+		return Decimal{}, fmt.Errorf("invalid decimal value: '%s'", s)
+	}
+
+	return Decimal{value: value}, nil
+}
+
+// NewDecimalFromRat builds a Decimal from the given rational value.
+func NewDecimalFromRat(r *big.Rat) Decimal {
+	return Decimal{value: new(big.Rat).Set(r)}
+}
+
+// NewDecimalFromInt builds a Decimal from the given whole number.
+func NewDecimalFromInt(i int64) Decimal {
+	return Decimal{value: new(big.Rat).SetInt64(i)}
+}
+
+// NewDecimalFromUnits builds a Decimal from an amount expressed in indivisible units with the given number of decimals
+// (e.g., 1500000 units with 6 decimals is 1.5).
+func NewDecimalFromUnits(units *big.Int, decimals int) Decimal {
+	return Decimal{value: new(big.Rat).SetFrac(units, pow10(decimals))}
+}
+
+// NewDecimalFromMilliunits builds a Decimal from an amount expressed in YNAB milliunits.
+func NewDecimalFromMilliunits(milliunits int64) Decimal {
+	return NewDecimalFromUnits(big.NewInt(milliunits), milliunitDigits)
+}
+
+// Add returns the sum of this and the given Decimal.
+func (d Decimal) Add(other Decimal) Decimal {
+	return Decimal{value: new(big.Rat).Add(d.rat(), other.rat())}
+}
+
+// Sub returns the difference of this and the given Decimal.
+func (d Decimal) Sub(other Decimal) Decimal {
+	return Decimal{value: new(big.Rat).Sub(d.rat(), other.rat())}
+}
+
+// Mul returns the product of this and the given Decimal.
+func (d Decimal) Mul(other Decimal) Decimal {
+	return Decimal{value: new(big.Rat).Mul(d.rat(), other.rat())}
+}
+
+// Neg returns the negation of this Decimal.
+func (d Decimal) Neg() Decimal {
+	return Decimal{value: new(big.Rat).Neg(d.rat())}
+}
+
+// Abs returns the absolute value of this Decimal.
+func (d Decimal) Abs() Decimal {
+	return Decimal{value: new(big.Rat).Abs(d.rat())}
+}
+
+// Cmp compares this Decimal to the given Decimal, returning -1, 0, or +1 if this is less than, equal to, or greater than the other value.
+func (d Decimal) Cmp(other Decimal) int {
+	return d.rat().Cmp(other.rat())
+}
+
+// Sign returns -1, 0, or +1 depending on whether this Decimal is negative, zero, or positive.
+func (d Decimal) Sign() int {
+	return d.rat().Sign()
+}
+
+// IsZero returns true if this Decimal is zero.
+func (d Decimal) IsZero() bool {
+	return d.Sign() == 0
+}
+
+// Rat returns a copy of the rational value of this Decimal.
+func (d Decimal) Rat() *big.Rat {
+	return new(big.Rat).Set(d.rat())
+}
+
+// Round rounds this Decimal to the given number of fractional digits, using the "round half away from zero" mode.
+func (d Decimal) Round(fractionDigits int) Decimal {
+	return NewDecimalFromUnits(d.roundedUnits(fractionDigits), fractionDigits)
+}
+
+// FloatString renders this Decimal with exactly the given number of fractional digits, using the "round half away from zero" mode.
+func (d Decimal) FloatString(fractionDigits int) string {
+	return d.rat().FloatString(fractionDigits)
+}
+
+// ToMilliunits converts this Decimal into YNAB milliunits (thousandths of a currency unit).
+// The value is first rounded, using the "round half away from zero" mode, to the given number of fractional digits (e.g., 2 for USD)
+// so that the result never contains a fraction of the currency's smallest unit.
+// An error is returned if the resulting amount does not fit in a 64-bit integer.
+func (d Decimal) ToMilliunits(fractionDigits int) (int64, error) {
+	if fractionDigits > milliunitDigits {
+		fractionDigits = milliunitDigits
+	}
+
+	rounded := d.roundedUnits(fractionDigits)
+	milliunits := rounded.Mul(rounded, pow10(milliunitDigits-fractionDigits))
+	if !milliunits.IsInt64() {
+		return 0, fmt.Errorf("value %s is too large to be represented in milliunits", d.String())
+	}
+
+	return milliunits.Int64(), nil
+}
+
+// String renders this Decimal as a plain decimal string with no trailing fractional zeros.
+// Values without a finite decimal representation are rounded to 18 fractional digits.
+func (d Decimal) String() string {
+	rendered := d.rat().FloatString(maxStringDigits)
+	if strings.Contains(rendered, ".") {
+		rendered = strings.TrimRight(strings.TrimRight(rendered, "0"), ".")
+	}
+
+	if rendered == "-0" {
+		return "0"
+	}
+
+	return rendered
+}
+
+// roundedUnits rounds this Decimal to the given number of fractional digits and returns the result
+// as a count of units of 10^-fractionDigits.
+func (d Decimal) roundedUnits(fractionDigits int) *big.Int {
+	scaled := new(big.Rat).Mul(d.rat(), new(big.Rat).SetInt(pow10(fractionDigits)))
+
+	quotient, remainder := new(big.Int).QuoRem(scaled.Num(), scaled.Denom(), new(big.Int))
+	doubledRemainder := remainder.Abs(remainder)
+	doubledRemainder.Lsh(doubledRemainder, 1)
+	if doubledRemainder.Cmp(scaled.Denom()) >= 0 {
+		if scaled.Sign() < 0 {
+			quotient.Sub(quotient, big.NewInt(1))
+		} else {
+			quotient.Add(quotient, big.NewInt(1))
+		}
+	}
+
+	return quotient
+}
+
+func (d Decimal) rat() *big.Rat {
+	if d.value == nil {
+		return new(big.Rat)
+	}
+
+	return d.value
+}
+
+func pow10(exponent int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exponent)), nil)
+}
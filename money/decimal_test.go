@@ -0,0 +1,76 @@
+package money_test
+
+import (
+	"math/big"
+
+	"github.com/jrh3k5/cryptonabber-sync/v3/money"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Decimal", func() {
+	mustParse := func(s string) money.Decimal {
+		decimal, err := money.ParseDecimal(s)
+		Expect(err).ToNot(HaveOccurred(), "parsing the decimal '%s' should not fail", s)
+		return decimal
+	}
+
+	Context("ParseDecimal", func() {
+		DescribeTable("valid values", func(input string, expected string) {
+			Expect(mustParse(input).String()).To(Equal(expected), "the value should be parsed exactly")
+		},
+			Entry("whole number", "12345", "12345"),
+			Entry("fractional number", "54321.6789", "54321.6789"),
+			Entry("negative number", "-0.0045", "-0.0045"),
+			Entry("scientific notation", "1.2e-7", "0.00000012"))
+
+		It("rejects invalid values", func() {
+			_, err := money.ParseDecimal("twelve")
+			Expect(err).To(HaveOccurred(), "parsing a non-numeric value should fail")
+		})
+	})
+
+	Context("NewDecimalFromUnits", func() {
+		It("scales the units by the given decimals", func() {
+			Expect(money.NewDecimalFromUnits(big.NewInt(1500000), 6).String()).To(Equal("1.5"), "the units should be scaled")
+		})
+	})
+
+	Context("ToMilliunits", func() {
+		DescribeTable("rounding to the currency's fractional digits", func(input string, fractionDigits int, expected int64) {
+			Expect(mustParse(input).ToMilliunits(fractionDigits)).To(Equal(expected), "the value should be rounded half away from zero")
+		},
+			Entry("exact cents", "123.45", 2, int64(123450)),
+			Entry("rounding down", "123.454", 2, int64(123450)),
+			Entry("rounding half up", "123.455", 2, int64(123460)),
+			Entry("rounding negative half away from zero", "-123.455", 2, int64(-123460)),
+			Entry("currency with no fractional digits", "1234.5", 0, int64(1235000)),
+			Entry("currency with three fractional digits", "1.2345", 3, int64(1235)))
+
+		When("the value is too large to be represented in milliunits", func() {
+			It("returns an error", func() {
+				_, err := mustParse("9223372036854775.808").ToMilliunits(3)
+				Expect(err).To(HaveOccurred(), "converting a value beyond the range of a 64-bit integer should fail")
+			})
+		})
+
+		It("converts the largest representable value", func() {
+			Expect(mustParse("9223372036854775.807").ToMilliunits(3)).To(Equal(int64(9223372036854775807)), "the largest 64-bit value should be converted")
+		})
+	})
+
+	Context("Round", func() {
+		It("rounds half away from zero", func() {
+			Expect(mustParse("1.005").Round(2).String()).To(Equal("1.01"), "the value should be rounded up")
+			Expect(mustParse("-1.005").Round(2).String()).To(Equal("-1.01"), "the value should be rounded down")
+		})
+	})
+
+	Context("zero value", func() {
+		It("behaves as zero", func() {
+			var zero money.Decimal
+			Expect(zero.IsZero()).To(BeTrue(), "the zero value should be zero")
+			Expect(zero.Add(money.NewDecimalFromInt(2)).String()).To(Equal("2"), "the zero value should be usable in arithmetic")
+		})
+	})
+})
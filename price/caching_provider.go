@@ -0,0 +1,165 @@
+package price
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jrh3k5/cryptonabber-sync/v3/cache"
+	"github.com/jrh3k5/cryptonabber-sync/v3/money"
+)
+
+// CachingProvider is a BatchingProvider that caches the quotes given by another Provider for a time to live.
+//
+// Assets whose quotes are cached are not registered with the other provider, so that they are not retrieved again.
+// Assets with fixed prices, and assets that cannot be identified by chain ID, are not cached.
+// A cached quote that has grown older than the maximum quote age is not used, and is instead retrieved again.
+type CachingProvider struct {
+	delegate    Provider
+	cache       *cache.File
+	ttl         time.Duration
+	maxQuoteAge time.Duration
+}
+
+// NewCachingProvider builds a CachingProvider that caches the quotes given by the given provider in the given cache for the given time to live.
+// Cached quotes last updated longer ago than the given maximum age are not used; if the maximum age is zero, cached quotes of any age are used.
+func NewCachingProvider(delegate Provider, cache *cache.File, ttl time.Duration, maxQuoteAge time.Duration) *CachingProvider {
+	return &CachingProvider{
+		delegate:    delegate,
+		cache:       cache,
+		ttl:         ttl,
+		maxQuoteAge: maxQuoteAge,
+	}
+}
+
+func (c *CachingProvider) Name() string {
+	return c.delegate.Name()
+}
+
+func (c *CachingProvider) RegisterAsset(ctx context.Context, asset Asset, currency money.Currency) {
+	batchingProvider, isBatching := c.delegate.(BatchingProvider)
+	if !isBatching {
+		return
+	}
+
+	if cacheKey, isCacheable := quoteCacheKey(asset, currency); isCacheable {
+		if _, isCached := c.getCachedQuote(cacheKey); isCached {
+			return
+		}
+	}
+
+	batchingProvider.RegisterAsset(ctx, asset, currency)
+}
+
+func (c *CachingProvider) ResolvePrice(ctx context.Context, asset Asset, currency money.Currency) (Quote, bool, error) {
+	cacheKey, isCacheable := quoteCacheKey(asset, currency)
+	if !isCacheable {
+		return c.delegate.ResolvePrice(ctx, asset, currency)
+	}
+
+	if cached, isCached := c.getCachedQuote(cacheKey); isCached {
+		return cached, true, nil
+	}
+
+	return c.retrieveQuote(ctx, cacheKey, asset, currency)
+}
+
+// Uncached gets a view of this provider that does not use cached quotes, but still caches the quotes that it retrieves.
+func (c *CachingProvider) Uncached() BatchingProvider {
+	return &uncachedProvider{
+		caching: c,
+	}
+}
+
+// retrieveQuote retrieves the quote of the given asset from the delegate provider and caches it under the given key.
+func (c *CachingProvider) retrieveQuote(ctx context.Context, cacheKey string, asset Asset, currency money.Currency) (Quote, bool, error) {
+	quote, hasQuote, err := c.delegate.ResolvePrice(ctx, asset, currency)
+	if err != nil || !hasQuote {
+		return quote, hasQuote, err
+	}
+
+	// The quote is returned even if it cannot be cached
+	_ = c.cache.Set(cacheKey, cachedQuote{
+		Price:     quote.Price.String(),
+		UpdatedAt: quote.UpdatedAt,
+	}, c.ttl)
+
+	return quote, true, nil
+}
+
+// getCachedQuote gets the quote cached under the given key.
+// The returned bool is false if no usable quote is cached, including if the cached quote is older than the maximum quote age.
+func (c *CachingProvider) getCachedQuote(cacheKey string) (Quote, bool) {
+	var cached cachedQuote
+	if !c.cache.Get(cacheKey, &cached) {
+		return Quote{}, false
+	}
+
+	if c.maxQuoteAge > 0 && !cached.UpdatedAt.IsZero() && time.Since(cached.UpdatedAt) > c.maxQuoteAge {
+		return Quote{}, false
+	}
+
+	price, err := money.ParseDecimal(cached.Price)
+	if err != nil {
+		return Quote{}, false
+	}
+
+	return Quote{
+		Price:     price,
+		UpdatedAt: cached.UpdatedAt,
+	}, true
+}
+
+// quoteCacheKey gets the key under which the quote of the given asset in the given currency is cached.
+// The returned bool is false if the asset's quote is not to be cached.
+func quoteCacheKey(asset Asset, currency money.Currency) (string, bool) {
+	if asset.FixedPrice != nil {
+		return "", false
+	}
+
+	if asset.CoinID != "" {
+		return fmt.Sprintf("quote/%s/coin/%s", currency.Code, asset.CoinID), true
+	}
+
+	// Assets are cached by chain ID, not chain name
+	if asset.ChainID == nil {
+		return "", false
+	}
+
+	contractAddress := "native"
+	if asset.ContractAddress != nil {
+		contractAddress = strings.ToLower(*asset.ContractAddress)
+	}
+
+	return fmt.Sprintf("quote/%s/%v/%s", currency.Code, asset.ChainID, contractAddress), true
+}
+
+// uncachedProvider is a view of a CachingProvider that always retrieves quotes from the CachingProvider's delegate.
+type uncachedProvider struct {
+	caching *CachingProvider
+}
+
+func (u *uncachedProvider) Name() string {
+	return u.caching.Name()
+}
+
+func (u *uncachedProvider) RegisterAsset(ctx context.Context, asset Asset, currency money.Currency) {
+	if batchingProvider, isBatching := u.caching.delegate.(BatchingProvider); isBatching {
+		batchingProvider.RegisterAsset(ctx, asset, currency)
+	}
+}
+
+func (u *uncachedProvider) ResolvePrice(ctx context.Context, asset Asset, currency money.Currency) (Quote, bool, error) {
+	cacheKey, isCacheable := quoteCacheKey(asset, currency)
+	if !isCacheable {
+		return u.caching.delegate.ResolvePrice(ctx, asset, currency)
+	}
+
+	return u.caching.retrieveQuote(ctx, cacheKey, asset, currency)
+}
+
+type cachedQuote struct {
+	Price     string    `json:"price"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
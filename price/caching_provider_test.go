@@ -0,0 +1,100 @@
+package price_test
+
+import (
+	"context"
+	"math/big"
+	"path/filepath"
+	"time"
+
+	"github.com/jrh3k5/cryptonabber-sync/v3/cache"
+	"github.com/jrh3k5/cryptonabber-sync/v3/money"
+	"github.com/jrh3k5/cryptonabber-sync/v3/price"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CachingProvider", func() {
+	var ctx context.Context
+	var cacheFile string
+	var asset price.Asset
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		cacheFile = filepath.Join(GinkgoT().TempDir(), "cache.json")
+
+		contractAddress := "0xCACHED"
+		asset = price.Asset{
+			ChainName:       chainName,
+			ChainID:         big.NewInt(1),
+			ContractAddress: &contractAddress,
+		}
+	})
+
+	It("reuses cached quotes across runs without registering their assets", func() {
+		delegate := &stubBatchingProvider{stubProvider: stubProvider{name: "batching", price: "1.25", updatedAt: time.Unix(1700000000, 0)}}
+
+		firstCache := cache.LoadFile(cacheFile)
+		firstProvider := price.NewCachingProvider(delegate, firstCache, time.Hour, 0)
+		firstProvider.RegisterAsset(ctx, asset, money.USD)
+
+		_, hasRate, err := firstProvider.ResolvePrice(ctx, asset, money.USD)
+		Expect(err).ToNot(HaveOccurred(), "resolving the price should not fail")
+		Expect(hasRate).To(BeTrue(), "a price should be found")
+		Expect(firstCache.Save()).To(Succeed(), "saving the cache should not fail")
+
+		secondProvider := price.NewCachingProvider(delegate, cache.LoadFile(cacheFile), time.Hour, 0)
+		secondProvider.RegisterAsset(ctx, asset, money.USD)
+
+		rate, hasRate, err := secondProvider.ResolvePrice(ctx, asset, money.USD)
+		Expect(err).ToNot(HaveOccurred(), "resolving the cached price should not fail")
+		Expect(hasRate).To(BeTrue(), "the cached price should be found")
+		Expect(rate.Price.String()).To(Equal("1.25"), "the cached price should be used")
+		Expect(rate.UpdatedAt).To(BeTemporally("==", time.Unix(1700000000, 0)), "the time at which the cached price was updated should be kept")
+
+		Expect(delegate.resolveCount).To(Equal(1), "the price should only be resolved once")
+		Expect(delegate.registered).To(HaveLen(1), "the asset should only be registered while it was not cached")
+	})
+
+	It("retrieves a cached quote again once it is older than the maximum quote age", func() {
+		delegate := &stubBatchingProvider{stubProvider: stubProvider{name: "batching", price: "1.25", updatedAt: time.Now().Add(-2 * time.Hour)}}
+
+		firstCache := cache.LoadFile(cacheFile)
+		_, _, err := price.NewCachingProvider(delegate, firstCache, 24*time.Hour, 0).ResolvePrice(ctx, asset, money.USD)
+		Expect(err).ToNot(HaveOccurred(), "resolving the price should not fail")
+		Expect(firstCache.Save()).To(Succeed(), "saving the cache should not fail")
+
+		secondProvider := price.NewCachingProvider(delegate, cache.LoadFile(cacheFile), 24*time.Hour, time.Hour)
+		secondProvider.RegisterAsset(ctx, asset, money.USD)
+		_, _, err = secondProvider.ResolvePrice(ctx, asset, money.USD)
+		Expect(err).ToNot(HaveOccurred(), "resolving the price again should not fail")
+
+		Expect(delegate.resolveCount).To(Equal(2), "the stale cached quote should not be used")
+		Expect(delegate.registered).To(HaveLen(1), "the asset of the stale cached quote should be registered to be retrieved again")
+	})
+
+	It("does not share quotes between currencies", func() {
+		delegate := &stubProvider{name: "plain", price: "1"}
+		provider := price.NewCachingProvider(delegate, cache.LoadFile(cacheFile), time.Hour, 0)
+
+		for _, currency := range []money.Currency{money.USD, money.CurrencyForCode("EUR")} {
+			_, _, err := provider.ResolvePrice(ctx, asset, currency)
+			Expect(err).ToNot(HaveOccurred(), "resolving the price in %s should not fail", currency.Code)
+		}
+
+		Expect(delegate.resolveCount).To(Equal(2), "the price should be resolved in each currency")
+	})
+
+	It("does not cache assets with fixed prices", func() {
+		fixedPrice := money.NewDecimalFromInt(1)
+		asset.FixedPrice = &fixedPrice
+		delegate := &stubProvider{name: "plain", price: "1"}
+		provider := price.NewCachingProvider(delegate, cache.LoadFile(cacheFile), time.Hour, 0)
+
+		for range 2 {
+			_, _, err := provider.ResolvePrice(ctx, asset, money.USD)
+			Expect(err).ToNot(HaveOccurred(), "resolving the price should not fail")
+		}
+
+		Expect(delegate.resolveCount).To(Equal(2), "the delegate should be consulted each time")
+	})
+})
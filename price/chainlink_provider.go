@@ -0,0 +1,103 @@
+package price
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/jrh3k5/cryptonabber-sync/v3/config"
+	"github.com/jrh3k5/cryptonabber-sync/v3/config/chain"
+	priceconfig "github.com/jrh3k5/cryptonabber-sync/v3/config/price"
+	rpcconfig "github.com/jrh3k5/cryptonabber-sync/v3/config/rpc"
+	"github.com/jrh3k5/cryptonabber-sync/v3/evm/abi"
+	"github.com/jrh3k5/cryptonabber-sync/v3/http/json/rpc"
+	"github.com/jrh3k5/cryptonabber-sync/v3/money"
+	"github.com/jrh3k5/cryptonabber-sync/v3/token"
+)
+
+// ChainlinkProvider is a Provider that prices assets using the answers of Chainlink price feeds (i.e., AggregatorV3Interface contracts).
+//
+// Only assets for which a feed has been configured are priced, and only in the currency in which the feed is denominated.
+// Feeds are identified by the chain name and address of the asset, so assets priced by coin ID or on chains without an RPC configuration
+// are not priced.
+type ChainlinkProvider struct {
+	rpcConfigurationResolver rpcconfig.ConfigurationResolver
+	ethCaller                rpc.EthCaller
+	feeds                    map[assetKey]priceconfig.ChainlinkFeed
+}
+
+// NewChainlinkProvider builds a ChainlinkProvider that prices assets using the given feeds.
+func NewChainlinkProvider(rpcConfigurationResolver rpcconfig.ConfigurationResolver, ethCaller rpc.EthCaller, feeds []priceconfig.ChainlinkFeed) *ChainlinkProvider {
+	feedsByAsset := make(map[assetKey]priceconfig.ChainlinkFeed, len(feeds))
+	for _, feed := range feeds {
+		feedsByAsset[newAssetKey(feed.ChainName, feed.TokenAddress)] = feed
+	}
+
+	return &ChainlinkProvider{
+		rpcConfigurationResolver: rpcConfigurationResolver,
+		ethCaller:                ethCaller,
+		feeds:                    feedsByAsset,
+	}
+}
+
+func (*ChainlinkProvider) Name() string {
+	return "Chainlink"
+}
+
+func (c *ChainlinkProvider) ResolvePrice(ctx context.Context, asset Asset, currency money.Currency) (Quote, bool, error) {
+	if asset.CoinID != "" || asset.ChainName == "" {
+		return Quote{}, false, nil
+	}
+
+	feed, hasFeed := c.feeds[assetKeyOf(asset)]
+	if !hasFeed {
+		return Quote{}, false, nil
+	}
+
+	feedCurrency := money.USD.Code
+	if feed.Currency != "" {
+		feedCurrency = strings.ToUpper(feed.Currency)
+	}
+
+	if feedCurrency != currency.Code {
+		return Quote{}, false, nil
+	}
+
+	rpcURL, err := token.ResolveRPCURL(ctx, c.rpcConfigurationResolver, config.OnchainAsset{ChainName: asset.ChainName}, chain.TypeEVM)
+	if err != nil {
+		return Quote{}, false, fmt.Errorf("failed to resolve RPC URL: %w", err)
+	}
+
+	decimalsResult, err := c.ethCaller.EthCall(ctx, rpcURL, "decimals", feed.FeedAddress)
+	if err != nil {
+		return Quote{}, false, fmt.Errorf("failed to get decimals of feed '%s': %w", feed.FeedAddress, err)
+	}
+
+	decodedDecimals, err := abi.DecodeHex([]string{"uint8"}, decimalsResult)
+	if err != nil {
+		return Quote{}, false, fmt.Errorf("failed to decode decimals of feed '%s': %w", feed.FeedAddress, err)
+	}
+
+	roundDataResult, err := c.ethCaller.EthCall(ctx, rpcURL, "latestRoundData", feed.FeedAddress)
+	if err != nil {
+		return Quote{}, false, fmt.Errorf("failed to get latest round data of feed '%s': %w", feed.FeedAddress, err)
+	}
+
+	// roundId, answer, startedAt, updatedAt, answeredInRound
+	decodedRoundData, err := abi.DecodeHex([]string{"uint80", "int256", "uint256", "uint256", "uint80"}, roundDataResult)
+	if err != nil {
+		return Quote{}, false, fmt.Errorf("failed to decode latest round data of feed '%s': %w", feed.FeedAddress, err)
+	}
+
+	answer := decodedRoundData[1].(*big.Int)
+	if answer.Sign() <= 0 {
+		return Quote{}, false, fmt.Errorf("feed '%s' answered with a non-positive price: %v", feed.FeedAddress, answer)
+	}
+
+	return Quote{
+		Price:     money.NewDecimalFromUnits(answer, int(decodedDecimals[0].(*big.Int).Int64())),
+		UpdatedAt: time.Unix(decodedRoundData[3].(*big.Int).Int64(), 0),
+	}, true, nil
+}
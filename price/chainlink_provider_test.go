@@ -0,0 +1,78 @@
+package price_test
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"time"
+
+	priceconfig "github.com/jrh3k5/cryptonabber-sync/v3/config/price"
+	"github.com/jrh3k5/cryptonabber-sync/v3/http/json/rpc"
+	"github.com/jrh3k5/cryptonabber-sync/v3/money"
+	"github.com/jrh3k5/cryptonabber-sync/v3/price"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ChainlinkProvider", func() {
+	var ctx context.Context
+	var provider *price.ChainlinkProvider
+
+	tokenAddress := "0x514910771AF9Ca656af840dff83E8264EcF986CA"
+	feedAddress := "0x2c1d072e956AFFC0D435Cb7AC38EF18d24d9127c"
+	nativeFeedAddress := "0x5f4eC3Df9cbd43714FE2740f5E3616155c5b8419"
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		provider = price.NewChainlinkProvider(rpcConfigurationResolver, rpc.NewDirectEthCaller(http.DefaultClient, rpc.NewLatestBlockResolver()), []priceconfig.ChainlinkFeed{
+			{
+				ChainName:    chainName,
+				TokenAddress: tokenAddress,
+				FeedAddress:  feedAddress,
+			},
+			{
+				ChainName:   chainName,
+				FeedAddress: nativeFeedAddress,
+			},
+		})
+
+		for address, answer := range map[string]*big.Int{feedAddress: big.NewInt(1523456789), nativeFeedAddress: big.NewInt(345678000000)} {
+			evmNode.RegisterETHCallCall("decimals", address, nil, func(_ string, _ []string) (rpc.MockEVMNodeRPCResult, *rpc.MockEVMNodeRPCError, error) {
+				return rpc.NewMockEVMNodeRPCNumericResult(big.NewInt(8)), nil, nil
+			})
+
+			evmNode.RegisterETHCallCall("latestRoundData", address, nil, func(_ string, _ []string) (rpc.MockEVMNodeRPCResult, *rpc.MockEVMNodeRPCError, error) {
+				return rpc.NewMockEVMNodeRPCABIResult([]string{"uint80", "int256", "uint256", "uint256", "uint80"}, big.NewInt(1), answer, big.NewInt(1700000000), big.NewInt(1700000000), big.NewInt(1)), nil, nil
+			})
+		}
+	})
+
+	It("prices a token using its feed", func() {
+		rate, hasRate, err := provider.ResolvePrice(ctx, price.Asset{ChainName: chainName, ChainID: big.NewInt(1), ContractAddress: &tokenAddress}, money.USD)
+		Expect(err).ToNot(HaveOccurred(), "resolving the price should not fail")
+		Expect(hasRate).To(BeTrue(), "a price should be found")
+		Expect(rate.Price.String()).To(Equal("15.23456789"), "the answer should be scaled by the feed's decimals")
+		Expect(rate.UpdatedAt).To(Equal(time.Unix(1700000000, 0)), "the time at which the answer was updated should be used")
+	})
+
+	It("prices a native coin using its feed", func() {
+		rate, hasRate, err := provider.ResolvePrice(ctx, price.Asset{ChainName: chainName, ChainID: big.NewInt(1)}, money.USD)
+		Expect(err).ToNot(HaveOccurred(), "resolving the price should not fail")
+		Expect(hasRate).To(BeTrue(), "a price should be found")
+		Expect(rate.Price.String()).To(Equal("3456.78"), "the answer should be scaled by the feed's decimals")
+	})
+
+	It("does not price an asset without a feed", func() {
+		otherAddress := "0x0000000000000000000000000000000000000001"
+		_, hasRate, err := provider.ResolvePrice(ctx, price.Asset{ChainName: chainName, ChainID: big.NewInt(1), ContractAddress: &otherAddress}, money.USD)
+		Expect(err).ToNot(HaveOccurred(), "resolving the price should not fail")
+		Expect(hasRate).To(BeFalse(), "no price should be found")
+	})
+
+	It("does not price an asset in a currency other than that of its feed", func() {
+		_, hasRate, err := provider.ResolvePrice(ctx, price.Asset{ChainName: chainName, ChainID: big.NewInt(1), ContractAddress: &tokenAddress}, money.CurrencyForCode("EUR"))
+		Expect(err).ToNot(HaveOccurred(), "resolving the price should not fail")
+		Expect(hasRate).To(BeFalse(), "no price should be found")
+	})
+})
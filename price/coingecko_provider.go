@@ -0,0 +1,89 @@
+package price
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jrh3k5/cryptonabber-sync/v3/coingecko"
+	"github.com/jrh3k5/cryptonabber-sync/v3/money"
+)
+
+// coingeckoQuoteRegistrar describes a coingecko.QuoteResolver that can be told ahead of time which quotes will be resolved.
+type coingeckoQuoteRegistrar interface {
+	RegisterCoin(coinID string, currency money.Currency)
+	RegisterToken(assetPlatformID string, contractAddress string, currency money.Currency)
+}
+
+// CoingeckoProvider is a BatchingProvider that prices assets using Coingecko's quotes.
+type CoingeckoProvider struct {
+	quoteResolver           coingecko.QuoteResolver
+	assetPlatformIDResolver coingecko.AssetPlatformIDResolver
+	nativeCoinIDResolver    coingecko.NativeCoinIDResolver
+}
+
+// NewCoingeckoProvider builds a CoingeckoProvider.
+// If the given QuoteResolver supports the registration of quotes ahead of time (as coingecko.BatchedQuoteResolver does),
+// registered assets are registered with it.
+func NewCoingeckoProvider(quoteResolver coingecko.QuoteResolver, assetPlatformIDResolver coingecko.AssetPlatformIDResolver, nativeCoinIDResolver coingecko.NativeCoinIDResolver) *CoingeckoProvider {
+	return &CoingeckoProvider{
+		quoteResolver:           quoteResolver,
+		assetPlatformIDResolver: assetPlatformIDResolver,
+		nativeCoinIDResolver:    nativeCoinIDResolver,
+	}
+}
+
+func (*CoingeckoProvider) Name() string {
+	return "Coingecko"
+}
+
+func (c *CoingeckoProvider) RegisterAsset(ctx context.Context, asset Asset, currency money.Currency) {
+	registrar, isRegistrar := c.quoteResolver.(coingeckoQuoteRegistrar)
+	if !isRegistrar {
+		return
+	}
+
+	// Registration is only an optimization; an asset that cannot be registered will fail when it is priced
+	if asset.CoinID != "" {
+		registrar.RegisterCoin(asset.CoinID, currency)
+	} else if asset.ContractAddress == nil {
+		if nativeCoinID, err := c.nativeCoinIDResolver.ResolveNativeCoinIDForChainID(ctx, asset.ChainID); err == nil {
+			registrar.RegisterCoin(nativeCoinID, currency)
+		}
+	} else if assetPlatformID, err := c.assetPlatformIDResolver.ResolveForChainID(ctx, asset.ChainID); err == nil {
+		registrar.RegisterToken(assetPlatformID, *asset.ContractAddress, currency)
+	}
+}
+
+func (c *CoingeckoProvider) ResolvePrice(ctx context.Context, asset Asset, currency money.Currency) (Quote, bool, error) {
+	if asset.CoinID != "" {
+		return toQuote(c.quoteResolver.ResolveCoinQuote(ctx, asset.CoinID, currency))
+	}
+
+	if asset.ContractAddress == nil {
+		nativeCoinID, err := c.nativeCoinIDResolver.ResolveNativeCoinIDForChainID(ctx, asset.ChainID)
+		if err != nil {
+			return Quote{}, false, fmt.Errorf("failed to resolve native coin ID: %w", err)
+		}
+
+		return toQuote(c.quoteResolver.ResolveCoinQuote(ctx, nativeCoinID, currency))
+	}
+
+	assetPlatformID, err := c.assetPlatformIDResolver.ResolveForChainID(ctx, asset.ChainID)
+	if err != nil {
+		return Quote{}, false, fmt.Errorf("failed to resolve asset platform ID: %w", err)
+	}
+
+	return toQuote(c.quoteResolver.ResolveQuote(ctx, assetPlatformID, *asset.ContractAddress, currency))
+}
+
+// toQuote translates the result of resolving a Coingecko quote into the result of resolving a price.
+func toQuote(coingeckoQuote coingecko.Quote, hasQuote bool, err error) (Quote, bool, error) {
+	if err != nil || !hasQuote {
+		return Quote{}, hasQuote, err
+	}
+
+	return Quote{
+		Price:     coingeckoQuote.Price,
+		UpdatedAt: coingeckoQuote.LastUpdatedAt,
+	}, true, nil
+}
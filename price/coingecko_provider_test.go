@@ -0,0 +1,92 @@
+package price_test
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/jrh3k5/cryptonabber-sync/v3/coingecko"
+	"github.com/jrh3k5/cryptonabber-sync/v3/money"
+	"github.com/jrh3k5/cryptonabber-sync/v3/price"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CoingeckoProvider", func() {
+	var ctx context.Context
+	var quoteResolver *stubQuoteResolver
+	var provider *price.CoingeckoProvider
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		quoteResolver = &stubQuoteResolver{}
+		provider = price.NewCoingeckoProvider(quoteResolver, &stubAssetPlatformIDResolver{}, &stubNativeCoinIDResolver{})
+	})
+
+	It("quotes a token on its chain's asset platform", func() {
+		tokenAddress := "0xaaaa"
+		asset := price.Asset{ChainName: chainName, ChainID: big.NewInt(1), ContractAddress: &tokenAddress}
+
+		provider.RegisterAsset(ctx, asset, money.USD)
+		Expect(quoteResolver.registeredTokens).To(ConsistOf("ethereum/0xaaaa"), "the token should be registered on its asset platform")
+
+		rate, hasRate, err := provider.ResolvePrice(ctx, asset, money.USD)
+		Expect(err).ToNot(HaveOccurred(), "resolving the price should not fail")
+		Expect(hasRate).To(BeTrue(), "a price should be found")
+		Expect(rate.Price.String()).To(Equal("2"), "the token's quote should be used")
+		Expect(rate.UpdatedAt).To(Equal(time.Unix(1700000000, 0)), "the time at which the quote was last updated should be used")
+	})
+
+	It("quotes a native coin by its coin ID", func() {
+		asset := price.Asset{ChainName: chainName, ChainID: big.NewInt(1)}
+
+		provider.RegisterAsset(ctx, asset, money.USD)
+		Expect(quoteResolver.registeredCoins).To(ConsistOf("ethereum"), "the native coin should be registered")
+
+		rate, hasRate, err := provider.ResolvePrice(ctx, asset, money.USD)
+		Expect(err).ToNot(HaveOccurred(), "resolving the price should not fail")
+		Expect(hasRate).To(BeTrue(), "a price should be found")
+		Expect(rate.Price.String()).To(Equal("1"), "the coin's quote should be used")
+	})
+
+	It("quotes an asset with a coin ID by its coin ID", func() {
+		tokenAddress := "0xaaaa"
+		asset := price.Asset{ChainName: chainName, ChainID: big.NewInt(1), ContractAddress: &tokenAddress, CoinID: "usd-coin"}
+
+		provider.RegisterAsset(ctx, asset, money.USD)
+		Expect(quoteResolver.registeredCoins).To(ConsistOf("usd-coin"), "the coin ID should be registered")
+		Expect(quoteResolver.registeredTokens).To(BeEmpty(), "the token should not be registered")
+
+		rate, hasRate, err := provider.ResolvePrice(ctx, asset, money.USD)
+		Expect(err).ToNot(HaveOccurred(), "resolving the price should not fail")
+		Expect(hasRate).To(BeTrue(), "a price should be found")
+		Expect(rate.Price.String()).To(Equal("1"), "the coin's quote should be used")
+	})
+})
+
+type stubQuoteResolver struct {
+	registeredCoins  []string
+	registeredTokens []string
+}
+
+func (s *stubQuoteResolver) RegisterCoin(coinID string, _ money.Currency) {
+	s.registeredCoins = append(s.registeredCoins, coinID)
+}
+
+func (s *stubQuoteResolver) RegisterToken(assetPlatformID string, contractAddress string, _ money.Currency) {
+	s.registeredTokens = append(s.registeredTokens, assetPlatformID+"/"+contractAddress)
+}
+
+func (*stubQuoteResolver) ResolveCoinQuote(_ context.Context, _ string, _ money.Currency) (coingecko.Quote, bool, error) {
+	return coingecko.Quote{Price: money.NewDecimalFromInt(1)}, true, nil
+}
+
+func (*stubQuoteResolver) ResolveQuote(_ context.Context, _ string, _ string, _ money.Currency) (coingecko.Quote, bool, error) {
+	return coingecko.Quote{Price: money.NewDecimalFromInt(2), LastUpdatedAt: time.Unix(1700000000, 0)}, true, nil
+}
+
+type stubAssetPlatformIDResolver struct{}
+
+func (*stubAssetPlatformIDResolver) ResolveForChainID(_ context.Context, _ *big.Int) (string, error) {
+	return "ethereum", nil
+}
@@ -0,0 +1,297 @@
+package price
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jrh3k5/cryptonabber-sync/v3/coingecko"
+	priceconfig "github.com/jrh3k5/cryptonabber-sync/v3/config/price"
+	synchttp "github.com/jrh3k5/cryptonabber-sync/v3/http"
+	"github.com/jrh3k5/cryptonabber-sync/v3/money"
+)
+
+// maxDefiLlamaBatchSize is the maximum number of coins whose prices are requested from DefiLlama in a single request.
+const maxDefiLlamaBatchSize = 100
+
+// defaultDefiLlamaChains are the names by which DefiLlama refers to well-known chains, keyed by chain ID.
+var defaultDefiLlamaChains = map[string]string{
+	"1":      "ethereum",
+	"10":     "optimism",
+	"56":     "bsc",
+	"100":    "xdai",
+	"137":    "polygon",
+	"250":    "fantom",
+	"324":    "era",
+	"5000":   "mantle",
+	"8453":   "base",
+	"42161":  "arbitrum",
+	"43114":  "avax",
+	"59144":  "linea",
+	"81457":  "blast",
+	"534352": "scroll",
+}
+
+// DefiLlamaProvider is a BatchingProvider that prices assets using DefiLlama's coins API.
+//
+// DefiLlama only prices assets in USD, so no price is found for an asset in any other currency.
+// Tokens are identified to DefiLlama by chain and contract address, and native coins (and assets priced by coin ID) by their Coingecko coin IDs.
+// The prices of all registered assets are retrieved together the first time an asset is priced; pricing an asset whose price
+// is already being retrieved waits for that retrieval.
+type DefiLlamaProvider struct {
+	doer                 synchttp.Doer
+	nativeCoinIDResolver coingecko.NativeCoinIDResolver
+	chains               map[string]string // chain ID -> DefiLlama chain name
+
+	stateMutex   sync.Mutex
+	pendingCoins map[string]bool          // DefiLlama coin IDs awaiting resolution
+	inFlight     map[string]chan struct{} // DefiLlama coin IDs being retrieved -> channel closed once the retrieval is done
+	quotes       map[string]*Quote        // DefiLlama coin ID -> resolved quote; nil if no price was found
+}
+
+// NewDefiLlamaProvider builds a DefiLlamaProvider that uses the given chain names in preference to the names of chains known by default.
+func NewDefiLlamaProvider(doer synchttp.Doer, nativeCoinIDResolver coingecko.NativeCoinIDResolver, chains []priceconfig.DefiLlamaChain) *DefiLlamaProvider {
+	chainsByID := make(map[string]string, len(defaultDefiLlamaChains)+len(chains))
+	for chainID, chain := range defaultDefiLlamaChains {
+		chainsByID[chainID] = chain
+	}
+	for _, chain := range chains {
+		chainsByID[fmt.Sprintf("%d", chain.ChainID)] = chain.Chain
+	}
+
+	return &DefiLlamaProvider{
+		doer:                 doer,
+		nativeCoinIDResolver: nativeCoinIDResolver,
+		chains:               chainsByID,
+		pendingCoins:         make(map[string]bool),
+		inFlight:             make(map[string]chan struct{}),
+		quotes:               make(map[string]*Quote),
+	}
+}
+
+func (*DefiLlamaProvider) Name() string {
+	return "DefiLlama"
+}
+
+func (d *DefiLlamaProvider) RegisterAsset(ctx context.Context, asset Asset, currency money.Currency) {
+	if currency.Code != money.USD.Code {
+		return
+	}
+
+	// Registration is only an optimization; an asset that cannot be registered will fail when it is priced
+	coinID, err := d.resolveCoinID(ctx, asset)
+	if err != nil {
+		return
+	}
+
+	d.stateMutex.Lock()
+	defer d.stateMutex.Unlock()
+
+	d.registerCoin(coinID)
+}
+
+func (d *DefiLlamaProvider) ResolvePrice(ctx context.Context, asset Asset, currency money.Currency) (Quote, bool, error) {
+	if currency.Code != money.USD.Code {
+		return Quote{}, false, nil
+	}
+
+	coinID, err := d.resolveCoinID(ctx, asset)
+	if err != nil {
+		return Quote{}, false, err
+	}
+
+	// The state of the provider is not locked while prices are retrieved, so that pricing assets whose prices
+	// have already been retrieved is not held up by a slow request
+	for {
+		d.stateMutex.Lock()
+		if quote, isResolved := d.quotes[coinID]; isResolved {
+			d.stateMutex.Unlock()
+			return derefQuote(quote)
+		}
+
+		if retrievalDone, isInFlight := d.inFlight[coinID]; isInFlight {
+			d.stateMutex.Unlock()
+
+			select {
+			case <-retrievalDone:
+				// The retrieval either resolved the price or failed, leaving it to be retrieved again
+				continue
+			case <-ctx.Done():
+				return Quote{}, false, ctx.Err()
+			}
+		}
+
+		d.registerCoin(coinID)
+		coinIDs := make([]string, 0, len(d.pendingCoins))
+		for pendingCoinID := range d.pendingCoins {
+			coinIDs = append(coinIDs, pendingCoinID)
+		}
+		slices.Sort(coinIDs)
+		clear(d.pendingCoins)
+
+		retrievalDone := make(chan struct{})
+		for _, pendingCoinID := range coinIDs {
+			d.inFlight[pendingCoinID] = retrievalDone
+		}
+		d.stateMutex.Unlock()
+
+		quotes, err := d.fetchAllPrices(ctx, coinIDs)
+
+		d.stateMutex.Lock()
+		for _, pendingCoinID := range coinIDs {
+			delete(d.inFlight, pendingCoinID)
+		}
+		maps.Copy(d.quotes, quotes)
+		if err != nil {
+			// Leave anything that could not be retrieved to be retrieved again
+			for _, pendingCoinID := range coinIDs {
+				d.registerCoin(pendingCoinID)
+			}
+		}
+		d.stateMutex.Unlock()
+		close(retrievalDone)
+
+		if err != nil {
+			return Quote{}, false, err
+		}
+	}
+}
+
+// resolveCoinID resolves the identifier by which DefiLlama refers to the given asset.
+func (d *DefiLlamaProvider) resolveCoinID(ctx context.Context, asset Asset) (string, error) {
+	if asset.CoinID != "" {
+		return "coingecko:" + asset.CoinID, nil
+	}
+
+	if asset.ContractAddress == nil {
+		nativeCoinID, err := d.nativeCoinIDResolver.ResolveNativeCoinIDForChainID(ctx, asset.ChainID)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve native coin ID: %w", err)
+		}
+
+		return "coingecko:" + nativeCoinID, nil
+	}
+
+	chain, hasChain := d.chains[asset.ChainID.Text(10)]
+	if !hasChain {
+		return "", fmt.Errorf("DefiLlama's name for chain ID %v is not known; please configure one under pricing.defillama_chains", asset.ChainID)
+	}
+
+	return chain + ":" + strings.ToLower(*asset.ContractAddress), nil
+}
+
+// fetchAllPrices retrieves the prices of the given coins in batches.
+// The prices retrieved before any failure are returned alongside the failure.
+func (d *DefiLlamaProvider) fetchAllPrices(ctx context.Context, coinIDs []string) (map[string]*Quote, error) {
+	quotes := make(map[string]*Quote, len(coinIDs))
+	for batch := range slices.Chunk(coinIDs, maxDefiLlamaBatchSize) {
+		if err := d.fetchPrices(ctx, batch, quotes); err != nil {
+			return quotes, err
+		}
+	}
+
+	return quotes, nil
+}
+
+// fetchPrices retrieves the prices of the given coins and records them in the given map.
+func (d *DefiLlamaProvider) fetchPrices(ctx context.Context, coinIDs []string, quotes map[string]*Quote) error {
+	requestURL := "https://coins.llama.fi/prices/current/" + strings.Join(coinIDs, ",")
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+
+	response, err := d.doer.Do(request)
+	if err != nil {
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer func() {
+		_ = response.Body.Close()
+	}()
+
+	if response.StatusCode != http.StatusOK {
+		statusErr := synchttp.BuildUnexpectedStatusErr(response)
+		return statusErr
+	}
+
+	responseBody := &defiLlamaPricesResponse{}
+	if unmarshalErr := json.NewDecoder(response.Body).Decode(responseBody); unmarshalErr != nil {
+		return fmt.Errorf("failed to unmarshal response body: %w", unmarshalErr)
+	}
+
+	coinsByCoinID := make(map[string]defiLlamaCoin, len(responseBody.Coins))
+	for coinID, coin := range responseBody.Coins {
+		coinsByCoinID[strings.ToLower(coinID)] = coin
+	}
+
+	batchQuotes := make(map[string]*Quote, len(coinIDs))
+	for _, coinID := range coinIDs {
+		coin := coinsByCoinID[strings.ToLower(coinID)]
+		if coin.Price.String() == "" {
+			batchQuotes[coinID] = nil
+			continue
+		}
+
+		parsedPrice, err := money.ParseDecimal(coin.Price.String())
+		if err != nil {
+			return fmt.Errorf("failed to parse price of '%s': %w", coinID, err)
+		}
+
+		quote := &Quote{
+			Price: parsedPrice,
+		}
+
+		if coin.Timestamp.String() != "" {
+			timestamp, err := coin.Timestamp.Int64()
+			if err != nil {
+				return fmt.Errorf("failed to parse timestamp of '%s': %w", coinID, err)
+			}
+
+			quote.UpdatedAt = time.Unix(timestamp, 0)
+		}
+
+		batchQuotes[coinID] = quote
+	}
+
+	maps.Copy(quotes, batchQuotes)
+
+	return nil
+}
+
+// registerCoin records the given coin as awaiting retrieval, unless its price has already been resolved or is being retrieved.
+// The caller is expected to hold the state mutex.
+func (d *DefiLlamaProvider) registerCoin(coinID string) {
+	if _, isResolved := d.quotes[coinID]; isResolved {
+		return
+	}
+
+	if _, isInFlight := d.inFlight[coinID]; isInFlight {
+		return
+	}
+
+	d.pendingCoins[coinID] = true
+}
+
+// derefQuote translates a recorded quote into the result of resolving it.
+func derefQuote(quote *Quote) (Quote, bool, error) {
+	if quote == nil {
+		return Quote{}, false, nil
+	}
+
+	return *quote, true, nil
+}
+
+type defiLlamaPricesResponse struct {
+	Coins map[string]defiLlamaCoin `json:"coins"`
+}
+
+type defiLlamaCoin struct {
+	Price     json.Number `json:"price"`
+	Timestamp json.Number `json:"timestamp"` // the Unix time at which the price was last updated
+}
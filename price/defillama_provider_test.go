@@ -0,0 +1,146 @@
+package price_test
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	priceconfig "github.com/jrh3k5/cryptonabber-sync/v3/config/price"
+	"github.com/jrh3k5/cryptonabber-sync/v3/money"
+	"github.com/jrh3k5/cryptonabber-sync/v3/price"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DefiLlamaProvider", func() {
+	var ctx context.Context
+	var provider *price.DefiLlamaProvider
+	var requestedCoins []string
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		requestedCoins = nil
+
+		provider = price.NewDefiLlamaProvider(http.DefaultClient, &stubNativeCoinIDResolver{}, []priceconfig.DefiLlamaChain{
+			{
+				ChainID: 999999,
+				Chain:   "testchain",
+			},
+		})
+
+		prices := map[string]string{
+			"ethereum:0xaaaa":    "1.01",
+			"testchain:0xbbbb":   "0.5",
+			"coingecko:ethereum": "3456.78",
+		}
+
+		httpmock.RegisterRegexpResponder(http.MethodGet, regexp.MustCompile(`^https://coins\.llama\.fi/prices/current/`), func(request *http.Request) (*http.Response, error) {
+			coins := strings.TrimPrefix(request.URL.Path, "/prices/current/")
+			requestedCoins = append(requestedCoins, coins)
+
+			var entries []string
+			for _, coin := range strings.Split(coins, ",") {
+				if price, hasPrice := prices[coin]; hasPrice {
+					entries = append(entries, fmt.Sprintf(`"%s": { "decimals": 18, "symbol": "TEST", "price": %s, "timestamp": 1700000000, "confidence": 0.99 }`, coin, price))
+				}
+			}
+
+			return httpmock.NewStringResponse(http.StatusOK, `{ "coins": {`+strings.Join(entries, ",")+`} }`), nil
+		})
+	})
+
+	It("resolves all registered assets in a single request", func() {
+		tokenAddress := "0xAAAA"
+		otherTokenAddress := "0xbbbb"
+		tokenAsset := price.Asset{ChainName: "ethereum", ChainID: big.NewInt(1), ContractAddress: &tokenAddress}
+		otherTokenAsset := price.Asset{ChainName: "testchain", ChainID: big.NewInt(999999), ContractAddress: &otherTokenAddress}
+		nativeAsset := price.Asset{ChainName: "ethereum", ChainID: big.NewInt(1)}
+
+		provider.RegisterAsset(ctx, tokenAsset, money.USD)
+		provider.RegisterAsset(ctx, otherTokenAsset, money.USD)
+		provider.RegisterAsset(ctx, nativeAsset, money.USD)
+
+		for asset, expectedPrice := range map[price.Asset]string{tokenAsset: "1.01", otherTokenAsset: "0.5", nativeAsset: "3456.78"} {
+			rate, hasRate, err := provider.ResolvePrice(ctx, asset, money.USD)
+			Expect(err).ToNot(HaveOccurred(), "resolving the price of %v should not fail", asset)
+			Expect(hasRate).To(BeTrue(), "a price should be found for %v", asset)
+			Expect(rate.Price.String()).To(Equal(expectedPrice), "the price of %v should be parsed", asset)
+			Expect(rate.UpdatedAt).To(Equal(time.Unix(1700000000, 0)), "the timestamp of the price of %v should be parsed", asset)
+		}
+
+		Expect(requestedCoins).To(Equal([]string{"coingecko:ethereum,ethereum:0xaaaa,testchain:0xbbbb"}), "all of the coins should be requested together, once")
+	})
+
+	It("does not hold up other prices while a request is in flight", func() {
+		var slowRequestCount atomic.Int32
+		slowRequestReceived := make(chan struct{}, 1)
+		releaseSlowRequest := make(chan struct{})
+		httpmock.RegisterResponder(http.MethodGet, "https://coins.llama.fi/prices/current/testchain:0xdddd", func(_ *http.Request) (*http.Response, error) {
+			slowRequestCount.Add(1)
+			slowRequestReceived <- struct{}{}
+			<-releaseSlowRequest
+
+			return httpmock.NewStringResponse(http.StatusOK, `{ "coins": { "testchain:0xdddd": { "price": 8.9, "timestamp": 1700000000 } } }`), nil
+		})
+
+		slowTokenAddress := "0xdddd"
+		slowAsset := price.Asset{ChainName: "testchain", ChainID: big.NewInt(999999), ContractAddress: &slowTokenAddress}
+		slowQuotes := make(chan price.Quote, 2)
+		resolveSlowPrice := func() {
+			defer GinkgoRecover()
+
+			quote, hasRate, err := provider.ResolvePrice(ctx, slowAsset, money.USD)
+			Expect(err).ToNot(HaveOccurred(), "resolving the slow price should not fail")
+			Expect(hasRate).To(BeTrue(), "the slow price should be found")
+			slowQuotes <- quote
+		}
+
+		go resolveSlowPrice()
+		Eventually(slowRequestReceived).Should(Receive(), "the slow price should be requested")
+		go resolveSlowPrice()
+
+		tokenAddress := "0xaaaa"
+		_, hasRate, err := provider.ResolvePrice(ctx, price.Asset{ChainName: "ethereum", ChainID: big.NewInt(1), ContractAddress: &tokenAddress}, money.USD)
+		Expect(err).ToNot(HaveOccurred(), "resolving a price while another is in flight should not fail")
+		Expect(hasRate).To(BeTrue(), "the price should be found while another is in flight")
+
+		close(releaseSlowRequest)
+		for range 2 {
+			var slowQuote price.Quote
+			Eventually(slowQuotes).Should(Receive(&slowQuote), "the slow price should be resolved once its request completes")
+			Expect(slowQuote.Price.String()).To(Equal("8.9"), "the slow price should be parsed")
+		}
+
+		Expect(slowRequestCount.Load()).To(Equal(int32(1)), "the price in flight should not be requested again")
+	})
+
+	It("does not price assets in currencies other than USD", func() {
+		tokenAddress := "0xaaaa"
+		_, hasRate, err := provider.ResolvePrice(ctx, price.Asset{ChainName: "ethereum", ChainID: big.NewInt(1), ContractAddress: &tokenAddress}, money.CurrencyForCode("EUR"))
+		Expect(err).ToNot(HaveOccurred(), "resolving the price should not fail")
+		Expect(hasRate).To(BeFalse(), "no price should be found")
+		Expect(requestedCoins).To(BeEmpty(), "no request should be made")
+	})
+
+	It("fails for a chain whose name is not known", func() {
+		tokenAddress := "0xaaaa"
+		_, _, err := provider.ResolvePrice(ctx, price.Asset{ChainName: "unknown", ChainID: big.NewInt(123456789), ContractAddress: &tokenAddress}, money.USD)
+		Expect(err).To(MatchError(ContainSubstring("pricing.defillama_chains")), "the failure should describe how to configure the chain")
+	})
+})
+
+type stubNativeCoinIDResolver struct{}
+
+func (*stubNativeCoinIDResolver) ResolveNativeCoinIDForChainID(_ context.Context, chainID *big.Int) (string, error) {
+	if chainID.Int64() == 1 {
+		return "ethereum", nil
+	}
+
+	return "", fmt.Errorf("no native coin for chain ID %v", chainID)
+}
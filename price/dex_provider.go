@@ -0,0 +1,263 @@
+package price
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/jrh3k5/cryptonabber-sync/v3/config"
+	"github.com/jrh3k5/cryptonabber-sync/v3/config/chain"
+	priceconfig "github.com/jrh3k5/cryptonabber-sync/v3/config/price"
+	rpcconfig "github.com/jrh3k5/cryptonabber-sync/v3/config/rpc"
+	"github.com/jrh3k5/cryptonabber-sync/v3/evm/abi"
+	"github.com/jrh3k5/cryptonabber-sync/v3/http/json/rpc"
+	"github.com/jrh3k5/cryptonabber-sync/v3/money"
+	"github.com/jrh3k5/cryptonabber-sync/v3/token"
+	"github.com/jrh3k5/cryptonabber-sync/v3/uniswap"
+)
+
+// DEXProvider is a Provider that prices assets using the DEX liquidity pools in which they are paired against another asset.
+//
+// An asset is priced by the amount of the other asset in its pool for which it trades, which is in turn priced by the
+// quote provider. Only assets for which a pool has been configured are priced.
+type DEXProvider struct {
+	rpcConfigurationResolver rpcconfig.ConfigurationResolver
+	ethCaller                rpc.EthCaller
+	decimalsResolver         token.DecimalsResolver
+	quoteProvider            Provider
+	pools                    map[assetKey]priceconfig.DEXPool
+}
+
+// NewDEXProvider builds a DEXProvider that prices assets using the given pools, pricing the other assets in the pools
+// using the given quote provider.
+func NewDEXProvider(rpcConfigurationResolver rpcconfig.ConfigurationResolver, ethCaller rpc.EthCaller, decimalsResolver token.DecimalsResolver, quoteProvider Provider, pools []priceconfig.DEXPool) *DEXProvider {
+	poolsByAsset := make(map[assetKey]priceconfig.DEXPool, len(pools))
+	for _, pool := range pools {
+		poolsByAsset[newAssetKey(pool.ChainName, pool.TokenAddress)] = pool
+	}
+
+	return &DEXProvider{
+		rpcConfigurationResolver: rpcConfigurationResolver,
+		ethCaller:                ethCaller,
+		decimalsResolver:         decimalsResolver,
+		quoteProvider:            quoteProvider,
+		pools:                    poolsByAsset,
+	}
+}
+
+func (*DEXProvider) Name() string {
+	return "DEX"
+}
+
+func (d *DEXProvider) ResolvePrice(ctx context.Context, asset Asset, currency money.Currency) (Quote, bool, error) {
+	if asset.CoinID != "" || asset.ChainName == "" || asset.ContractAddress == nil {
+		return Quote{}, false, nil
+	}
+
+	pool, hasPool := d.pools[assetKeyOf(asset)]
+	if !hasPool {
+		return Quote{}, false, nil
+	}
+
+	onchainAsset := config.OnchainAsset{ChainName: asset.ChainName}
+	rpcURL, err := token.ResolveRPCURL(ctx, d.rpcConfigurationResolver, onchainAsset, chain.TypeEVM)
+	if err != nil {
+		return Quote{}, false, fmt.Errorf("failed to resolve RPC URL: %w", err)
+	}
+
+	token0, err := d.getPoolToken(ctx, rpcURL, pool, "token0")
+	if err != nil {
+		return Quote{}, false, err
+	}
+
+	token1, err := d.getPoolToken(ctx, rpcURL, pool, "token1")
+	if err != nil {
+		return Quote{}, false, err
+	}
+
+	var isToken0 bool
+	var quoteAddress string
+	switch {
+	case strings.EqualFold(token0, *asset.ContractAddress):
+		isToken0 = true
+		quoteAddress = token1
+	case strings.EqualFold(token1, *asset.ContractAddress):
+		quoteAddress = token0
+	default:
+		return Quote{}, false, fmt.Errorf("pool '%s' does not contain token '%s'", pool.PoolAddress, *asset.ContractAddress)
+	}
+
+	// The price of token0, expressed in the smallest units of token1 per smallest unit of token0
+	var rawPrice *big.Rat
+	switch pool.PoolType {
+	case priceconfig.DEXPoolTypeUniswapV2:
+		rawPrice, err = d.getUniswapV2Price(ctx, rpcURL, pool)
+	case priceconfig.DEXPoolTypeUniswapV3:
+		if pool.TWAPPeriod > 0 {
+			rawPrice, err = d.getUniswapV3TWAPPrice(ctx, rpcURL, pool)
+		} else {
+			rawPrice, err = d.getUniswapV3SpotPrice(ctx, rpcURL, pool)
+		}
+	default:
+		return Quote{}, false, fmt.Errorf("unsupported pool type '%s' for pool '%s'", pool.PoolType, pool.PoolAddress)
+	}
+	if err != nil {
+		return Quote{}, false, err
+	}
+
+	token0Decimals, err := d.decimalsResolver.ResolveDecimals(ctx, onchainAsset, &token0)
+	if err != nil {
+		return Quote{}, false, fmt.Errorf("failed to resolve decimals of token '%s': %w", token0, err)
+	}
+
+	token1Decimals, err := d.decimalsResolver.ResolveDecimals(ctx, onchainAsset, &token1)
+	if err != nil {
+		return Quote{}, false, fmt.Errorf("failed to resolve decimals of token '%s': %w", token1, err)
+	}
+
+	// Express the price in whole units of token1 per whole unit of token0
+	relativePrice := new(big.Rat).Mul(rawPrice, new(big.Rat).SetFrac(pow10(token0Decimals), pow10(token1Decimals)))
+	if !isToken0 {
+		relativePrice.Inv(relativePrice)
+	}
+
+	quoteAsset := Asset{
+		ChainName:       asset.ChainName,
+		ChainID:         asset.ChainID,
+		ContractAddress: &quoteAddress,
+	}
+
+	quoteAssetQuote, hasQuoteAssetQuote, err := d.quoteProvider.ResolvePrice(ctx, quoteAsset, currency)
+	if err != nil {
+		return Quote{}, false, fmt.Errorf("failed to resolve price of quote asset %s: %w", quoteAsset, err)
+	} else if !hasQuoteAssetQuote {
+		return Quote{}, false, fmt.Errorf("no price found for quote asset %s", quoteAsset)
+	}
+
+	// The pool's price is current, so the quote is only as old as the price of the quote asset
+	return Quote{
+		Price:     money.NewDecimalFromRat(relativePrice).Mul(quoteAssetQuote.Price),
+		UpdatedAt: quoteAssetQuote.UpdatedAt,
+	}, true, nil
+}
+
+func (d *DEXProvider) getPoolToken(ctx context.Context, rpcURL string, pool priceconfig.DEXPool, functionName string) (string, error) {
+	result, err := d.ethCaller.EthCall(ctx, rpcURL, functionName, pool.PoolAddress)
+	if err != nil {
+		return "", fmt.Errorf("failed to get %s of pool '%s': %w", functionName, pool.PoolAddress, err)
+	}
+
+	decoded, err := abi.DecodeHex([]string{"address"}, result)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode %s of pool '%s': %w", functionName, pool.PoolAddress, err)
+	}
+
+	return decoded[0].(string), nil
+}
+
+// getUniswapV2Price gets the price of token0 in token1 from the reserves of a Uniswap V2 pair.
+func (d *DEXProvider) getUniswapV2Price(ctx context.Context, rpcURL string, pool priceconfig.DEXPool) (*big.Rat, error) {
+	result, err := d.ethCaller.EthCall(ctx, rpcURL, "getReserves", pool.PoolAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reserves of pool '%s': %w", pool.PoolAddress, err)
+	}
+
+	// reserve0, reserve1, blockTimestampLast
+	decoded, err := abi.DecodeHex([]string{"uint112", "uint112", "uint32"}, result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode reserves of pool '%s': %w", pool.PoolAddress, err)
+	}
+
+	reserve0 := decoded[0].(*big.Int)
+	reserve1 := decoded[1].(*big.Int)
+	if reserve0.Sign() == 0 || reserve1.Sign() == 0 {
+		return nil, fmt.Errorf("pool '%s' has no liquidity", pool.PoolAddress)
+	}
+
+	return new(big.Rat).SetFrac(reserve1, reserve0), nil
+}
+
+// getUniswapV3SpotPrice gets the current price of token0 in token1 from the square root price of a Uniswap V3 pool.
+func (d *DEXProvider) getUniswapV3SpotPrice(ctx context.Context, rpcURL string, pool priceconfig.DEXPool) (*big.Rat, error) {
+	result, err := d.ethCaller.EthCall(ctx, rpcURL, "slot0", pool.PoolAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get slot0 of pool '%s': %w", pool.PoolAddress, err)
+	}
+
+	// sqrtPriceX96, tick, observationIndex, observationCardinality, observationCardinalityNext, feeProtocol, unlocked
+	decoded, err := abi.DecodeHex([]string{"uint160", "int24", "uint16", "uint16", "uint16", "uint8", "bool"}, result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode slot0 of pool '%s': %w", pool.PoolAddress, err)
+	}
+
+	sqrtPriceX96 := decoded[0].(*big.Int)
+	if sqrtPriceX96.Sign() == 0 {
+		return nil, fmt.Errorf("pool '%s' has not been initialized", pool.PoolAddress)
+	}
+
+	// price = (sqrtPriceX96 / 2^96)^2
+	return new(big.Rat).SetFrac(new(big.Int).Mul(sqrtPriceX96, sqrtPriceX96), new(big.Int).Lsh(big.NewInt(1), 192)), nil
+}
+
+// getUniswapV3TWAPPrice gets the time-weighted average price of token0 in token1 over the pool's configured period
+// from the tick accumulator of a Uniswap V3 pool.
+func (d *DEXProvider) getUniswapV3TWAPPrice(ctx context.Context, rpcURL string, pool priceconfig.DEXPool) (*big.Rat, error) {
+	periodSeconds := int64(pool.TWAPPeriod.Seconds())
+	if periodSeconds <= 0 || periodSeconds > int64(^uint32(0)) {
+		return nil, fmt.Errorf("invalid TWAP period for pool '%s': %v", pool.PoolAddress, pool.TWAPPeriod)
+	}
+
+	result, err := d.ethCaller.EthCall(ctx, rpcURL, "observe", pool.PoolAddress, rpc.Arg("uint32[]", []uint32{uint32(periodSeconds), 0}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to observe pool '%s': %w", pool.PoolAddress, err)
+	}
+
+	// tickCumulatives, secondsPerLiquidityCumulativeX128s
+	decoded, err := abi.DecodeHex([]string{"int56[]", "uint160[]"}, result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode observations of pool '%s': %w", pool.PoolAddress, err)
+	}
+
+	tickCumulatives := decoded[0].([]any)
+	if len(tickCumulatives) != 2 {
+		return nil, fmt.Errorf("expected 2 observations of pool '%s', but got %d", pool.PoolAddress, len(tickCumulatives))
+	}
+
+	// Round towards negative infinity, as Uniswap's OracleLibrary does
+	period := big.NewInt(periodSeconds)
+	tickDelta := new(big.Int).Sub(tickCumulatives[1].(*big.Int), tickCumulatives[0].(*big.Int))
+	meanTick, remainder := new(big.Int).QuoRem(tickDelta, period, new(big.Int))
+	if tickDelta.Sign() < 0 && remainder.Sign() != 0 {
+		meanTick.Sub(meanTick, big.NewInt(1))
+	}
+
+	if !meanTick.IsInt64() {
+		return nil, errors.New("mean tick is out of range")
+	}
+
+	price, err := priceAtTick(meanTick.Int64())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get price at mean tick of pool '%s': %w", pool.PoolAddress, err)
+	}
+
+	return price, nil
+}
+
+// priceAtTick gets the price of token0 in token1 at the given Uniswap V3 tick, which is 1.0001^tick.
+// It is derived from the tick's square root price, as pools themselves derive it.
+func priceAtTick(tick int64) (*big.Rat, error) {
+	sqrtRatioX96, err := uniswap.SqrtRatioAtTick(tick)
+	if err != nil {
+		return nil, err
+	}
+
+	ratioX192 := new(big.Int).Mul(sqrtRatioX96, sqrtRatioX96)
+
+	return new(big.Rat).SetFrac(ratioX192, new(big.Int).Lsh(big.NewInt(1), 192)), nil
+}
+
+func pow10(exponent int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exponent)), nil)
+}
@@ -0,0 +1,185 @@
+package price_test
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"time"
+
+	priceconfig "github.com/jrh3k5/cryptonabber-sync/v3/config/price"
+	"github.com/jrh3k5/cryptonabber-sync/v3/http/json/rpc"
+	"github.com/jrh3k5/cryptonabber-sync/v3/money"
+	"github.com/jrh3k5/cryptonabber-sync/v3/price"
+	"github.com/jrh3k5/cryptonabber-sync/v3/token"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DEXProvider", func() {
+	var ctx context.Context
+	var quoteProvider *stubProvider
+
+	// The priced token has 18 decimals, and the quote token has 6
+	pricedToken := "0x1111111111111111111111111111111111111111"
+	quoteToken := "0x2222222222222222222222222222222222222222"
+	// An 18-decimal token that sorts after the priced token
+	otherToken := "0x3333333333333333333333333333333333333333"
+
+	registerPool := func(poolAddress string, token0 string, token1 string) {
+		evmNode.RegisterETHCallCall("token0", poolAddress, nil, func(_ string, _ []string) (rpc.MockEVMNodeRPCResult, *rpc.MockEVMNodeRPCError, error) {
+			return rpc.NewMockEVMNodeRPCAddressResult(token0), nil, nil
+		})
+		evmNode.RegisterETHCallCall("token1", poolAddress, nil, func(_ string, _ []string) (rpc.MockEVMNodeRPCResult, *rpc.MockEVMNodeRPCError, error) {
+			return rpc.NewMockEVMNodeRPCAddressResult(token1), nil, nil
+		})
+	}
+
+	newProvider := func(pools ...priceconfig.DEXPool) *price.DEXProvider {
+		ethCaller := rpc.NewDirectEthCaller(http.DefaultClient, rpc.NewLatestBlockResolver())
+		return price.NewDEXProvider(rpcConfigurationResolver, ethCaller, token.NewRPCDecimalsResolver(rpcConfigurationResolver, ethCaller), quoteProvider, pools)
+	}
+
+	assetFor := func(tokenAddress string) price.Asset {
+		return price.Asset{
+			ChainName:       chainName,
+			ChainID:         big.NewInt(1),
+			ContractAddress: &tokenAddress,
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		quoteProvider = &stubProvider{name: "quote", price: "2"}
+
+		for tokenAddress, decimals := range map[string]int64{pricedToken: 18, quoteToken: 6, otherToken: 18} {
+			evmNode.RegisterETHCallCall("decimals", tokenAddress, nil, func(_ string, _ []string) (rpc.MockEVMNodeRPCResult, *rpc.MockEVMNodeRPCError, error) {
+				return rpc.NewMockEVMNodeRPCNumericResult(big.NewInt(decimals)), nil, nil
+			})
+		}
+	})
+
+	Context("Uniswap V2 pairs", func() {
+		poolAddress := "0x000000000000000000000000000000000000a002"
+
+		BeforeEach(func() {
+			registerPool(poolAddress, pricedToken, quoteToken)
+
+			evmNode.RegisterETHCallCall("getReserves", poolAddress, nil, func(_ string, _ []string) (rpc.MockEVMNodeRPCResult, *rpc.MockEVMNodeRPCError, error) {
+				reserve0 := new(big.Int).Mul(big.NewInt(1000), new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil))
+				reserve1 := big.NewInt(2500_000000)
+				return rpc.NewMockEVMNodeRPCABIResult([]string{"uint112", "uint112", "uint32"}, reserve0, reserve1, big.NewInt(1700000000)), nil, nil
+			})
+		})
+
+		It("prices the token by its reserves", func() {
+			provider := newProvider(priceconfig.DEXPool{
+				ChainName:    chainName,
+				TokenAddress: pricedToken,
+				PoolAddress:  poolAddress,
+				PoolType:     priceconfig.DEXPoolTypeUniswapV2,
+			})
+
+			rate, hasRate, err := provider.ResolvePrice(ctx, assetFor(pricedToken), money.USD)
+			Expect(err).ToNot(HaveOccurred(), "resolving the price should not fail")
+			Expect(hasRate).To(BeTrue(), "a price should be found")
+			Expect(rate.Price.String()).To(Equal("5"), "the token should be priced at 2.5 quote tokens of 2 each")
+		})
+
+		It("dates the price by the price of the quote asset", func() {
+			quoteProvider.updatedAt = time.Unix(1700000000, 0)
+
+			provider := newProvider(priceconfig.DEXPool{
+				ChainName:    chainName,
+				TokenAddress: pricedToken,
+				PoolAddress:  poolAddress,
+				PoolType:     priceconfig.DEXPoolTypeUniswapV2,
+			})
+
+			rate, _, err := provider.ResolvePrice(ctx, assetFor(pricedToken), money.USD)
+			Expect(err).ToNot(HaveOccurred(), "resolving the price should not fail")
+			Expect(rate.UpdatedAt).To(Equal(quoteProvider.updatedAt), "the price should be as old as the price of the quote asset")
+		})
+
+		It("fails if the quote asset cannot be priced", func() {
+			quoteProvider.price = ""
+
+			provider := newProvider(priceconfig.DEXPool{
+				ChainName:    chainName,
+				TokenAddress: pricedToken,
+				PoolAddress:  poolAddress,
+				PoolType:     priceconfig.DEXPoolTypeUniswapV2,
+			})
+
+			_, _, err := provider.ResolvePrice(ctx, assetFor(pricedToken), money.USD)
+			Expect(err).To(MatchError(ContainSubstring("no price found for quote asset")), "the unpriced quote asset should be reported")
+		})
+	})
+
+	Context("Uniswap V3 pools", func() {
+		poolAddress := "0x000000000000000000000000000000000000a003"
+
+		BeforeEach(func() {
+			registerPool(poolAddress, pricedToken, otherToken)
+
+			evmNode.RegisterETHCallCall("slot0", poolAddress, nil, func(_ string, _ []string) (rpc.MockEVMNodeRPCResult, *rpc.MockEVMNodeRPCError, error) {
+				// A square root price of 2, for a price of 4 of token1 per token0
+				sqrtPriceX96 := new(big.Int).Lsh(big.NewInt(2), 96)
+				return rpc.NewMockEVMNodeRPCABIResult([]string{"uint160", "int24", "uint16", "uint16", "uint16", "uint8", "bool"}, sqrtPriceX96, big.NewInt(13863), big.NewInt(0), big.NewInt(1), big.NewInt(1), big.NewInt(0), true), nil, nil
+			})
+
+			evmNode.RegisterETHCallCall("observe", poolAddress, []string{"uint32[]"}, func(_ string, _ []string) (rpc.MockEVMNodeRPCResult, *rpc.MockEVMNodeRPCError, error) {
+				// A mean tick of -1 over the 1800-second period, rounded down from -0.5
+				return rpc.NewMockEVMNodeRPCABIResult([]string{"int56[]", "uint160[]"}, []any{big.NewInt(900), big.NewInt(0)}, []any{big.NewInt(0), big.NewInt(0)}), nil, nil
+			})
+		})
+
+		It("prices token0 by the current price", func() {
+			provider := newProvider(priceconfig.DEXPool{
+				ChainName:    chainName,
+				TokenAddress: pricedToken,
+				PoolAddress:  poolAddress,
+				PoolType:     priceconfig.DEXPoolTypeUniswapV3,
+			})
+
+			rate, hasRate, err := provider.ResolvePrice(ctx, assetFor(pricedToken), money.USD)
+			Expect(err).ToNot(HaveOccurred(), "resolving the price should not fail")
+			Expect(hasRate).To(BeTrue(), "a price should be found")
+			Expect(rate.Price.String()).To(Equal("8"), "the token should be priced at 4 quote tokens of 2 each")
+		})
+
+		It("prices token1 by the inverse of the current price", func() {
+			provider := newProvider(priceconfig.DEXPool{
+				ChainName:    chainName,
+				TokenAddress: otherToken,
+				PoolAddress:  poolAddress,
+				PoolType:     priceconfig.DEXPoolTypeUniswapV3,
+			})
+
+			rate, hasRate, err := provider.ResolvePrice(ctx, assetFor(otherToken), money.USD)
+			Expect(err).ToNot(HaveOccurred(), "resolving the price should not fail")
+			Expect(hasRate).To(BeTrue(), "a price should be found")
+			Expect(rate.Price.String()).To(Equal("0.5"), "the token should be priced at a quarter of a quote token of 2")
+		})
+
+		It("prices the token by the time-weighted average price", func() {
+			provider := newProvider(priceconfig.DEXPool{
+				ChainName:    chainName,
+				TokenAddress: pricedToken,
+				PoolAddress:  poolAddress,
+				PoolType:     priceconfig.DEXPoolTypeUniswapV3,
+				TWAPPeriod:   30 * time.Minute,
+			})
+
+			rate, hasRate, err := provider.ResolvePrice(ctx, assetFor(pricedToken), money.USD)
+			Expect(err).ToNot(HaveOccurred(), "resolving the price should not fail")
+			Expect(hasRate).To(BeTrue(), "a price should be found")
+			Expect(rate.Price.FloatString(8)).To(Equal("1.99980002"), "the token should be priced at 1.0001^-1 quote tokens of 2 each")
+		})
+	})
+
+	It("does not price a token without a pool", func() {
+		_, hasRate, err := newProvider().ResolvePrice(ctx, assetFor(pricedToken), money.USD)
+		Expect(err).ToNot(HaveOccurred(), "resolving the price should not fail")
+		Expect(hasRate).To(BeFalse(), "no price should be found")
+	})
+})
@@ -0,0 +1,162 @@
+package price
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/jrh3k5/cryptonabber-sync/v3/money"
+)
+
+// Asset describes an asset to be priced.
+type Asset struct {
+	ChainName       string   // the chain name of the RPC configuration of the chain on which the asset resides
+	ChainID         *big.Int // the ID of the chain on which the asset resides
+	ContractAddress *string  // the address of the asset; nil for the chain's native coin
+
+	CoinID     string         // the Coingecko coin ID by which the asset is to be priced instead of its address, for providers that support it
+	FixedPrice *money.Decimal // a fixed price for the asset, in the currency in which it is being valued; if set, no provider is consulted
+}
+
+func (a Asset) String() string {
+	if a.CoinID != "" {
+		return fmt.Sprintf("coin '%s'", a.CoinID)
+	}
+
+	contractAddress := "native"
+	if a.ContractAddress != nil {
+		contractAddress = *a.ContractAddress
+	}
+
+	if a.ChainName == "" {
+		return fmt.Sprintf("%s on chain ID %v", contractAddress, a.ChainID)
+	}
+
+	return fmt.Sprintf("%s on chain '%s'", contractAddress, a.ChainName)
+}
+
+// assetKey identifies an asset for which a source of prices has been configured.
+type assetKey struct {
+	chainName    string
+	tokenAddress string // the lowercased address of the asset; blank for the chain's native coin
+}
+
+func newAssetKey(chainName string, tokenAddress string) assetKey {
+	return assetKey{
+		chainName:    chainName,
+		tokenAddress: strings.ToLower(tokenAddress),
+	}
+}
+
+// assetKeyOf gets the key identifying the given asset.
+func assetKeyOf(asset Asset) assetKey {
+	var tokenAddress string
+	if asset.ContractAddress != nil {
+		tokenAddress = *asset.ContractAddress
+	}
+
+	return newAssetKey(asset.ChainName, tokenAddress)
+}
+
+// Quote is the per-token price of an asset.
+type Quote struct {
+	Price     money.Decimal
+	UpdatedAt time.Time // when the source of the price last updated it; zero if not known
+}
+
+// Provider describes a source of prices.
+type Provider interface {
+	// Name gets the name of the provider, for use in messages.
+	Name() string
+
+	// ResolvePrice resolves the per-token price, in the given currency, of the given asset.
+	// The returned bool is true if a price was found; false if not.
+	ResolvePrice(ctx context.Context, asset Asset, currency money.Currency) (Quote, bool, error)
+}
+
+// BatchingProvider is a Provider that can resolve prices more efficiently when it is told ahead of time
+// which assets will be priced.
+type BatchingProvider interface {
+	Provider
+
+	// RegisterAsset registers the given asset as one whose price in the given currency will be resolved.
+	RegisterAsset(ctx context.Context, asset Asset, currency money.Currency)
+}
+
+// FallbackProvider is a BatchingProvider that consults each of a list of providers in order until one of them prices an asset.
+//
+// An asset with a fixed price is priced at it without any of the providers being consulted.
+// A provider that fails to price an asset, or that gives a quote older than the maximum quote age, does not prevent the
+// following providers from being consulted; the failure is only reported if none of the providers can price the asset.
+type FallbackProvider struct {
+	maxQuoteAge time.Duration
+	providers   []Provider
+}
+
+// NewFallbackProvider builds a FallbackProvider that consults the given providers in the given order.
+// Quotes last updated longer ago than the given maximum age are rejected; if the maximum age is zero, quotes of any age are accepted.
+// Quotes whose update time is not known are always accepted.
+func NewFallbackProvider(maxQuoteAge time.Duration, providers ...Provider) *FallbackProvider {
+	return &FallbackProvider{
+		maxQuoteAge: maxQuoteAge,
+		providers:   providers,
+	}
+}
+
+func (f *FallbackProvider) Name() string {
+	names := make([]string, len(f.providers))
+	for i, provider := range f.providers {
+		names[i] = provider.Name()
+	}
+
+	return strings.Join(names, ", ")
+}
+
+func (f *FallbackProvider) RegisterAsset(ctx context.Context, asset Asset, currency money.Currency) {
+	if asset.FixedPrice != nil {
+		return
+	}
+
+	for _, provider := range f.providers {
+		if batchingProvider, isBatching := provider.(BatchingProvider); isBatching {
+			batchingProvider.RegisterAsset(ctx, asset, currency)
+		}
+	}
+}
+
+func (f *FallbackProvider) ResolvePrice(ctx context.Context, asset Asset, currency money.Currency) (Quote, bool, error) {
+	if asset.FixedPrice != nil {
+		return Quote{Price: *asset.FixedPrice}, true, nil
+	}
+
+	var errs []error
+	for _, provider := range f.providers {
+		quote, hasQuote, err := provider.ResolvePrice(ctx, asset, currency)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", provider.Name(), err))
+			continue
+		}
+
+		if !hasQuote {
+			continue
+		}
+
+		if f.maxQuoteAge > 0 && !quote.UpdatedAt.IsZero() {
+			if quoteAge := time.Since(quote.UpdatedAt); quoteAge > f.maxQuoteAge {
+				errs = append(errs, fmt.Errorf("%s: quote was last updated %v ago, which exceeds the maximum age of %v", provider.Name(), quoteAge.Round(time.Second), f.maxQuoteAge))
+				continue
+			}
+		}
+
+		return quote, true, nil
+	}
+
+	if len(errs) > 0 {
+		return Quote{}, false, errors.Join(errs...)
+	}
+
+	return Quote{}, false, nil
+}
@@ -0,0 +1,154 @@
+package price_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/jrh3k5/cryptonabber-sync/v3/money"
+	"github.com/jrh3k5/cryptonabber-sync/v3/price"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FallbackProvider", func() {
+	var ctx context.Context
+	var asset price.Asset
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		contractAddress := "0xfallback"
+		asset = price.Asset{
+			ChainName:       chainName,
+			ChainID:         big.NewInt(1),
+			ContractAddress: &contractAddress,
+		}
+	})
+
+	It("uses the price of the first provider that prices the asset", func() {
+		first := &stubProvider{name: "first"}
+		second := &stubProvider{name: "second", price: "1.5"}
+		third := &stubProvider{name: "third", price: "2.5"}
+
+		rate, hasRate, err := price.NewFallbackProvider(0, first, second, third).ResolvePrice(ctx, asset, money.USD)
+		Expect(err).ToNot(HaveOccurred(), "resolving the price should not fail")
+		Expect(hasRate).To(BeTrue(), "a price should be found")
+		Expect(rate.Price.String()).To(Equal("1.5"), "the price of the first provider to price the asset should be used")
+		Expect(first.resolveCount).To(Equal(1), "the first provider should be consulted")
+		Expect(third.resolveCount).To(BeZero(), "providers after the one that priced the asset should not be consulted")
+	})
+
+	It("falls back past a provider that fails", func() {
+		failing := &stubProvider{name: "failing", err: errors.New("expected error")}
+		pricing := &stubProvider{name: "pricing", price: "3"}
+
+		rate, hasRate, err := price.NewFallbackProvider(0, failing, pricing).ResolvePrice(ctx, asset, money.USD)
+		Expect(err).ToNot(HaveOccurred(), "resolving the price should not fail")
+		Expect(hasRate).To(BeTrue(), "a price should be found")
+		Expect(rate.Price.String()).To(Equal("3"), "the price of the provider that did not fail should be used")
+	})
+
+	It("reports failures if no provider prices the asset", func() {
+		failing := &stubProvider{name: "failing", err: errors.New("expected error")}
+		empty := &stubProvider{name: "empty"}
+
+		_, _, err := price.NewFallbackProvider(0, failing, empty).ResolvePrice(ctx, asset, money.USD)
+		Expect(err).To(MatchError(ContainSubstring("failing: expected error")), "the failure should be reported")
+	})
+
+	It("indicates that no price was found if no provider prices the asset", func() {
+		_, hasRate, err := price.NewFallbackProvider(0, &stubProvider{name: "empty"}).ResolvePrice(ctx, asset, money.USD)
+		Expect(err).ToNot(HaveOccurred(), "resolving the price should not fail")
+		Expect(hasRate).To(BeFalse(), "no price should be found")
+	})
+
+	It("falls back past a provider whose quote is older than the maximum age", func() {
+		stale := &stubProvider{name: "stale", price: "1.5", updatedAt: time.Now().Add(-2 * time.Hour)}
+		fresh := &stubProvider{name: "fresh", price: "2.5", updatedAt: time.Now().Add(-time.Minute)}
+
+		rate, hasRate, err := price.NewFallbackProvider(time.Hour, stale, fresh).ResolvePrice(ctx, asset, money.USD)
+		Expect(err).ToNot(HaveOccurred(), "resolving the price should not fail")
+		Expect(hasRate).To(BeTrue(), "a price should be found")
+		Expect(rate.Price.String()).To(Equal("2.5"), "the price of the provider with a fresh quote should be used")
+	})
+
+	It("reports a quote older than the maximum age if no provider prices the asset", func() {
+		stale := &stubProvider{name: "stale", price: "1.5", updatedAt: time.Now().Add(-2 * time.Hour)}
+
+		_, hasRate, err := price.NewFallbackProvider(time.Hour, stale).ResolvePrice(ctx, asset, money.USD)
+		Expect(err).To(MatchError(ContainSubstring("stale: quote was last updated")), "the stale quote should be reported")
+		Expect(hasRate).To(BeFalse(), "no price should be found")
+	})
+
+	It("accepts quotes whose update time is not known regardless of the maximum age", func() {
+		undated := &stubProvider{name: "undated", price: "1.5"}
+
+		rate, hasRate, err := price.NewFallbackProvider(time.Hour, undated).ResolvePrice(ctx, asset, money.USD)
+		Expect(err).ToNot(HaveOccurred(), "resolving the price should not fail")
+		Expect(hasRate).To(BeTrue(), "a price should be found")
+		Expect(rate.Price.String()).To(Equal("1.5"), "the undated quote should be used")
+	})
+
+	It("prices an asset with a fixed price without consulting the providers", func() {
+		fixedPrice := money.NewDecimalFromInt(1)
+		asset.FixedPrice = &fixedPrice
+		provider := &stubBatchingProvider{stubProvider: stubProvider{name: "batching", price: "2"}}
+
+		fallbackProvider := price.NewFallbackProvider(0, provider)
+		fallbackProvider.RegisterAsset(ctx, asset, money.USD)
+
+		rate, hasRate, err := fallbackProvider.ResolvePrice(ctx, asset, money.USD)
+		Expect(err).ToNot(HaveOccurred(), "resolving the price should not fail")
+		Expect(hasRate).To(BeTrue(), "a price should be found")
+		Expect(rate.Price.String()).To(Equal("1"), "the fixed price should be used")
+		Expect(provider.resolveCount).To(BeZero(), "the provider should not be consulted")
+		Expect(provider.registered).To(BeEmpty(), "the asset should not be registered with the provider")
+	})
+
+	It("registers assets with the providers that batch", func() {
+		batching := &stubBatchingProvider{stubProvider: stubProvider{name: "batching"}}
+
+		provider := price.NewFallbackProvider(0, &stubProvider{name: "plain"}, batching)
+		provider.RegisterAsset(ctx, asset, money.USD)
+		Expect(batching.registered).To(ConsistOf(asset), "the asset should be registered with the batching provider")
+		Expect(provider.Name()).To(Equal("plain, batching"), "the name should list the providers in order")
+	})
+})
+
+type stubProvider struct {
+	name         string
+	price        string
+	updatedAt    time.Time
+	err          error
+	resolveCount int
+}
+
+func (s *stubProvider) Name() string {
+	return s.name
+}
+
+func (s *stubProvider) ResolvePrice(_ context.Context, _ price.Asset, _ money.Currency) (price.Quote, bool, error) {
+	s.resolveCount++
+
+	if s.err != nil {
+		return price.Quote{}, false, s.err
+	}
+
+	if s.price == "" {
+		return price.Quote{}, false, nil
+	}
+
+	parsed, err := money.ParseDecimal(s.price)
+	return price.Quote{Price: parsed, UpdatedAt: s.updatedAt}, true, err
+}
+
+type stubBatchingProvider struct {
+	stubProvider
+	registered []price.Asset
+}
+
+func (s *stubBatchingProvider) RegisterAsset(_ context.Context, asset price.Asset, _ money.Currency) {
+	s.registered = append(s.registered, asset)
+}
@@ -0,0 +1,184 @@
+package price
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jrh3k5/cryptonabber-sync/v3/money"
+)
+
+// RateHistory records the rate at which each account was last valued, so that the rate at which it is next valued
+// can be compared against it.
+//
+// A rate that is rejected is kept as pending rather than replacing the rate at which the account was last valued, and is
+// only accepted once a later sync confirms it with a freshly retrieved quote, so that a glitched quote that has been cached
+// cannot confirm itself.
+type RateHistory struct {
+	file  string                  // the location of the file in which the history is kept; if blank, the history is not kept on disk
+	rates map[string]recordedRate // account name -> the rate at which the account was last valued
+}
+
+// LoadRateHistory loads the history kept in the given file.
+// If the file does not exist, or if no file is given, the history starts out empty.
+func LoadRateHistory(file string) (*RateHistory, error) {
+	history := &RateHistory{
+		file:  file,
+		rates: make(map[string]recordedRate),
+	}
+
+	if file == "" {
+		return history, nil
+	}
+
+	historyBytes, err := os.ReadFile(file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return history, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read rate history file '%s': %w", file, err)
+	}
+
+	if unmarshalErr := json.Unmarshal(historyBytes, &history.rates); unmarshalErr != nil {
+		return nil, fmt.Errorf("failed to unmarshal rate history file '%s': %w", file, unmarshalErr)
+	}
+
+	return history, nil
+}
+
+// PreviousRate gets the rate, in the given currency, at which the account with the given name was last valued.
+// The returned bool is false if the account has not been valued in that currency before.
+func (r *RateHistory) PreviousRate(accountName string, currency money.Currency) (money.Decimal, bool) {
+	recorded, hasRecorded := r.rates[accountName]
+	if !hasRecorded || recorded.Currency != currency.Code {
+		return money.Decimal{}, false
+	}
+
+	rate, err := money.ParseDecimal(recorded.Rate)
+	if err != nil {
+		return money.Decimal{}, false
+	}
+
+	return rate, true
+}
+
+// Record records the rate, in the given currency, at which the account with the given name was valued.
+// Any rate pending for the account is discarded.
+func (r *RateHistory) Record(accountName string, currency money.Currency, rate money.Decimal) {
+	r.rates[accountName] = recordedRate{
+		Currency:   currency.Code,
+		Rate:       rate.String(),
+		RecordedAt: time.Now(),
+	}
+}
+
+// Reject records the given rate, in the given currency, as pending for the account with the given name, leaving the rate
+// at which the account was last valued unchanged. It does nothing if the account has not been valued in that currency before.
+func (r *RateHistory) Reject(accountName string, currency money.Currency, rate money.Decimal) {
+	recorded, hasRecorded := r.rates[accountName]
+	if !hasRecorded || recorded.Currency != currency.Code {
+		return
+	}
+
+	recorded.PendingRate = rate.String()
+	r.rates[accountName] = recorded
+}
+
+// HasPendingRate determines whether a rate, in the given currency, that was rejected for the account with the given name is pending.
+func (r *RateHistory) HasPendingRate(accountName string, currency money.Currency) bool {
+	_, hasPendingRate := r.pendingRate(accountName, currency)
+	return hasPendingRate
+}
+
+// Observe compares the given rate, in the given currency, at which the account with the given name is being valued against the rate
+// at which it was last valued. If a rejected rate is pending for the account and the given rate is from freshly retrieved quotes,
+// the given rate is also compared against the pending rate, and whichever of the two rates is closer to the given rate is used,
+// so that a lasting change in rate is accepted once a fresh quote confirms it.
+// The rate compared against, and the change from it in percent, are returned; the returned bool is false if the account has not been valued
+// in that currency before. The given rate is not recorded; it is up to the caller to Record or Reject it.
+func (r *RateHistory) Observe(accountName string, currency money.Currency, rate money.Decimal, isFresh bool) (money.Decimal, float64, bool) {
+	previousRate, hasPreviousRate := r.PreviousRate(accountName, currency)
+	if !hasPreviousRate {
+		return money.Decimal{}, 0, false
+	}
+
+	changePercent := RateChangePercent(previousRate, rate)
+	if !isFresh {
+		return previousRate, changePercent, true
+	}
+
+	if pendingRate, hasPendingRate := r.pendingRate(accountName, currency); hasPendingRate {
+		if pendingChangePercent := RateChangePercent(pendingRate, rate); math.Abs(pendingChangePercent) < math.Abs(changePercent) {
+			return pendingRate, pendingChangePercent, true
+		}
+	}
+
+	return previousRate, changePercent, true
+}
+
+// pendingRate gets the rejected rate, in the given currency, that is pending for the account with the given name.
+// The returned bool is false if no rate is pending.
+func (r *RateHistory) pendingRate(accountName string, currency money.Currency) (money.Decimal, bool) {
+	recorded, hasRecorded := r.rates[accountName]
+	if !hasRecorded || recorded.Currency != currency.Code || recorded.PendingRate == "" {
+		return money.Decimal{}, false
+	}
+
+	pendingRate, err := money.ParseDecimal(recorded.PendingRate)
+	if err != nil {
+		return money.Decimal{}, false
+	}
+
+	return pendingRate, true
+}
+
+// Save writes the history to its file.
+func (r *RateHistory) Save() error {
+	if r.file == "" {
+		return nil
+	}
+
+	historyBytes, err := json.Marshal(r.rates)
+	if err != nil {
+		return fmt.Errorf("failed to marshal rate history: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(r.file), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory for rate history file '%s': %w", r.file, err)
+	}
+
+	if err := os.WriteFile(r.file, historyBytes, 0o644); err != nil {
+		return fmt.Errorf("failed to write rate history file '%s': %w", r.file, err)
+	}
+
+	return nil
+}
+
+// RateChangePercent gets the change, in percent, from the given previous rate to the given rate.
+// A change from a rate of zero to any other rate is an infinite change.
+func RateChangePercent(previousRate money.Decimal, rate money.Decimal) float64 {
+	if previousRate.IsZero() {
+		if rate.IsZero() {
+			return 0
+		}
+
+		return math.Inf(rate.Sign())
+	}
+
+	change := new(big.Rat).Quo(rate.Sub(previousRate).Rat(), previousRate.Abs().Rat())
+	changePercent, _ := new(big.Rat).Mul(change, big.NewRat(100, 1)).Float64()
+
+	return changePercent
+}
+
+type recordedRate struct {
+	Currency    string    `json:"currency"`
+	Rate        string    `json:"rate"`
+	RecordedAt  time.Time `json:"recorded_at"`
+	PendingRate string    `json:"pending_rate,omitempty"` // the last rate that was rejected since the rate was recorded, if any
+}
@@ -0,0 +1,188 @@
+package price_test
+
+import (
+	"context"
+	"math"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jrh3k5/cryptonabber-sync/v3/cache"
+	"github.com/jrh3k5/cryptonabber-sync/v3/money"
+	"github.com/jrh3k5/cryptonabber-sync/v3/price"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RateHistory", func() {
+	var historyFile string
+
+	BeforeEach(func() {
+		historyFile = filepath.Join(GinkgoT().TempDir(), "history", "rates.json")
+	})
+
+	It("remembers recorded rates across loads", func() {
+		history, err := price.LoadRateHistory(historyFile)
+		Expect(err).ToNot(HaveOccurred(), "loading a history that does not exist yet should not fail")
+
+		_, hasPreviousRate := history.PreviousRate("Account", money.USD)
+		Expect(hasPreviousRate).To(BeFalse(), "a new history should have no rates")
+
+		history.Record("Account", money.USD, money.NewDecimalFromInt(1234))
+		Expect(history.Save()).To(Succeed(), "saving the history should not fail")
+
+		reloaded, err := price.LoadRateHistory(historyFile)
+		Expect(err).ToNot(HaveOccurred(), "loading the saved history should not fail")
+
+		previousRate, hasPreviousRate := reloaded.PreviousRate("Account", money.USD)
+		Expect(hasPreviousRate).To(BeTrue(), "the recorded rate should be remembered")
+		Expect(previousRate.String()).To(Equal("1234"), "the recorded rate should be remembered as-is")
+
+		_, hasPreviousRate = reloaded.PreviousRate("Account", money.CurrencyForCode("EUR"))
+		Expect(hasPreviousRate).To(BeFalse(), "a rate recorded in another currency should not be used")
+	})
+
+	It("keeps a rejected rate pending without replacing the recorded rate", func() {
+		history, err := price.LoadRateHistory(historyFile)
+		Expect(err).ToNot(HaveOccurred(), "loading the history should not fail")
+		history.Record("Account", money.USD, money.NewDecimalFromInt(100))
+		history.Reject("Account", money.USD, money.NewDecimalFromInt(200))
+		Expect(history.Save()).To(Succeed(), "saving the history should not fail")
+
+		reloaded, err := price.LoadRateHistory(historyFile)
+		Expect(err).ToNot(HaveOccurred(), "reloading the history should not fail")
+		Expect(reloaded.HasPendingRate("Account", money.USD)).To(BeTrue(), "the rejected rate should be pending")
+
+		previousRate, hasPreviousRate := reloaded.PreviousRate("Account", money.USD)
+		Expect(hasPreviousRate).To(BeTrue(), "the recorded rate should be kept")
+		Expect(previousRate.String()).To(Equal("100"), "the rejected rate should not replace the recorded rate")
+
+		previousRate, changePercent, _ := reloaded.Observe("Account", money.USD, money.NewDecimalFromInt(200), false)
+		Expect(previousRate.String()).To(Equal("100"), "a rate from quotes that may have been cached should only be compared against the recorded rate")
+		Expect(changePercent).To(Equal(100.0), "the change from the recorded rate should be seen")
+
+		previousRate, changePercent, _ = reloaded.Observe("Account", money.USD, money.NewDecimalFromInt(200), true)
+		Expect(previousRate.String()).To(Equal("200"), "a rate from fresh quotes should be compared against the pending rate")
+		Expect(changePercent).To(BeZero(), "a fresh rate that confirms the pending rate should not be seen as a change")
+
+		previousRate, _, _ = reloaded.Observe("Account", money.USD, money.NewDecimalFromInt(101), true)
+		Expect(previousRate.String()).To(Equal("100"), "a fresh rate closer to the recorded rate should be compared against the recorded rate")
+
+		reloaded.Record("Account", money.USD, money.NewDecimalFromInt(200))
+		Expect(reloaded.HasPendingRate("Account", money.USD)).To(BeFalse(), "recording a rate should discard the pending rate")
+	})
+
+	Context("guarding rates across syncs", func() {
+		const maxChangePercent = 50.0
+
+		var ctx context.Context
+		var cacheFile string
+		var asset price.Asset
+		var delegate *stubBatchingProvider
+
+		// sync values an account as cmd/main.go does, returning whether its rate was accepted
+		sync := func() bool {
+			history, err := price.LoadRateHistory(historyFile)
+			Expect(err).ToNot(HaveOccurred(), "loading the history should not fail")
+
+			fileCache := cache.LoadFile(cacheFile)
+			cachingProvider := price.NewCachingProvider(delegate, fileCache, time.Hour, 0)
+
+			var provider price.BatchingProvider = cachingProvider
+			hasFreshQuotes := false
+			if history.HasPendingRate("Account", money.USD) {
+				provider = cachingProvider.Uncached()
+				hasFreshQuotes = true
+			}
+
+			provider.RegisterAsset(ctx, asset, money.USD)
+			quote, hasQuote, err := provider.ResolvePrice(ctx, asset, money.USD)
+			Expect(err).ToNot(HaveOccurred(), "resolving the price should not fail")
+			Expect(hasQuote).To(BeTrue(), "the price should be found")
+
+			_, changePercent, hasPreviousRate := history.Observe("Account", money.USD, quote.Price, hasFreshQuotes)
+			isAccepted := !hasPreviousRate || math.Abs(changePercent) <= maxChangePercent
+			if isAccepted {
+				history.Record("Account", money.USD, quote.Price)
+			} else {
+				history.Reject("Account", money.USD, quote.Price)
+			}
+
+			Expect(history.Save()).To(Succeed(), "saving the history should not fail")
+			Expect(fileCache.Save()).To(Succeed(), "saving the cache should not fail")
+
+			return isAccepted
+		}
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			cacheFile = filepath.Join(GinkgoT().TempDir(), "cache.json")
+
+			contractAddress := "0xGUARDED"
+			asset = price.Asset{
+				ChainName:       chainName,
+				ChainID:         big.NewInt(1),
+				ContractAddress: &contractAddress,
+			}
+
+			history, err := price.LoadRateHistory(historyFile)
+			Expect(err).ToNot(HaveOccurred(), "loading the history should not fail")
+			history.Record("Account", money.USD, money.NewDecimalFromInt(100))
+			Expect(history.Save()).To(Succeed(), "saving the history should not fail")
+
+			delegate = &stubBatchingProvider{stubProvider: stubProvider{name: "glitching", price: "1000"}}
+		})
+
+		It("does not let a cached glitched quote confirm itself", func() {
+			Expect(sync()).To(BeFalse(), "the first sync should reject the glitched rate")
+
+			delegate.price = "101"
+			Expect(sync()).To(BeTrue(), "the second sync should accept the rate of the fresh quote")
+			Expect(delegate.resolveCount).To(Equal(2), "the second sync should not use the cached glitched quote")
+
+			history, err := price.LoadRateHistory(historyFile)
+			Expect(err).ToNot(HaveOccurred(), "loading the history should not fail")
+			previousRate, _ := history.PreviousRate("Account", money.USD)
+			Expect(previousRate.String()).To(Equal("101"), "the rate of the fresh quote should be recorded")
+			Expect(history.HasPendingRate("Account", money.USD)).To(BeFalse(), "the glitched rate should no longer be pending")
+		})
+
+		It("accepts a lasting change in rate once a fresh quote confirms it", func() {
+			Expect(sync()).To(BeFalse(), "the first sync should reject the changed rate")
+			Expect(sync()).To(BeTrue(), "the second sync should accept the changed rate once a fresh quote confirms it")
+			Expect(delegate.resolveCount).To(Equal(2), "the second sync should retrieve the quote afresh")
+		})
+	})
+
+	It("fails to load a malformed history", func() {
+		Expect(os.MkdirAll(filepath.Dir(historyFile), 0o755)).To(Succeed(), "the history directory should be creatable")
+		Expect(os.WriteFile(historyFile, []byte("not JSON"), 0o644)).To(Succeed(), "the history file should be writable")
+
+		_, err := price.LoadRateHistory(historyFile)
+		Expect(err).To(HaveOccurred(), "a malformed history should not be loaded")
+	})
+
+	It("does not keep a history without a file", func() {
+		history, err := price.LoadRateHistory("")
+		Expect(err).ToNot(HaveOccurred(), "loading the history should not fail")
+
+		history.Record("Account", money.USD, money.NewDecimalFromInt(1))
+		Expect(history.Save()).To(Succeed(), "saving the history should do nothing")
+	})
+})
+
+var _ = DescribeTable("RateChangePercent", func(previousRate string, rate string, expectedPercent float64) {
+	parsedPreviousRate, err := money.ParseDecimal(previousRate)
+	Expect(err).ToNot(HaveOccurred(), "the previous rate should parse")
+
+	parsedRate, err := money.ParseDecimal(rate)
+	Expect(err).ToNot(HaveOccurred(), "the rate should parse")
+
+	Expect(price.RateChangePercent(parsedPreviousRate, parsedRate)).To(Equal(expectedPercent), "the change should be calculated from the previous rate")
+},
+	Entry("an increase", "2", "3", 50.0),
+	Entry("a decrease", "4", "1", -75.0),
+	Entry("no change", "1.5", "1.5", 0.0),
+	Entry("a change from zero", "0", "1", math.Inf(1)),
+	Entry("no change from zero", "0", "0", 0.0))
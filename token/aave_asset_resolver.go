@@ -0,0 +1,46 @@
+package token
+
+import (
+	"context"
+
+	"github.com/jrh3k5/cryptonabber-sync/v3/config"
+	rpcconfig "github.com/jrh3k5/cryptonabber-sync/v3/config/rpc"
+	"github.com/jrh3k5/cryptonabber-sync/v3/http/json/rpc"
+)
+
+// underlyingAssetAddressFunction is the function by which aTokens and debt tokens express the asset supplied to or borrowed from an Aave v3 market.
+const underlyingAssetAddressFunction = "UNDERLYING_ASSET_ADDRESS"
+
+// AaveSupplyAssetResolver resolves the asset supplied to an Aave v3 market through an aToken.
+type AaveSupplyAssetResolver struct {
+	rpcConfigurationResolver rpcconfig.ConfigurationResolver
+	ethCaller                rpc.EthCaller
+}
+
+func NewAaveSupplyAssetResolver(rpcConfigurationResolver rpcconfig.ConfigurationResolver, ethCaller rpc.EthCaller) *AaveSupplyAssetResolver {
+	return &AaveSupplyAssetResolver{
+		rpcConfigurationResolver: rpcConfigurationResolver,
+		ethCaller:                ethCaller,
+	}
+}
+
+func (a *AaveSupplyAssetResolver) ResolveAssetAddress(ctx context.Context, onchainAccount *config.AaveSupplyAccount) (*string, error) {
+	return resolveAddressFunction(ctx, a.rpcConfigurationResolver, a.ethCaller, onchainAccount.OnchainAsset, onchainAccount.ATokenAddress, underlyingAssetAddressFunction)
+}
+
+// AaveDebtAssetResolver resolves the asset borrowed through an Aave v3 debt token.
+type AaveDebtAssetResolver struct {
+	rpcConfigurationResolver rpcconfig.ConfigurationResolver
+	ethCaller                rpc.EthCaller
+}
+
+func NewAaveDebtAssetResolver(rpcConfigurationResolver rpcconfig.ConfigurationResolver, ethCaller rpc.EthCaller) *AaveDebtAssetResolver {
+	return &AaveDebtAssetResolver{
+		rpcConfigurationResolver: rpcConfigurationResolver,
+		ethCaller:                ethCaller,
+	}
+}
+
+func (a *AaveDebtAssetResolver) ResolveAssetAddress(ctx context.Context, onchainAccount *config.AaveDebtAccount) (*string, error) {
+	return resolveAddressFunction(ctx, a.rpcConfigurationResolver, a.ethCaller, onchainAccount.OnchainAsset, onchainAccount.DebtTokenAddress, underlyingAssetAddressFunction)
+}
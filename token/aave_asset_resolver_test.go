@@ -0,0 +1,79 @@
+package token_test
+
+import (
+	"context"
+	"net/http"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jrh3k5/cryptonabber-sync/v3/config"
+	"github.com/jrh3k5/cryptonabber-sync/v3/http/json/rpc"
+	"github.com/jrh3k5/cryptonabber-sync/v3/token"
+)
+
+var _ = Describe("AaveSupplyAssetResolver", func() {
+	var aaveSupplyAssetResolver *token.AaveSupplyAssetResolver
+
+	var ctx context.Context
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		aaveSupplyAssetResolver = token.NewAaveSupplyAssetResolver(rpcConfigurationResolver, rpc.NewDirectEthCaller(http.DefaultClient, rpc.NewLatestBlockResolver()))
+	})
+
+	Describe("ResolveAssetAddress", func() {
+		It("returns the address of the supplied asset", func() {
+			aTokenAddress := "0x98C23E9d8f34FEFb1B7BD6a91B7FF122F4e16F5c"
+			underlyingAddress := "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"
+
+			evmNode.RegisterETHCallCall("UNDERLYING_ASSET_ADDRESS", aTokenAddress, nil, func(_ string, _ []string) (rpc.MockEVMNodeRPCResult, *rpc.MockEVMNodeRPCError, error) {
+				return rpc.NewMockEVMNodeRPCAddressResult(underlyingAddress), nil, nil
+			})
+
+			address, err := aaveSupplyAssetResolver.ResolveAssetAddress(ctx, &config.AaveSupplyAccount{
+				OnchainAsset: config.OnchainAsset{
+					ChainName: chainName,
+				},
+				ATokenAddress: aTokenAddress,
+			})
+
+			Expect(err).To(BeNil(), "resolving the asset address should not fail")
+			Expect(*address).To(Equal(underlyingAddress), "the asset address should be the underlying asset address")
+		})
+	})
+})
+
+var _ = Describe("AaveDebtAssetResolver", func() {
+	var aaveDebtAssetResolver *token.AaveDebtAssetResolver
+
+	var ctx context.Context
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		aaveDebtAssetResolver = token.NewAaveDebtAssetResolver(rpcConfigurationResolver, rpc.NewDirectEthCaller(http.DefaultClient, rpc.NewLatestBlockResolver()))
+	})
+
+	Describe("ResolveAssetAddress", func() {
+		It("returns the address of the borrowed asset", func() {
+			debtTokenAddress := "0x72E95b8931767C79bA4EeE721354d6E99a61D004"
+			underlyingAddress := "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"
+
+			evmNode.RegisterETHCallCall("UNDERLYING_ASSET_ADDRESS", debtTokenAddress, nil, func(_ string, _ []string) (rpc.MockEVMNodeRPCResult, *rpc.MockEVMNodeRPCError, error) {
+				return rpc.NewMockEVMNodeRPCAddressResult(underlyingAddress), nil, nil
+			})
+
+			address, err := aaveDebtAssetResolver.ResolveAssetAddress(ctx, &config.AaveDebtAccount{
+				OnchainAsset: config.OnchainAsset{
+					ChainName: chainName,
+				},
+				DebtTokenAddress: debtTokenAddress,
+			})
+
+			Expect(err).To(BeNil(), "resolving the asset address should not fail")
+			Expect(*address).To(Equal(underlyingAddress), "the asset address should be the underlying asset address")
+		})
+	})
+})
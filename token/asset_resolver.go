@@ -2,8 +2,13 @@ package token
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/jrh3k5/cryptonabber-sync/v3/config"
+	"github.com/jrh3k5/cryptonabber-sync/v3/config/chain"
+	rpcconfig "github.com/jrh3k5/cryptonabber-sync/v3/config/rpc"
+	"github.com/jrh3k5/cryptonabber-sync/v3/evm/abi"
+	"github.com/jrh3k5/cryptonabber-sync/v3/http/json/rpc"
 )
 
 // AssetResolver is a function that resolves the address for the onchain asset that represents the value of the token.
@@ -12,3 +17,25 @@ type AssetResolver[M config.OnchainAccount] interface {
 	// If this returns nil, it means it is for an asset that has no contract address on the asset's network.
 	ResolveAssetAddress(ctx context.Context, onchainAccount M) (*string, error)
 }
+
+// resolveAddressFunction calls the given function, which takes no arguments and returns an address, on the given contract.
+func resolveAddressFunction(ctx context.Context, rpcConfigurationResolver rpcconfig.ConfigurationResolver, ethCaller rpc.EthCaller, onchainAsset config.OnchainAsset, contractAddress string, functionName string) (*string, error) {
+	rpcURL, err := ResolveRPCURL(ctx, rpcConfigurationResolver, onchainAsset, chain.TypeEVM)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve RPC URL: %w", err)
+	}
+
+	result, err := ethCaller.EthCall(ctx, rpcURL, functionName, contractAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute %s: %w", functionName, err)
+	}
+
+	decoded, err := abi.DecodeHex([]string{"address"}, result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode result of %s: %w", functionName, err)
+	}
+
+	address := decoded[0].(string)
+
+	return &address, nil
+}
@@ -0,0 +1,52 @@
+package balance
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/jrh3k5/cryptonabber-sync/v3/config"
+)
+
+// AaveSupplyFetcher is a Fetcher implementation for Aave v3 aTokens.
+// aTokens rebase, so the balance of an aToken is the amount of the underlying asset supplied, including accrued interest.
+type AaveSupplyFetcher struct {
+	erc20Fetcher Fetcher[*config.ERC20Account]
+}
+
+// NewAaveSupplyFetcher creates a new AaveSupplyFetcher.
+func NewAaveSupplyFetcher(erc20Fetcher Fetcher[*config.ERC20Account]) *AaveSupplyFetcher {
+	return &AaveSupplyFetcher{
+		erc20Fetcher: erc20Fetcher,
+	}
+}
+
+func (f *AaveSupplyFetcher) FetchBalance(ctx context.Context, onchainAccount *config.AaveSupplyAccount) (*big.Int, error) {
+	return f.erc20Fetcher.FetchBalance(ctx, &config.ERC20Account{
+		SyncableAccount: onchainAccount.SyncableAccount,
+		OnchainAsset:    onchainAccount.OnchainAsset,
+		OnchainWallet:   onchainAccount.OnchainWallet,
+		TokenAddress:    onchainAccount.ATokenAddress,
+	})
+}
+
+// AaveDebtFetcher is a Fetcher implementation for Aave v3 debt tokens.
+// The balance of a debt token is the amount of the underlying asset owed, including accrued interest.
+type AaveDebtFetcher struct {
+	erc20Fetcher Fetcher[*config.ERC20Account]
+}
+
+// NewAaveDebtFetcher creates a new AaveDebtFetcher.
+func NewAaveDebtFetcher(erc20Fetcher Fetcher[*config.ERC20Account]) *AaveDebtFetcher {
+	return &AaveDebtFetcher{
+		erc20Fetcher: erc20Fetcher,
+	}
+}
+
+func (f *AaveDebtFetcher) FetchBalance(ctx context.Context, onchainAccount *config.AaveDebtAccount) (*big.Int, error) {
+	return f.erc20Fetcher.FetchBalance(ctx, &config.ERC20Account{
+		SyncableAccount: onchainAccount.SyncableAccount,
+		OnchainAsset:    onchainAccount.OnchainAsset,
+		OnchainWallet:   onchainAccount.OnchainWallet,
+		TokenAddress:    onchainAccount.DebtTokenAddress,
+	})
+}
@@ -0,0 +1,55 @@
+package balance_test
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/jrh3k5/cryptonabber-sync/v3/config"
+	"github.com/jrh3k5/cryptonabber-sync/v3/token/balance"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AaveSupplyFetcher", func() {
+	var erc20Fetcher *testERC20Fetcher
+	var aaveSupplyFetcher *balance.AaveSupplyFetcher
+
+	BeforeEach(func() {
+		erc20Fetcher = newTestERC20Fetcher()
+		aaveSupplyFetcher = balance.NewAaveSupplyFetcher(erc20Fetcher)
+	})
+
+	Context("FetchBalance", func() {
+		It("returns the balance of the aToken", func() {
+			erc20Fetcher.setBalance("0xatoken", big.NewInt(7500))
+			balance, err := aaveSupplyFetcher.FetchBalance(context.Background(), &config.AaveSupplyAccount{
+				ATokenAddress: "0xatoken",
+			})
+
+			Expect(err).ToNot(HaveOccurred(), "fetching the balance should not fail")
+			Expect(balance).To(Equal(big.NewInt(7500)), "the balance of the aToken should be returned")
+		})
+	})
+})
+
+var _ = Describe("AaveDebtFetcher", func() {
+	var erc20Fetcher *testERC20Fetcher
+	var aaveDebtFetcher *balance.AaveDebtFetcher
+
+	BeforeEach(func() {
+		erc20Fetcher = newTestERC20Fetcher()
+		aaveDebtFetcher = balance.NewAaveDebtFetcher(erc20Fetcher)
+	})
+
+	Context("FetchBalance", func() {
+		It("returns the balance of the debt token", func() {
+			erc20Fetcher.setBalance("0xdebt", big.NewInt(2500))
+			balance, err := aaveDebtFetcher.FetchBalance(context.Background(), &config.AaveDebtAccount{
+				DebtTokenAddress: "0xdebt",
+			})
+
+			Expect(err).ToNot(HaveOccurred(), "fetching the balance should not fail")
+			Expect(balance).To(Equal(big.NewInt(2500)), "the balance of the debt token should be returned")
+		})
+	})
+})
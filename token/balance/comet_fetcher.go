@@ -0,0 +1,70 @@
+package balance
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/jrh3k5/cryptonabber-sync/v3/config"
+	"github.com/jrh3k5/cryptonabber-sync/v3/config/chain"
+	rpcconfig "github.com/jrh3k5/cryptonabber-sync/v3/config/rpc"
+	"github.com/jrh3k5/cryptonabber-sync/v3/evm/abi"
+	"github.com/jrh3k5/cryptonabber-sync/v3/http/json/rpc"
+	"github.com/jrh3k5/cryptonabber-sync/v3/token"
+)
+
+// CometSupplyFetcher is a Fetcher implementation for supplies of the base asset of Compound v3 (Comet) markets.
+// A Comet's balanceOf is the amount of the base asset supplied, including accrued interest.
+type CometSupplyFetcher struct {
+	erc20Fetcher Fetcher[*config.ERC20Account]
+}
+
+// NewCometSupplyFetcher creates a new CometSupplyFetcher.
+func NewCometSupplyFetcher(erc20Fetcher Fetcher[*config.ERC20Account]) *CometSupplyFetcher {
+	return &CometSupplyFetcher{
+		erc20Fetcher: erc20Fetcher,
+	}
+}
+
+func (f *CometSupplyFetcher) FetchBalance(ctx context.Context, onchainAccount *config.CometSupplyAccount) (*big.Int, error) {
+	return f.erc20Fetcher.FetchBalance(ctx, &config.ERC20Account{
+		SyncableAccount: onchainAccount.SyncableAccount,
+		OnchainAsset:    onchainAccount.OnchainAsset,
+		OnchainWallet:   onchainAccount.OnchainWallet,
+		TokenAddress:    onchainAccount.CometAddress,
+	})
+}
+
+// CometDebtFetcher is a Fetcher implementation for borrow positions in Compound v3 (Comet) markets.
+// The balance of a borrow position is the amount of the market's base asset owed, including accrued interest.
+type CometDebtFetcher struct {
+	rpcConfigurationResolver rpcconfig.ConfigurationResolver
+	ethCaller                rpc.EthCaller
+}
+
+// NewCometDebtFetcher creates a new CometDebtFetcher.
+func NewCometDebtFetcher(rpcConfigurationResolver rpcconfig.ConfigurationResolver, ethCaller rpc.EthCaller) *CometDebtFetcher {
+	return &CometDebtFetcher{
+		rpcConfigurationResolver: rpcConfigurationResolver,
+		ethCaller:                ethCaller,
+	}
+}
+
+func (c *CometDebtFetcher) FetchBalance(ctx context.Context, onchainAccount *config.CometDebtAccount) (*big.Int, error) {
+	rpcURL, err := token.ResolveRPCURL(ctx, c.rpcConfigurationResolver, onchainAccount.OnchainAsset, chain.TypeEVM)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve RPC URL: %w", err)
+	}
+
+	result, err := c.ethCaller.EthCall(ctx, rpcURL, "borrowBalanceOf", onchainAccount.CometAddress, rpc.Arg("address", onchainAccount.WalletAddress))
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute borrowBalanceOf: %w", err)
+	}
+
+	decoded, err := abi.DecodeHex([]string{"uint256"}, result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode borrowBalanceOf result: %w", err)
+	}
+
+	return decoded[0].(*big.Int), nil
+}
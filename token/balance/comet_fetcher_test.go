@@ -0,0 +1,81 @@
+package balance_test
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+
+	"github.com/jrh3k5/cryptonabber-sync/v3/config"
+	"github.com/jrh3k5/cryptonabber-sync/v3/http/json/rpc"
+	"github.com/jrh3k5/cryptonabber-sync/v3/token/balance"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CometSupplyFetcher", func() {
+	var erc20Fetcher *testERC20Fetcher
+	var cometSupplyFetcher *balance.CometSupplyFetcher
+
+	BeforeEach(func() {
+		erc20Fetcher = newTestERC20Fetcher()
+		cometSupplyFetcher = balance.NewCometSupplyFetcher(erc20Fetcher)
+	})
+
+	Context("FetchBalance", func() {
+		It("returns the balance of the wallet in the market", func() {
+			erc20Fetcher.setBalance("0xcomet", big.NewInt(1200))
+			balance, err := cometSupplyFetcher.FetchBalance(context.Background(), &config.CometSupplyAccount{
+				CometAccount: config.CometAccount{
+					CometAddress: "0xcomet",
+				},
+			})
+
+			Expect(err).ToNot(HaveOccurred(), "fetching the balance should not fail")
+			Expect(balance).To(Equal(big.NewInt(1200)), "the supplied balance should be returned")
+		})
+	})
+})
+
+var _ = Describe("CometDebtFetcher", func() {
+	var fetcher *balance.CometDebtFetcher
+
+	var ctx context.Context
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		fetcher = balance.NewCometDebtFetcher(rpcConfigurationResolver, rpc.NewDirectEthCaller(http.DefaultClient, rpc.NewLatestBlockResolver()))
+	})
+
+	It("fetches the borrow balance of the wallet", func() {
+		cometAddress := "0xc3d688B66703497DAA19211EEdff47f25384cdc3"
+		walletAddress := "0x2870d53DcAc4763D6b0C030fbE0555405B09CDb3"
+		borrowBalance := big.NewInt(1500000000)
+
+		evmNode.RegisterETHCallCall("borrowBalanceOf", cometAddress, []string{"address"}, func(_ string, params []string) (rpc.MockEVMNodeRPCResult, *rpc.MockEVMNodeRPCError, error) {
+			expectedAddress := "000000000000000000000000" + strings.ToLower(strings.TrimPrefix(walletAddress, "0x"))
+			if len(params) != 1 || params[0] != expectedAddress {
+				return nil, nil, fmt.Errorf("expected the address %s, got: %s", expectedAddress, strings.Join(params, ", "))
+			}
+
+			return rpc.NewMockEVMNodeRPCNumericResult(borrowBalance), nil, nil
+		})
+
+		retrievedBalance, err := fetcher.FetchBalance(ctx, &config.CometDebtAccount{
+			CometAccount: config.CometAccount{
+				CometAddress: cometAddress,
+				OnchainAsset: config.OnchainAsset{
+					ChainName: chainName,
+				},
+				OnchainWallet: config.OnchainWallet{
+					WalletAddress: walletAddress,
+				},
+			},
+		})
+
+		Expect(err).ToNot(HaveOccurred(), "getting the borrow balance should not fail")
+		Expect(retrievedBalance).To(Equal(borrowBalance), "the borrow balance should be returned")
+	})
+})
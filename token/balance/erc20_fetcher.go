@@ -8,7 +8,7 @@ import (
 	"github.com/jrh3k5/cryptonabber-sync/v3/config"
 	"github.com/jrh3k5/cryptonabber-sync/v3/config/chain"
 	rpcconfig "github.com/jrh3k5/cryptonabber-sync/v3/config/rpc"
-	synchttp "github.com/jrh3k5/cryptonabber-sync/v3/http"
+	"github.com/jrh3k5/cryptonabber-sync/v3/evm/abi"
 	"github.com/jrh3k5/cryptonabber-sync/v3/http/json/rpc"
 	"github.com/jrh3k5/cryptonabber-sync/v3/token"
 )
@@ -16,14 +16,14 @@ import (
 // ERC20Fetcher is a Fetcher implementation for EVM chains.
 type ERC20Fetcher struct {
 	rpcConfigurationResolver rpcconfig.ConfigurationResolver
-	doer                     synchttp.Doer
+	ethCaller                rpc.EthCaller
 }
 
 // NewERC20Fetcher builds an ERC20Fetcher instance that communicates with the given node URL.
-func NewERC20Fetcher(rpcConfigurationResolver rpcconfig.ConfigurationResolver, doer synchttp.Doer) *ERC20Fetcher {
+func NewERC20Fetcher(rpcConfigurationResolver rpcconfig.ConfigurationResolver, ethCaller rpc.EthCaller) *ERC20Fetcher {
 	return &ERC20Fetcher{
 		rpcConfigurationResolver: rpcConfigurationResolver,
-		doer:                     doer,
+		ethCaller:                ethCaller,
 	}
 }
 
@@ -33,13 +33,15 @@ func (e *ERC20Fetcher) FetchBalance(ctx context.Context, onchainAccount *config.
 		return nil, fmt.Errorf("failed to resolve RPC URL: %w", err)
 	}
 
-	result, err := rpc.ExecuteEthCall(ctx, e.doer, rpcURL, "balanceOf", onchainAccount.TokenAddress, rpc.Arg("address", onchainAccount.WalletAddress))
+	result, err := e.ethCaller.EthCall(ctx, rpcURL, "balanceOf", onchainAccount.TokenAddress, rpc.Arg("address", onchainAccount.WalletAddress))
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute balanceOf: %w", err)
 	}
 
-	balance := big.NewInt(0)
-	balance.SetString(result[2:], 16)
+	decoded, err := abi.DecodeHex([]string{"uint256"}, result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode balanceOf result: %w", err)
+	}
 
-	return balance, nil
+	return decoded[0].(*big.Int), nil
 }
@@ -22,7 +22,7 @@ var _ = Describe("ERC20Fetcher", func() {
 	BeforeEach(func() {
 		ctx = context.Background()
 
-		fetcher = balance.NewERC20Fetcher(rpcConfigurationResolver, http.DefaultClient)
+		fetcher = balance.NewERC20Fetcher(rpcConfigurationResolver, rpc.NewDirectEthCaller(http.DefaultClient, rpc.NewLatestBlockResolver()))
 	})
 
 	It("fetches and retrieves the correct balance", func() {
@@ -35,7 +35,7 @@ var _ = Describe("ERC20Fetcher", func() {
 				return nil, nil, fmt.Errorf("expected 1 parameter, got %d: %s", len(params), strings.Join(params, ", "))
 			}
 
-			expectedAddress := strings.TrimPrefix(walletAddress, "0x")
+			expectedAddress := "000000000000000000000000" + strings.ToLower(strings.TrimPrefix(walletAddress, "0x"))
 			if params[0] != expectedAddress {
 				return nil, nil, fmt.Errorf("expected address to be %s, got %s", expectedAddress, params[0])
 			}
@@ -8,20 +8,22 @@ import (
 	"github.com/jrh3k5/cryptonabber-sync/v3/config"
 	"github.com/jrh3k5/cryptonabber-sync/v3/config/chain"
 	rpcconfig "github.com/jrh3k5/cryptonabber-sync/v3/config/rpc"
-	synchttp "github.com/jrh3k5/cryptonabber-sync/v3/http"
+	"github.com/jrh3k5/cryptonabber-sync/v3/evm/abi"
 	"github.com/jrh3k5/cryptonabber-sync/v3/http/json/rpc"
 	"github.com/jrh3k5/cryptonabber-sync/v3/token"
 )
 
 type ERC4262Fetcher struct {
 	rpcConfigurationResolver rpcconfig.ConfigurationResolver
-	doer                     synchttp.Doer
+	ethCaller                rpc.EthCaller
+	decimalsResolver         token.DecimalsResolver
 }
 
-func NewERC4262Fetcher(rpcConfigurationResolver rpcconfig.ConfigurationResolver, doer synchttp.Doer) *ERC4262Fetcher {
+func NewERC4262Fetcher(rpcConfigurationResolver rpcconfig.ConfigurationResolver, ethCaller rpc.EthCaller, decimalsResolver token.DecimalsResolver) *ERC4262Fetcher {
 	return &ERC4262Fetcher{
 		rpcConfigurationResolver: rpcConfigurationResolver,
-		doer:                     doer,
+		ethCaller:                ethCaller,
+		decimalsResolver:         decimalsResolver,
 	}
 }
 
@@ -31,21 +33,50 @@ func (e *ERC4262Fetcher) FetchBalance(ctx context.Context, onchainAccount *confi
 		return nil, fmt.Errorf("failed to resolve RPC URL: %w", err)
 	}
 
-	sharesResult, err := rpc.ExecuteEthCall(ctx, e.doer, rpcNodeURL, onchainAccount.BalanceFunctionName, onchainAccount.VaultAddress, rpc.Arg("address", onchainAccount.WalletAddress))
+	sharesResult, err := e.ethCaller.EthCall(ctx, rpcNodeURL, onchainAccount.BalanceFunctionName, onchainAccount.VaultAddress, rpc.Arg("address", onchainAccount.WalletAddress))
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute getShares: %w", err)
+		return nil, fmt.Errorf("failed to execute %s: %w", onchainAccount.BalanceFunctionName, err)
 	}
 
-	sharesBalance := big.NewInt(0)
-	sharesBalance.SetString(sharesResult[2:], 16)
-
-	assetsResult, err := rpc.ExecuteEthCall(ctx, e.doer, rpcNodeURL, "convertToAssets", onchainAccount.VaultAddress, rpc.Arg("uint256", sharesBalance))
+	decodedShares, err := abi.DecodeHex([]string{"uint256"}, sharesResult)
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute convertToAssets: %w", err)
+		return nil, fmt.Errorf("failed to decode %s result: %w", onchainAccount.BalanceFunctionName, err)
 	}
 
-	assetsBalance := big.NewInt(0)
-	assetsBalance.SetString(assetsResult[2:], 16)
+	sharesBalance := decodedShares[0].(*big.Int)
+
+	conversion := onchainAccount.Conversion
+	if conversion == nil {
+		conversion = &config.VaultConversion{
+			Function: "convertToAssets",
+			Argument: config.VaultConversionArgumentShares,
+		}
+	}
+
+	if conversion.Argument == config.VaultConversionArgumentShares {
+		assetsResult, err := e.ethCaller.EthCall(ctx, rpcNodeURL, conversion.Function, onchainAccount.VaultAddress, rpc.Arg("uint256", sharesBalance))
+		if err != nil {
+			return nil, fmt.Errorf("failed to execute %s: %w", conversion.Function, err)
+		}
+
+		decodedAssets, err := abi.DecodeHex([]string{"uint256"}, assetsResult)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode %s result: %w", conversion.Function, err)
+		}
+
+		return decodedAssets[0].(*big.Int), nil
+	}
+
+	// The value of a single share is expressed in the vault's own decimals unless the vault family is known to scale it otherwise
+	var conversionDecimals int
+	if conversion.Decimals != nil {
+		conversionDecimals = *conversion.Decimals
+	} else {
+		conversionDecimals, err = e.decimalsResolver.ResolveDecimals(ctx, onchainAccount.OnchainAsset, &onchainAccount.VaultAddress)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve decimals of vault: %w", err)
+		}
+	}
 
-	return assetsBalance, nil
+	return convertAtRate(ctx, e.ethCaller, rpcNodeURL, onchainAccount.VaultAddress, conversion.Function, conversionDecimals, sharesBalance)
 }
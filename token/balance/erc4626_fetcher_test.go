@@ -17,13 +17,18 @@ import (
 
 var _ = Describe("Erc4626Fetcher", func() {
 	var fetcher *balance.ERC4262Fetcher
+	var decimalsResolver *testDecimalsResolver
 
 	var ctx context.Context
 
 	BeforeEach(func() {
 		ctx = context.Background()
 
-		fetcher = balance.NewERC4262Fetcher(rpcConfigurationResolver, http.DefaultClient)
+		decimalsResolver = &testDecimalsResolver{
+			decimals: make(map[string]int),
+		}
+
+		fetcher = balance.NewERC4262Fetcher(rpcConfigurationResolver, rpc.NewDirectEthCaller(http.DefaultClient, rpc.NewLatestBlockResolver()), decimalsResolver)
 	})
 
 	Context("FetchBalance", func() {
@@ -39,7 +44,7 @@ var _ = Describe("Erc4626Fetcher", func() {
 					return nil, nil, fmt.Errorf("expected 1 parameter, got %d", len(params))
 				}
 
-				expectedAddress := strings.TrimPrefix(walletAddress, "0x")
+				expectedAddress := "000000000000000000000000" + strings.ToLower(strings.TrimPrefix(walletAddress, "0x"))
 				if params[0] != expectedAddress {
 					return nil, nil, fmt.Errorf("expected address '%s', got '%s'", expectedAddress, params[0])
 				}
@@ -78,5 +83,66 @@ var _ = Describe("Erc4626Fetcher", func() {
 
 			Expect(balance).To(Equal(assets), "the balance should be correct")
 		})
+
+		It("resolves the balance from a price per share in the vault's decimals", func() {
+			walletAddress := "0x4838B106FCe9647Bdf1E7877BF73cE8B0BAD5f97"
+			vaultAddress := "0xa354F35829Ae975e850e23e9615b11Da1B3dC4DE"
+
+			decimalsResolver.decimals[strings.ToLower(vaultAddress)] = 6
+			evmNode.RegisterETHCallCall("balanceOf", vaultAddress, []string{"address"}, func(_ string, _ []string) (rpc.MockEVMNodeRPCResult, *rpc.MockEVMNodeRPCError, error) {
+				return rpc.NewMockEVMNodeRPCNumericResult(big.NewInt(5000000)), nil, nil
+			})
+			evmNode.RegisterETHCallCall("pricePerShare", vaultAddress, nil, func(_ string, _ []string) (rpc.MockEVMNodeRPCResult, *rpc.MockEVMNodeRPCError, error) {
+				return rpc.NewMockEVMNodeRPCNumericResult(big.NewInt(1100000)), nil, nil
+			})
+
+			balance, err := fetcher.FetchBalance(ctx, &config.ERC4626Account{
+				OnchainAsset: config.OnchainAsset{
+					ChainName: chainName,
+				},
+				OnchainWallet: config.OnchainWallet{
+					WalletAddress: walletAddress,
+				},
+				VaultAddress:        vaultAddress,
+				BalanceFunctionName: "balanceOf",
+				Conversion: &config.VaultConversion{
+					Function: "pricePerShare",
+					Argument: config.VaultConversionArgumentNone,
+				},
+			})
+			Expect(err).ToNot(HaveOccurred(), "fetching the balance should not fail")
+			Expect(balance).To(Equal(big.NewInt(5500000)), "the shares should be scaled by the price per share")
+		})
+
+		It("resolves the balance from a price per share in configured decimals", func() {
+			walletAddress := "0x4838B106FCe9647Bdf1E7877BF73cE8B0BAD5f97"
+			vaultAddress := "0xF0B9e4A4F3e0A6e3Ab8C6d1c4F1e8c8E4c1A8C0e"
+			conversionDecimals := 18
+
+			evmNode.RegisterETHCallCall("balanceOf", vaultAddress, []string{"address"}, func(_ string, _ []string) (rpc.MockEVMNodeRPCResult, *rpc.MockEVMNodeRPCError, error) {
+				return rpc.NewMockEVMNodeRPCNumericResult(big.NewInt(2000000)), nil, nil
+			})
+			evmNode.RegisterETHCallCall("getPricePerFullShare", vaultAddress, nil, func(_ string, _ []string) (rpc.MockEVMNodeRPCResult, *rpc.MockEVMNodeRPCError, error) {
+				return rpc.NewMockEVMNodeRPCNumericResult(big.NewInt(1500000000000000000)), nil, nil
+			})
+
+			balance, err := fetcher.FetchBalance(ctx, &config.ERC4626Account{
+				OnchainAsset: config.OnchainAsset{
+					ChainName: chainName,
+				},
+				OnchainWallet: config.OnchainWallet{
+					WalletAddress: walletAddress,
+				},
+				VaultAddress:        vaultAddress,
+				BalanceFunctionName: "balanceOf",
+				Conversion: &config.VaultConversion{
+					Function: "getPricePerFullShare",
+					Argument: config.VaultConversionArgumentNone,
+					Decimals: &conversionDecimals,
+				},
+			})
+			Expect(err).ToNot(HaveOccurred(), "fetching the balance should not fail")
+			Expect(balance).To(Equal(big.NewInt(3000000)), "the shares should be scaled by the price per share in its configured decimals")
+		})
 	})
 })
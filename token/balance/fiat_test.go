@@ -3,6 +3,7 @@ package balance_test
 import (
 	"math/big"
 
+	"github.com/jrh3k5/cryptonabber-sync/v3/money"
 	"github.com/jrh3k5/cryptonabber-sync/v3/token/balance"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -10,30 +11,51 @@ import (
 
 var _ = Describe("Fiat", func() {
 	Context("AsFiat", func() {
-		DescribeTable("token decimals", func(decimals int, expectedFiatValue int) {
-			Expect(balance.AsFiat(big.NewInt(int64(12345)), decimals, 1, 0.05)).To(Equal(int64(expectedFiatValue)), "the correct fiat amount should be calculated")
+		mustParse := func(s string) money.Decimal {
+			decimal, err := money.ParseDecimal(s)
+			Expect(err).ToNot(HaveOccurred(), "parsing the decimal '%s' should not fail", s)
+			return decimal
+		}
+
+		DescribeTable("token decimals", func(decimals int, expectedFiatValue string) {
+			Expect(balance.AsFiat(big.NewInt(int64(12345)), decimals, mustParse("1.05")).String()).To(Equal(expectedFiatValue), "the correct fiat amount should be calculated")
 		},
-			Entry("decimals of zero", 0, 1296225),
-			Entry("decimals of one", 1, 129622),
-			Entry("decimals of two", 2, 12962),
-			Entry("decimals of three", 3, 1296))
+			Entry("decimals of zero", 0, "12962.25"),
+			Entry("decimals of one", 1, "1296.225"),
+			Entry("decimals of two", 2, "129.6225"),
+			Entry("decimals of three", 3, "12.96225"))
 
-		DescribeTable("dollar rate", func(dollarRate int, expectedFiatValue int) {
-			Expect(balance.AsFiat(big.NewInt(int64(98765)), 0, int64(dollarRate), 0.5)).To(Equal(int64(expectedFiatValue)), "the correct fiat amount should be calculated")
+		DescribeTable("rate", func(rate string, expectedFiatValue string) {
+			Expect(balance.AsFiat(big.NewInt(int64(98765)), 0, mustParse(rate)).String()).To(Equal(expectedFiatValue), "the correct fiat amount should be calculated")
 		},
-			Entry("dollar rate of zero", 0, 4938250),
-			Entry("dollar rate of one", 1, 14814750),
-			Entry("dollar rate of two", 2, 24691250))
+			Entry("rate of zero dollars", "0.5", "49382.5"),
+			Entry("rate of one dollar", "1.5", "148147.5"),
+			Entry("rate of two dollars", "2.5", "246912.5"))
 
-		When("the cents rate is past two significant figures", func() {
+		When("the rate is past two significant figures", func() {
 			It("correctly calculates the fiat value", func() {
-				Expect(balance.AsFiat(big.NewInt(int64(98765)), 0, 1, 0.0005)).To(Equal(int64(9881438)), "the correct fiat amount should be calculated")
+				Expect(balance.AsFiat(big.NewInt(int64(98765)), 0, mustParse("1.0005")).String()).To(Equal("98814.3825"), "the correct fiat amount should be calculated")
 			})
 		})
 
 		It("calculates a realistic ETH balance scenario", func() {
 			tokenBalance := big.NewInt(int64(2410555693229900000))
-			Expect(balance.AsFiat(tokenBalance, 18, 2493, 0.38)).To(Equal(int64(601043)), "the correct fiat amount should be calculated")
+			fiatValue := balance.AsFiat(tokenBalance, 18, mustParse("2493.38"))
+			Expect(fiatValue.ToMilliunits(2)).To(Equal(int64(6010430)), "the correct fiat amount should be calculated")
+		})
+
+		When("the balance exceeds the range of a 64-bit integer", func() {
+			It("correctly calculates the fiat value", func() {
+				tokenBalance, _ := new(big.Int).SetString("1234567000000000000000000", 10) // 1,234,567 tokens with 18 decimals
+				Expect(balance.AsFiat(tokenBalance, 18, mustParse("2.5")).String()).To(Equal("3086417.5"), "the correct fiat amount should be calculated")
+			})
+		})
+
+		When("the rate is a tiny fraction of a cent", func() {
+			It("correctly calculates the fiat value", func() {
+				tokenBalance, _ := new(big.Int).SetString("250000000000000000000000000", 10) // 250,000,000 tokens with 18 decimals
+				Expect(balance.AsFiat(tokenBalance, 18, mustParse("1.234e-7")).String()).To(Equal("30.85"), "the correct fiat amount should be calculated")
+			})
 		})
 	})
 })
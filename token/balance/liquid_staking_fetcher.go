@@ -0,0 +1,68 @@
+package balance
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/jrh3k5/cryptonabber-sync/v3/config"
+	"github.com/jrh3k5/cryptonabber-sync/v3/config/chain"
+	rpcconfig "github.com/jrh3k5/cryptonabber-sync/v3/config/rpc"
+	"github.com/jrh3k5/cryptonabber-sync/v3/evm/abi"
+	"github.com/jrh3k5/cryptonabber-sync/v3/http/json/rpc"
+	"github.com/jrh3k5/cryptonabber-sync/v3/token"
+)
+
+// LiquidStakingFetcher fetches the balance of a liquid staking token, expressed in the token's underlying asset
+// at the exchange rate reported by the token's contract.
+type LiquidStakingFetcher struct {
+	erc20Fetcher             Fetcher[*config.ERC20Account]
+	rpcConfigurationResolver rpcconfig.ConfigurationResolver
+	ethCaller                rpc.EthCaller
+}
+
+// NewLiquidStakingFetcher creates a new LiquidStakingFetcher.
+func NewLiquidStakingFetcher(erc20Fetcher Fetcher[*config.ERC20Account], rpcConfigurationResolver rpcconfig.ConfigurationResolver, ethCaller rpc.EthCaller) *LiquidStakingFetcher {
+	return &LiquidStakingFetcher{
+		erc20Fetcher:             erc20Fetcher,
+		rpcConfigurationResolver: rpcConfigurationResolver,
+		ethCaller:                ethCaller,
+	}
+}
+
+func (l *LiquidStakingFetcher) FetchBalance(ctx context.Context, onchainAccount *config.LiquidStakingAccount) (*big.Int, error) {
+	tokenBalance, err := l.erc20Fetcher.FetchBalance(ctx, &onchainAccount.ERC20Account)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch token balance: %w", err)
+	}
+
+	rpcURL, err := token.ResolveRPCURL(ctx, l.rpcConfigurationResolver, onchainAccount.OnchainAsset, chain.TypeEVM)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve RPC URL: %w", err)
+	}
+
+	return convertAtRate(ctx, l.ethCaller, rpcURL, onchainAccount.TokenAddress, onchainAccount.RateFunction, onchainAccount.RateDecimals, tokenBalance)
+}
+
+// convertAtRate converts the given balance into another asset at the rate returned by the given function on the given contract,
+// which is expressed in the given number of decimals.
+func convertAtRate(ctx context.Context, ethCaller rpc.EthCaller, rpcURL string, contractAddress string, rateFunction string, rateDecimals int, balance *big.Int) (*big.Int, error) {
+	rateResult, err := ethCaller.EthCall(ctx, rpcURL, rateFunction, contractAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute %s: %w", rateFunction, err)
+	}
+
+	decodedRate, err := abi.DecodeHex([]string{"uint256"}, rateResult)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s result: %w", rateFunction, err)
+	}
+
+	converted := new(big.Int).Mul(balance, decodedRate[0].(*big.Int))
+
+	return converted.Quo(converted, pow10(rateDecimals)), nil
+}
+
+// pow10 gets 10 raised to the given power.
+func pow10(exponent int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exponent)), nil)
+}
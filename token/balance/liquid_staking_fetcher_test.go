@@ -0,0 +1,69 @@
+package balance_test
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+
+	"github.com/jrh3k5/cryptonabber-sync/v3/config"
+	"github.com/jrh3k5/cryptonabber-sync/v3/http/json/rpc"
+	"github.com/jrh3k5/cryptonabber-sync/v3/token/balance"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LiquidStakingFetcher", func() {
+	var erc20Fetcher *testERC20Fetcher
+	var fetcher *balance.LiquidStakingFetcher
+
+	var ctx context.Context
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		erc20Fetcher = newTestERC20Fetcher()
+		fetcher = balance.NewLiquidStakingFetcher(erc20Fetcher, rpcConfigurationResolver, rpc.NewDirectEthCaller(http.DefaultClient, rpc.NewLatestBlockResolver()))
+	})
+
+	It("scales the token balance by the exchange rate", func() {
+		tokenAddress := "0x7f39C581F595B53c5cb19bD0b3f8dA6c935E2Ca0"
+		erc20Fetcher.setBalance(tokenAddress, big.NewInt(2000000000000000000))
+		evmNode.RegisterETHCallCall("stEthPerToken", tokenAddress, nil, func(_ string, _ []string) (rpc.MockEVMNodeRPCResult, *rpc.MockEVMNodeRPCError, error) {
+			return rpc.NewMockEVMNodeRPCNumericResult(big.NewInt(1210000000000000000)), nil, nil
+		})
+
+		underlyingBalance, err := fetcher.FetchBalance(ctx, &config.LiquidStakingAccount{
+			ERC20Account: config.ERC20Account{
+				OnchainAsset: config.OnchainAsset{
+					ChainName: chainName,
+				},
+				TokenAddress: tokenAddress,
+			},
+			RateFunction: "stEthPerToken",
+			RateDecimals: 18,
+		})
+		Expect(err).ToNot(HaveOccurred(), "fetching the balance should not fail")
+		Expect(underlyingBalance).To(Equal(big.NewInt(2420000000000000000)), "the balance should be expressed in the underlying asset")
+	})
+
+	It("honors the decimals of the rate", func() {
+		tokenAddress := "0xBe9895146f7AF43049ca1c1AE358B0541Ea49704"
+		erc20Fetcher.setBalance(tokenAddress, big.NewInt(1000))
+		evmNode.RegisterETHCallCall("exchangeRate", tokenAddress, nil, func(_ string, _ []string) (rpc.MockEVMNodeRPCResult, *rpc.MockEVMNodeRPCError, error) {
+			return rpc.NewMockEVMNodeRPCNumericResult(big.NewInt(1075000)), nil, nil
+		})
+
+		underlyingBalance, err := fetcher.FetchBalance(ctx, &config.LiquidStakingAccount{
+			ERC20Account: config.ERC20Account{
+				OnchainAsset: config.OnchainAsset{
+					ChainName: chainName,
+				},
+				TokenAddress: tokenAddress,
+			},
+			RateFunction: "exchangeRate",
+			RateDecimals: 6,
+		})
+		Expect(err).ToNot(HaveOccurred(), "fetching the balance should not fail")
+		Expect(underlyingBalance).To(Equal(big.NewInt(1075)), "the rate should be scaled by its own decimals")
+	})
+})
@@ -0,0 +1,112 @@
+package balance
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/jrh3k5/cryptonabber-sync/v3/config"
+	"github.com/jrh3k5/cryptonabber-sync/v3/config/chain"
+	rpcconfig "github.com/jrh3k5/cryptonabber-sync/v3/config/rpc"
+	"github.com/jrh3k5/cryptonabber-sync/v3/evm/abi"
+	"github.com/jrh3k5/cryptonabber-sync/v3/http/json/rpc"
+	"github.com/jrh3k5/cryptonabber-sync/v3/token"
+)
+
+// PoolShare is a wallet's share of the assets held by a liquidity pool.
+type PoolShare struct {
+	Balance *big.Int      // the wallet's balance of the pool's LP token
+	Assets  []PooledAsset // the wallet's pro-rata share of each of the pool's assets
+}
+
+// PooledAsset is an amount of one of the assets held by a liquidity pool.
+type PooledAsset struct {
+	TokenAddress string
+	Balance      *big.Int
+}
+
+// LPV2Fetcher fetches a wallet's share of the reserves of a Uniswap V2-style pair.
+type LPV2Fetcher struct {
+	rpcConfigurationResolver rpcconfig.ConfigurationResolver
+	ethCaller                rpc.EthCaller
+}
+
+// NewLPV2Fetcher creates a new LPV2Fetcher.
+func NewLPV2Fetcher(rpcConfigurationResolver rpcconfig.ConfigurationResolver, ethCaller rpc.EthCaller) *LPV2Fetcher {
+	return &LPV2Fetcher{
+		rpcConfigurationResolver: rpcConfigurationResolver,
+		ethCaller:                ethCaller,
+	}
+}
+
+// FetchPoolShare gets the wallet's balance of the pair's LP token and the share of each of the pair's reserves to which that balance entitles it.
+func (l *LPV2Fetcher) FetchPoolShare(ctx context.Context, onchainAccount *config.LPV2Account) (*PoolShare, error) {
+	rpcURL, err := token.ResolveRPCURL(ctx, l.rpcConfigurationResolver, onchainAccount.OnchainAsset, chain.TypeEVM)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve RPC URL: %w", err)
+	}
+
+	balance, err := l.callUint256(ctx, rpcURL, onchainAccount.PairAddress, "balanceOf", rpc.Arg("address", onchainAccount.WalletAddress))
+	if err != nil {
+		return nil, err
+	}
+
+	totalSupply, err := l.callUint256(ctx, rpcURL, onchainAccount.PairAddress, "totalSupply")
+	if err != nil {
+		return nil, err
+	}
+
+	reservesResult, err := l.ethCaller.EthCall(ctx, rpcURL, "getReserves", onchainAccount.PairAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute getReserves: %w", err)
+	}
+
+	reserves, err := abi.DecodeHex([]string{"uint112", "uint112", "uint32"}, reservesResult)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode getReserves result: %w", err)
+	}
+
+	poolShare := &PoolShare{
+		Balance: balance,
+	}
+
+	for tokenIndex, tokenFunction := range []string{"token0", "token1"} {
+		tokenResult, err := l.ethCaller.EthCall(ctx, rpcURL, tokenFunction, onchainAccount.PairAddress)
+		if err != nil {
+			return nil, fmt.Errorf("failed to execute %s: %w", tokenFunction, err)
+		}
+
+		tokenAddress, err := abi.DecodeHex([]string{"address"}, tokenResult)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode %s result: %w", tokenFunction, err)
+		}
+
+		// An empty pair has no reserves to which any balance is entitled
+		shareBalance := big.NewInt(0)
+		if totalSupply.Sign() > 0 {
+			shareBalance.Mul(reserves[tokenIndex].(*big.Int), balance)
+			shareBalance.Quo(shareBalance, totalSupply)
+		}
+
+		poolShare.Assets = append(poolShare.Assets, PooledAsset{
+			TokenAddress: tokenAddress[0].(string),
+			Balance:      shareBalance,
+		})
+	}
+
+	return poolShare, nil
+}
+
+func (l *LPV2Fetcher) callUint256(ctx context.Context, rpcURL string, contractAddress string, functionName string, args ...*rpc.EthCallArgument) (*big.Int, error) {
+	result, err := l.ethCaller.EthCall(ctx, rpcURL, functionName, contractAddress, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute %s: %w", functionName, err)
+	}
+
+	decoded, err := abi.DecodeHex([]string{"uint256"}, result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s result: %w", functionName, err)
+	}
+
+	return decoded[0].(*big.Int), nil
+}
@@ -0,0 +1,84 @@
+package balance_test
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+
+	"github.com/jrh3k5/cryptonabber-sync/v3/config"
+	"github.com/jrh3k5/cryptonabber-sync/v3/http/json/rpc"
+	"github.com/jrh3k5/cryptonabber-sync/v3/token/balance"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LPV2Fetcher", func() {
+	var fetcher *balance.LPV2Fetcher
+
+	var ctx context.Context
+	var account *config.LPV2Account
+
+	var token0Address string
+	var token1Address string
+
+	registerPair := func(pairAddress string, lpBalance *big.Int, totalSupply *big.Int, reserve0 *big.Int, reserve1 *big.Int) {
+		evmNode.RegisterETHCallCall("balanceOf", pairAddress, []string{"address"}, func(_ string, _ []string) (rpc.MockEVMNodeRPCResult, *rpc.MockEVMNodeRPCError, error) {
+			return rpc.NewMockEVMNodeRPCNumericResult(lpBalance), nil, nil
+		})
+		evmNode.RegisterETHCallCall("totalSupply", pairAddress, nil, func(_ string, _ []string) (rpc.MockEVMNodeRPCResult, *rpc.MockEVMNodeRPCError, error) {
+			return rpc.NewMockEVMNodeRPCNumericResult(totalSupply), nil, nil
+		})
+		evmNode.RegisterETHCallCall("getReserves", pairAddress, nil, func(_ string, _ []string) (rpc.MockEVMNodeRPCResult, *rpc.MockEVMNodeRPCError, error) {
+			return rpc.NewMockEVMNodeRPCABIResult([]string{"uint112", "uint112", "uint32"}, reserve0, reserve1, big.NewInt(1700000000)), nil, nil
+		})
+		evmNode.RegisterETHCallCall("token0", pairAddress, nil, func(_ string, _ []string) (rpc.MockEVMNodeRPCResult, *rpc.MockEVMNodeRPCError, error) {
+			return rpc.NewMockEVMNodeRPCAddressResult(token0Address), nil, nil
+		})
+		evmNode.RegisterETHCallCall("token1", pairAddress, nil, func(_ string, _ []string) (rpc.MockEVMNodeRPCResult, *rpc.MockEVMNodeRPCError, error) {
+			return rpc.NewMockEVMNodeRPCAddressResult(token1Address), nil, nil
+		})
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		fetcher = balance.NewLPV2Fetcher(rpcConfigurationResolver, rpc.NewDirectEthCaller(http.DefaultClient, rpc.NewLatestBlockResolver()))
+
+		token0Address = "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"
+		token1Address = "0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2"
+
+		account = &config.LPV2Account{
+			OnchainAsset: config.OnchainAsset{
+				ChainName: chainName,
+			},
+			OnchainWallet: config.OnchainWallet{
+				WalletAddress: "0x2870d53DcAc4763D6b0C030fbE0555405B09CDb3",
+			},
+		}
+	})
+
+	It("computes the wallet's pro-rata share of each reserve", func() {
+		account.PairAddress = "0xB4e16d0168e52d35CaCD2c6185b44281Ec28C9Dc"
+		registerPair(account.PairAddress, big.NewInt(250), big.NewInt(1000), big.NewInt(4000000), big.NewInt(2000))
+
+		poolShare, err := fetcher.FetchPoolShare(ctx, account)
+		Expect(err).ToNot(HaveOccurred(), "fetching the pool share should not fail")
+		Expect(poolShare.Balance).To(Equal(big.NewInt(250)), "the balance of the LP token should be returned")
+		Expect(poolShare.Assets).To(Equal([]balance.PooledAsset{
+			{TokenAddress: token0Address, Balance: big.NewInt(1000000)},
+			{TokenAddress: token1Address, Balance: big.NewInt(500)},
+		}), "the wallet should be entitled to a quarter of each reserve")
+	})
+
+	It("entitles the wallet to nothing from a pair with no supply", func() {
+		account.PairAddress = "0x397FF1542f962076d0BFE58eA045FfA2d347ACa0"
+		registerPair(account.PairAddress, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0))
+
+		poolShare, err := fetcher.FetchPoolShare(ctx, account)
+		Expect(err).ToNot(HaveOccurred(), "fetching the pool share should not fail")
+		Expect(poolShare.Assets).To(HaveLen(2), "both of the pair's assets should be returned")
+		for _, pooledAsset := range poolShare.Assets {
+			Expect(pooledAsset.Balance.Sign()).To(BeZero(), "the wallet should not be entitled to any of the reserves")
+		}
+	})
+})
@@ -0,0 +1,67 @@
+package balance
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/jrh3k5/cryptonabber-sync/v3/config"
+	"github.com/jrh3k5/cryptonabber-sync/v3/config/chain"
+	rpcconfig "github.com/jrh3k5/cryptonabber-sync/v3/config/rpc"
+	synchttp "github.com/jrh3k5/cryptonabber-sync/v3/http"
+	"github.com/jrh3k5/cryptonabber-sync/v3/http/json/rpc"
+	"github.com/jrh3k5/cryptonabber-sync/v3/token"
+)
+
+// NativeFetcher is a Fetcher implementation that retrieves the balance of a chain's native coin.
+type NativeFetcher struct {
+	rpcConfigurationResolver rpcconfig.ConfigurationResolver
+	doer                     synchttp.Doer
+	blockResolver            rpc.BlockResolver
+}
+
+// NewNativeFetcher builds a NativeFetcher instance that reads balances as of the blocks resolved by the given BlockResolver.
+func NewNativeFetcher(rpcConfigurationResolver rpcconfig.ConfigurationResolver, doer synchttp.Doer, blockResolver rpc.BlockResolver) *NativeFetcher {
+	return &NativeFetcher{
+		rpcConfigurationResolver: rpcConfigurationResolver,
+		doer:                     doer,
+		blockResolver:            blockResolver,
+	}
+}
+
+func (n *NativeFetcher) FetchBalance(ctx context.Context, onchainAccount *config.NativeAccount) (*big.Int, error) {
+	rpcURL, err := token.ResolveRPCURL(ctx, n.rpcConfigurationResolver, onchainAccount.OnchainAsset, chain.TypeEVM)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve RPC URL: %w", err)
+	}
+
+	blockNumber, err := n.blockResolver.ResolveBlock(ctx, rpcURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve block: %w", err)
+	}
+
+	rpcRequest := &rpc.Request{
+		ID:      1,
+		JSONRPC: "2.0",
+		Method:  "eth_getBalance",
+		Params:  []any{onchainAccount.WalletAddress, rpc.BlockTag(blockNumber)},
+	}
+
+	rpcResponse, err := rpc.ExecuteRequest(ctx, n.doer, rpcURL, rpcRequest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute eth_getBalance: %w", err)
+	}
+
+	balanceHex := strings.TrimPrefix(rpcResponse.Result, "0x")
+	if balanceHex == "" {
+		return nil, fmt.Errorf("empty balance returned by eth_getBalance: '%s'", rpcResponse.Result)
+	}
+
+	balance, isValid := new(big.Int).SetString(balanceHex, 16)
+	if !isValid {
+		return nil, fmt.Errorf("invalid balance returned by eth_getBalance: '%s'", rpcResponse.Result)
+	}
+
+	return balance, nil
+}
@@ -0,0 +1,71 @@
+package balance_test
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+
+	"github.com/jrh3k5/cryptonabber-sync/v3/config"
+	"github.com/jrh3k5/cryptonabber-sync/v3/http/json/rpc"
+	"github.com/jrh3k5/cryptonabber-sync/v3/token/balance"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NativeFetcher", func() {
+	var fetcher *balance.NativeFetcher
+
+	var ctx context.Context
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		fetcher = balance.NewNativeFetcher(rpcConfigurationResolver, http.DefaultClient, rpc.NewLatestBlockResolver())
+	})
+
+	It("fetches and retrieves the correct balance", func() {
+		nativeBalance, _ := new(big.Int).SetString("12345678901234567890", 10)
+
+		evmNode.RegisterRPCMethodCall("eth_getBalance", func(_ string) (rpc.MockEVMNodeRPCResult, *rpc.MockEVMNodeRPCError, error) {
+			return rpc.NewMockEVMNodeRPCNumericResult(nativeBalance), nil, nil
+		})
+
+		retrievedBalance, err := fetcher.FetchBalance(ctx, &config.NativeAccount{
+			OnchainAsset: config.OnchainAsset{
+				ChainName: chainName,
+			},
+			OnchainWallet: config.OnchainWallet{
+				WalletAddress: "0x2870d53DcAc4763D6b0C030fbE0555405B09CDb3",
+			},
+		})
+
+		Expect(err).ToNot(HaveOccurred(), "getting the balance should not fail")
+		Expect(retrievedBalance).To(Equal(nativeBalance), "the correct balance should be returned")
+	})
+
+	DescribeTable("rejects a malformed balance", func(result string) {
+		evmNode.RegisterRPCMethodCall("eth_getBalance", func(_ string) (rpc.MockEVMNodeRPCResult, *rpc.MockEVMNodeRPCError, error) {
+			return rawRPCResult(result), nil, nil
+		})
+
+		_, err := fetcher.FetchBalance(ctx, &config.NativeAccount{
+			OnchainAsset: config.OnchainAsset{
+				ChainName: chainName,
+			},
+			OnchainWallet: config.OnchainWallet{
+				WalletAddress: "0x2870d53DcAc4763D6b0C030fbE0555405B09CDb3",
+			},
+		})
+		Expect(err).To(HaveOccurred(), "getting a malformed balance should fail")
+	},
+		Entry("an empty result", ""),
+		Entry("a bare prefix", "0x"),
+		Entry("a non-hexadecimal result", "0xnotanumber"))
+})
+
+// rawRPCResult is an RPC result that is returned exactly as given.
+type rawRPCResult string
+
+func (r rawRPCResult) ReturnValue() string {
+	return string(r)
+}
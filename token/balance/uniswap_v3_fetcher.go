@@ -0,0 +1,278 @@
+package balance
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/jrh3k5/cryptonabber-sync/v3/config"
+	"github.com/jrh3k5/cryptonabber-sync/v3/config/chain"
+	rpcconfig "github.com/jrh3k5/cryptonabber-sync/v3/config/rpc"
+	"github.com/jrh3k5/cryptonabber-sync/v3/evm/abi"
+	"github.com/jrh3k5/cryptonabber-sync/v3/http/json/rpc"
+	"github.com/jrh3k5/cryptonabber-sync/v3/token"
+	"github.com/jrh3k5/cryptonabber-sync/v3/uniswap"
+)
+
+// UniswapV3Fetcher fetches the assets held by the Uniswap V3-style concentrated liquidity positions of a wallet.
+type UniswapV3Fetcher struct {
+	rpcConfigurationResolver rpcconfig.ConfigurationResolver
+	ethCaller                rpc.EthCaller
+}
+
+// NewUniswapV3Fetcher creates a new UniswapV3Fetcher.
+func NewUniswapV3Fetcher(rpcConfigurationResolver rpcconfig.ConfigurationResolver, ethCaller rpc.EthCaller) *UniswapV3Fetcher {
+	return &UniswapV3Fetcher{
+		rpcConfigurationResolver: rpcConfigurationResolver,
+		ethCaller:                ethCaller,
+	}
+}
+
+// FetchPooledAssets enumerates the position NFTs of the given position manager held by the wallet and gets the total amount of each asset
+// held by those positions, in the order in which the assets are first encountered.
+func (u *UniswapV3Fetcher) FetchPooledAssets(ctx context.Context, onchainAccount *config.UniswapV3Account) ([]PooledAsset, error) {
+	rpcURL, err := token.ResolveRPCURL(ctx, u.rpcConfigurationResolver, onchainAccount.OnchainAsset, chain.TypeEVM)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve RPC URL: %w", err)
+	}
+
+	positionManagerAddress := onchainAccount.PositionManagerAddress
+
+	positionCount, err := u.callValue(ctx, rpcURL, positionManagerAddress, "balanceOf", "uint256", rpc.Arg("address", onchainAccount.WalletAddress))
+	if err != nil {
+		return nil, err
+	}
+
+	var pooledAssets []PooledAsset
+	if positionCount.(*big.Int).Sign() == 0 {
+		return pooledAssets, nil
+	}
+
+	factoryAddress, err := u.callValue(ctx, rpcURL, positionManagerAddress, "factory", "address")
+	if err != nil {
+		return nil, err
+	}
+
+	pools := make(map[string]*uniswapV3Pool)
+	for positionIndex := range positionCount.(*big.Int).Int64() {
+		tokenID, err := u.callValue(ctx, rpcURL, positionManagerAddress, "tokenOfOwnerByIndex", "uint256", rpc.Arg("address", onchainAccount.WalletAddress), rpc.Arg("uint256", positionIndex))
+		if err != nil {
+			return nil, err
+		}
+
+		position, err := u.getPosition(ctx, rpcURL, positionManagerAddress, tokenID.(*big.Int))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get position %v: %w", tokenID, err)
+		}
+
+		poolKey := strings.ToLower(position.token0 + "/" + position.token1 + "/" + position.fee.String())
+		pool, hasPool := pools[poolKey]
+		if !hasPool {
+			pool, err = u.getPool(ctx, rpcURL, factoryAddress.(string), position)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get pool of position %v: %w", tokenID, err)
+			}
+			pools[poolKey] = pool
+		}
+
+		amount0, amount1, err := u.getPositionAmounts(ctx, rpcURL, pool, position, onchainAccount.IncludeUncollectedFees)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get amounts of position %v: %w", tokenID, err)
+		}
+
+		pooledAssets = addPooledAsset(pooledAssets, position.token0, amount0)
+		pooledAssets = addPooledAsset(pooledAssets, position.token1, amount1)
+	}
+
+	return pooledAssets, nil
+}
+
+// getPosition reads the position with the given token ID from the position manager.
+func (u *UniswapV3Fetcher) getPosition(ctx context.Context, rpcURL string, positionManagerAddress string, tokenID *big.Int) (*uniswapV3Position, error) {
+	result, err := u.ethCaller.EthCall(ctx, rpcURL, "positions", positionManagerAddress, rpc.Arg("uint256", tokenID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute positions: %w", err)
+	}
+
+	decoded, err := abi.DecodeHex([]string{"uint96", "address", "address", "address", "uint24", "int24", "int24", "uint128", "uint256", "uint256", "uint128", "uint128"}, result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode positions result: %w", err)
+	}
+
+	return &uniswapV3Position{
+		token0:                   decoded[2].(string),
+		token1:                   decoded[3].(string),
+		fee:                      decoded[4].(*big.Int),
+		tickLower:                decoded[5].(*big.Int).Int64(),
+		tickUpper:                decoded[6].(*big.Int).Int64(),
+		liquidity:                decoded[7].(*big.Int),
+		feeGrowthInside0LastX128: decoded[8].(*big.Int),
+		feeGrowthInside1LastX128: decoded[9].(*big.Int),
+		tokensOwed0:              decoded[10].(*big.Int),
+		tokensOwed1:              decoded[11].(*big.Int),
+	}, nil
+}
+
+// getPool resolves the pool of the given position through the factory and reads its current price.
+func (u *UniswapV3Fetcher) getPool(ctx context.Context, rpcURL string, factoryAddress string, position *uniswapV3Position) (*uniswapV3Pool, error) {
+	poolAddress, err := u.callValue(ctx, rpcURL, factoryAddress, "getPool", "address", rpc.Arg("address", position.token0), rpc.Arg("address", position.token1), rpc.Arg("uint24", position.fee))
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := u.ethCaller.EthCall(ctx, rpcURL, "slot0", poolAddress.(string))
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute slot0: %w", err)
+	}
+
+	// Forks differ in the later fields of slot0, so only the price and tick are decoded
+	decoded, err := abi.DecodeHex([]string{"uint160", "int24"}, result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode slot0 result: %w", err)
+	}
+
+	return &uniswapV3Pool{
+		address:      poolAddress.(string),
+		sqrtPriceX96: decoded[0].(*big.Int),
+		tick:         decoded[1].(*big.Int).Int64(),
+	}, nil
+}
+
+// getPositionAmounts gets the amounts of token0 and token1 held by the given position in the given pool.
+func (u *UniswapV3Fetcher) getPositionAmounts(ctx context.Context, rpcURL string, pool *uniswapV3Pool, position *uniswapV3Position, includeUncollectedFees bool) (*big.Int, *big.Int, error) {
+	sqrtRatioLowerX96, err := uniswap.SqrtRatioAtTick(position.tickLower)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid lower tick: %w", err)
+	}
+
+	sqrtRatioUpperX96, err := uniswap.SqrtRatioAtTick(position.tickUpper)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid upper tick: %w", err)
+	}
+
+	amount0, amount1 := uniswap.AmountsForLiquidity(pool.sqrtPriceX96, sqrtRatioLowerX96, sqrtRatioUpperX96, position.liquidity)
+	if !includeUncollectedFees {
+		return amount0, amount1, nil
+	}
+
+	// Fees already credited to the position are owed to it regardless of its liquidity
+	amount0.Add(amount0, position.tokensOwed0)
+	amount1.Add(amount1, position.tokensOwed1)
+
+	if position.liquidity.Sign() == 0 {
+		return amount0, amount1, nil
+	}
+
+	if err := u.loadFeeGrowth(ctx, rpcURL, pool); err != nil {
+		return nil, nil, err
+	}
+
+	lowerTickFeeGrowth, err := u.getTickFeeGrowthOutside(ctx, rpcURL, pool.address, position.tickLower)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	upperTickFeeGrowth, err := u.getTickFeeGrowthOutside(ctx, rpcURL, pool.address, position.tickUpper)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	feeGrowthInside0X128 := uniswap.FeeGrowthInside(pool.tick, position.tickLower, position.tickUpper, pool.feeGrowthGlobal0X128, lowerTickFeeGrowth[0], upperTickFeeGrowth[0])
+	feeGrowthInside1X128 := uniswap.FeeGrowthInside(pool.tick, position.tickLower, position.tickUpper, pool.feeGrowthGlobal1X128, lowerTickFeeGrowth[1], upperTickFeeGrowth[1])
+
+	amount0.Add(amount0, uniswap.FeesEarned(position.liquidity, feeGrowthInside0X128, position.feeGrowthInside0LastX128))
+	amount1.Add(amount1, uniswap.FeesEarned(position.liquidity, feeGrowthInside1X128, position.feeGrowthInside1LastX128))
+
+	return amount0, amount1, nil
+}
+
+// loadFeeGrowth reads the fees earned per unit of liquidity over the life of the given pool, if they have not already been read.
+func (u *UniswapV3Fetcher) loadFeeGrowth(ctx context.Context, rpcURL string, pool *uniswapV3Pool) error {
+	if pool.feeGrowthGlobal0X128 != nil {
+		return nil
+	}
+
+	feeGrowthGlobal0X128, err := u.callValue(ctx, rpcURL, pool.address, "feeGrowthGlobal0X128", "uint256")
+	if err != nil {
+		return err
+	}
+
+	feeGrowthGlobal1X128, err := u.callValue(ctx, rpcURL, pool.address, "feeGrowthGlobal1X128", "uint256")
+	if err != nil {
+		return err
+	}
+
+	pool.feeGrowthGlobal0X128 = feeGrowthGlobal0X128.(*big.Int)
+	pool.feeGrowthGlobal1X128 = feeGrowthGlobal1X128.(*big.Int)
+
+	return nil
+}
+
+// getTickFeeGrowthOutside reads the fees earned per unit of liquidity on the other side of the given tick from the current tick,
+// in token0 and token1 respectively.
+func (u *UniswapV3Fetcher) getTickFeeGrowthOutside(ctx context.Context, rpcURL string, poolAddress string, tick int64) ([2]*big.Int, error) {
+	result, err := u.ethCaller.EthCall(ctx, rpcURL, "ticks", poolAddress, rpc.Arg("int24", tick))
+	if err != nil {
+		return [2]*big.Int{}, fmt.Errorf("failed to execute ticks: %w", err)
+	}
+
+	// Forks differ in the later fields of a tick, so only those leading up to the fee growth are decoded
+	decoded, err := abi.DecodeHex([]string{"uint128", "int128", "uint256", "uint256"}, result)
+	if err != nil {
+		return [2]*big.Int{}, fmt.Errorf("failed to decode ticks result: %w", err)
+	}
+
+	return [2]*big.Int{decoded[2].(*big.Int), decoded[3].(*big.Int)}, nil
+}
+
+// callValue calls the given function, which returns a single value of the given type, on the given contract.
+func (u *UniswapV3Fetcher) callValue(ctx context.Context, rpcURL string, contractAddress string, functionName string, returnType string, args ...*rpc.EthCallArgument) (any, error) {
+	result, err := u.ethCaller.EthCall(ctx, rpcURL, functionName, contractAddress, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute %s: %w", functionName, err)
+	}
+
+	decoded, err := abi.DecodeHex([]string{returnType}, result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s result: %w", functionName, err)
+	}
+
+	return decoded[0], nil
+}
+
+// addPooledAsset adds the given amount of the given asset to the given assets.
+func addPooledAsset(pooledAssets []PooledAsset, tokenAddress string, amount *big.Int) []PooledAsset {
+	for assetIndex, pooledAsset := range pooledAssets {
+		if strings.EqualFold(pooledAsset.TokenAddress, tokenAddress) {
+			pooledAssets[assetIndex].Balance = new(big.Int).Add(pooledAsset.Balance, amount)
+			return pooledAssets
+		}
+	}
+
+	return append(pooledAssets, PooledAsset{
+		TokenAddress: tokenAddress,
+		Balance:      new(big.Int).Set(amount),
+	})
+}
+
+type uniswapV3Position struct {
+	token0                   string
+	token1                   string
+	fee                      *big.Int
+	tickLower                int64
+	tickUpper                int64
+	liquidity                *big.Int
+	feeGrowthInside0LastX128 *big.Int
+	feeGrowthInside1LastX128 *big.Int
+	tokensOwed0              *big.Int // fees credited to the position, but not yet collected, in token0
+	tokensOwed1              *big.Int // fees credited to the position, but not yet collected, in token1
+}
+
+type uniswapV3Pool struct {
+	address              string
+	sqrtPriceX96         *big.Int
+	tick                 int64
+	feeGrowthGlobal0X128 *big.Int // nil until the pool's fee growth is read
+	feeGrowthGlobal1X128 *big.Int
+}
@@ -0,0 +1,173 @@
+package balance_test
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+
+	"github.com/jrh3k5/cryptonabber-sync/v3/config"
+	"github.com/jrh3k5/cryptonabber-sync/v3/evm/abi"
+	"github.com/jrh3k5/cryptonabber-sync/v3/http/json/rpc"
+	"github.com/jrh3k5/cryptonabber-sync/v3/token/balance"
+	"github.com/jrh3k5/cryptonabber-sync/v3/uniswap"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("UniswapV3Fetcher", func() {
+	var fetcher *balance.UniswapV3Fetcher
+
+	var ctx context.Context
+	var account *config.UniswapV3Account
+
+	factoryAddress := "0x1F98431c8aD98523631AE4a59f267346ea31F984"
+	poolAddress := "0x88e6A0c2dDD26FEEb64F039a2c41296FcB3f5640"
+	token0Address := "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"
+	token1Address := "0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2"
+
+	liquidity := big.NewInt(1000000000000)
+	q128 := new(big.Int).Lsh(big.NewInt(1), 128)
+	x128 := func(value int64) *big.Int {
+		return new(big.Int).Mul(big.NewInt(value), q128)
+	}
+
+	// positionAmounts computes the amounts held by the given liquidity between the given ticks while the pool is at tick 0
+	positionAmounts := func(tickLower int64, tickUpper int64) (*big.Int, *big.Int) {
+		sqrtRatioLower, err := uniswap.SqrtRatioAtTick(tickLower)
+		Expect(err).ToNot(HaveOccurred(), "getting the ratio of the lower tick should not fail")
+		sqrtRatioUpper, err := uniswap.SqrtRatioAtTick(tickUpper)
+		Expect(err).ToNot(HaveOccurred(), "getting the ratio of the upper tick should not fail")
+		sqrtRatio, err := uniswap.SqrtRatioAtTick(0)
+		Expect(err).ToNot(HaveOccurred(), "getting the ratio of the current tick should not fail")
+
+		return uniswap.AmountsForLiquidity(sqrtRatio, sqrtRatioLower, sqrtRatioUpper, liquidity)
+	}
+
+	// registerPositions registers a position manager holding, for the wallet, one position that contains the pool's current tick
+	// and one position entirely above it, both of which are in a single pool that is at tick 0.
+	registerPositions := func(positionManagerAddress string) {
+		evmNode.RegisterETHCallCall("balanceOf", positionManagerAddress, []string{"address"}, func(_ string, _ []string) (rpc.MockEVMNodeRPCResult, *rpc.MockEVMNodeRPCError, error) {
+			return rpc.NewMockEVMNodeRPCNumericResult(big.NewInt(2)), nil, nil
+		})
+		evmNode.RegisterETHCallCall("factory", positionManagerAddress, nil, func(_ string, _ []string) (rpc.MockEVMNodeRPCResult, *rpc.MockEVMNodeRPCError, error) {
+			return rpc.NewMockEVMNodeRPCAddressResult(factoryAddress), nil, nil
+		})
+		evmNode.RegisterETHCallCall("tokenOfOwnerByIndex", positionManagerAddress, []string{"address", "uint256"}, func(_ string, params []string) (rpc.MockEVMNodeRPCResult, *rpc.MockEVMNodeRPCError, error) {
+			index, err := abi.DecodeHex([]string{"uint256"}, params[1])
+			if err != nil {
+				return nil, nil, err
+			}
+
+			return rpc.NewMockEVMNodeRPCNumericResult(new(big.Int).Add(index[0].(*big.Int), big.NewInt(100))), nil, nil
+		})
+		evmNode.RegisterETHCallCall("positions", positionManagerAddress, []string{"uint256"}, func(_ string, params []string) (rpc.MockEVMNodeRPCResult, *rpc.MockEVMNodeRPCError, error) {
+			tokenID, err := abi.DecodeHex([]string{"uint256"}, params[0])
+			if err != nil {
+				return nil, nil, err
+			}
+
+			positionTypes := []string{"uint96", "address", "address", "address", "uint24", "int24", "int24", "uint128", "uint256", "uint256", "uint128", "uint128"}
+			if tokenID[0].(*big.Int).Int64() == 100 {
+				return rpc.NewMockEVMNodeRPCABIResult(positionTypes, 0, "0x0000000000000000000000000000000000000000", token0Address, token1Address, 3000, -600, 600, liquidity, x128(1), x128(2), 5, 6), nil, nil
+			}
+
+			return rpc.NewMockEVMNodeRPCABIResult(positionTypes, 0, "0x0000000000000000000000000000000000000000", token0Address, token1Address, 3000, 600, 1200, liquidity, 0, 0, 0, 0), nil, nil
+		})
+
+		evmNode.RegisterETHCallCall("getPool", factoryAddress, []string{"address", "address", "uint24"}, func(_ string, _ []string) (rpc.MockEVMNodeRPCResult, *rpc.MockEVMNodeRPCError, error) {
+			return rpc.NewMockEVMNodeRPCAddressResult(poolAddress), nil, nil
+		})
+
+		evmNode.RegisterETHCallCall("slot0", poolAddress, nil, func(_ string, _ []string) (rpc.MockEVMNodeRPCResult, *rpc.MockEVMNodeRPCError, error) {
+			return rpc.NewMockEVMNodeRPCABIResult([]string{"uint160", "int24", "uint16", "uint16", "uint16", "uint8", "bool"}, new(big.Int).Lsh(big.NewInt(1), 96), 0, 0, 1, 1, 0, true), nil, nil
+		})
+		evmNode.RegisterETHCallCall("feeGrowthGlobal0X128", poolAddress, nil, func(_ string, _ []string) (rpc.MockEVMNodeRPCResult, *rpc.MockEVMNodeRPCError, error) {
+			return rpc.NewMockEVMNodeRPCNumericResult(x128(10)), nil, nil
+		})
+		evmNode.RegisterETHCallCall("feeGrowthGlobal1X128", poolAddress, nil, func(_ string, _ []string) (rpc.MockEVMNodeRPCResult, *rpc.MockEVMNodeRPCError, error) {
+			return rpc.NewMockEVMNodeRPCNumericResult(x128(20)), nil, nil
+		})
+		evmNode.RegisterETHCallCall("ticks", poolAddress, []string{"int24"}, func(_ string, params []string) (rpc.MockEVMNodeRPCResult, *rpc.MockEVMNodeRPCError, error) {
+			tick, err := abi.DecodeHex([]string{"int24"}, params[0])
+			if err != nil {
+				return nil, nil, err
+			}
+
+			// The fee growth outside of each tick, in token0 and token1
+			feeGrowthOutside := map[int64][2]int64{
+				-600: {2, 4},
+				600:  {3, 6},
+				1200: {1, 1},
+			}[tick[0].(*big.Int).Int64()]
+
+			return rpc.NewMockEVMNodeRPCABIResult([]string{"uint128", "int128", "uint256", "uint256"}, liquidity, 0, x128(feeGrowthOutside[0]), x128(feeGrowthOutside[1])), nil, nil
+		})
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		fetcher = balance.NewUniswapV3Fetcher(rpcConfigurationResolver, rpc.NewDirectEthCaller(http.DefaultClient, rpc.NewLatestBlockResolver()))
+
+		account = &config.UniswapV3Account{
+			OnchainAsset: config.OnchainAsset{
+				ChainName: chainName,
+			},
+			OnchainWallet: config.OnchainWallet{
+				WalletAddress: "0x2870d53DcAc4763D6b0C030fbE0555405B09CDb3",
+			},
+		}
+	})
+
+	It("totals the assets held by each of the wallet's positions", func() {
+		account.PositionManagerAddress = "0xC36442b4a4522E871399CD717aBDD847Ab11FE88"
+		registerPositions(account.PositionManagerAddress)
+
+		inRangeAmount0, inRangeAmount1 := positionAmounts(-600, 600)
+		aboveRangeAmount0, aboveRangeAmount1 := positionAmounts(600, 1200)
+		Expect(aboveRangeAmount1.Sign()).To(BeZero(), "a position above the current tick should hold only token0")
+
+		pooledAssets, err := fetcher.FetchPooledAssets(ctx, account)
+		Expect(err).ToNot(HaveOccurred(), "fetching the pooled assets should not fail")
+		Expect(pooledAssets).To(Equal([]balance.PooledAsset{
+			{TokenAddress: token0Address, Balance: new(big.Int).Add(inRangeAmount0, aboveRangeAmount0)},
+			{TokenAddress: token1Address, Balance: inRangeAmount1},
+		}), "the amounts of each asset should be totaled across the positions")
+	})
+
+	It("includes uncollected fees when configured to", func() {
+		account.PositionManagerAddress = "0x03a520b32C04BF3bEEf7BEb72E919cf822Ed34f1"
+		account.IncludeUncollectedFees = true
+		registerPositions(account.PositionManagerAddress)
+
+		inRangeAmount0, inRangeAmount1 := positionAmounts(-600, 600)
+		aboveRangeAmount0, _ := positionAmounts(600, 1200)
+
+		// The in-range position has grown by 5 and 10 per unit of liquidity since its last growth of 1 and 2, and is owed 5 and 6;
+		// the position above the range has grown by 2 and 5 per unit of liquidity
+		expectedAmount0 := new(big.Int).Add(inRangeAmount0, aboveRangeAmount0)
+		expectedAmount0.Add(expectedAmount0, big.NewInt(5))
+		expectedAmount0.Add(expectedAmount0, new(big.Int).Mul(liquidity, big.NewInt(4+2)))
+
+		expectedAmount1 := new(big.Int).Add(inRangeAmount1, big.NewInt(6))
+		expectedAmount1.Add(expectedAmount1, new(big.Int).Mul(liquidity, big.NewInt(8+5)))
+
+		pooledAssets, err := fetcher.FetchPooledAssets(ctx, account)
+		Expect(err).ToNot(HaveOccurred(), "fetching the pooled assets should not fail")
+		Expect(pooledAssets).To(Equal([]balance.PooledAsset{
+			{TokenAddress: token0Address, Balance: expectedAmount0},
+			{TokenAddress: token1Address, Balance: expectedAmount1},
+		}), "the fees owed to and earned by each position should be included")
+	})
+
+	It("returns no assets for a wallet without positions", func() {
+		account.PositionManagerAddress = "0x655C406EBFa14EE2006250925e54ec43AD184f8B"
+		evmNode.RegisterETHCallCall("balanceOf", account.PositionManagerAddress, []string{"address"}, func(_ string, _ []string) (rpc.MockEVMNodeRPCResult, *rpc.MockEVMNodeRPCError, error) {
+			return rpc.NewMockEVMNodeRPCNumericResult(big.NewInt(0)), nil, nil
+		})
+
+		pooledAssets, err := fetcher.FetchPooledAssets(ctx, account)
+		Expect(err).ToNot(HaveOccurred(), "fetching the pooled assets should not fail")
+		Expect(pooledAssets).To(BeEmpty(), "a wallet without positions should hold no assets")
+	})
+})
@@ -0,0 +1,66 @@
+package balance
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/jrh3k5/cryptonabber-sync/v3/config"
+	"github.com/jrh3k5/cryptonabber-sync/v3/config/chain"
+	rpcconfig "github.com/jrh3k5/cryptonabber-sync/v3/config/rpc"
+	"github.com/jrh3k5/cryptonabber-sync/v3/http/json/rpc"
+	"github.com/jrh3k5/cryptonabber-sync/v3/token"
+)
+
+// VirtualPriceLPFetcher fetches the balance of the LP token of a pool that reports the value of its LP token (e.g., a Curve or Balancer pool),
+// expressed in the reference asset in which that value is reported.
+type VirtualPriceLPFetcher struct {
+	erc20Fetcher             Fetcher[*config.ERC20Account]
+	decimalsResolver         token.DecimalsResolver
+	rpcConfigurationResolver rpcconfig.ConfigurationResolver
+	ethCaller                rpc.EthCaller
+}
+
+// NewVirtualPriceLPFetcher creates a new VirtualPriceLPFetcher.
+func NewVirtualPriceLPFetcher(erc20Fetcher Fetcher[*config.ERC20Account], decimalsResolver token.DecimalsResolver, rpcConfigurationResolver rpcconfig.ConfigurationResolver, ethCaller rpc.EthCaller) *VirtualPriceLPFetcher {
+	return &VirtualPriceLPFetcher{
+		erc20Fetcher:             erc20Fetcher,
+		decimalsResolver:         decimalsResolver,
+		rpcConfigurationResolver: rpcConfigurationResolver,
+		ethCaller:                ethCaller,
+	}
+}
+
+func (v *VirtualPriceLPFetcher) FetchBalance(ctx context.Context, onchainAccount *config.VirtualPriceLPAccount) (*big.Int, error) {
+	lpBalance, err := v.erc20Fetcher.FetchBalance(ctx, &onchainAccount.ERC20Account)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch LP token balance: %w", err)
+	}
+
+	rpcURL, err := token.ResolveRPCURL(ctx, v.rpcConfigurationResolver, onchainAccount.OnchainAsset, chain.TypeEVM)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve RPC URL: %w", err)
+	}
+
+	referenceBalance, err := convertAtRate(ctx, v.ethCaller, rpcURL, onchainAccount.PoolAddress, onchainAccount.RateFunction, onchainAccount.RateDecimals, lpBalance)
+	if err != nil {
+		return nil, err
+	}
+
+	// The rate converts the LP token into the reference asset at the LP token's precision, which is often not that of the reference asset
+	lpDecimals, err := v.decimalsResolver.ResolveDecimals(ctx, onchainAccount.OnchainAsset, &onchainAccount.TokenAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve decimals of LP token: %w", err)
+	}
+
+	referenceDecimals, err := v.decimalsResolver.ResolveDecimals(ctx, onchainAccount.OnchainAsset, onchainAccount.ReferenceTokenAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve decimals of reference asset: %w", err)
+	}
+
+	if referenceDecimals > lpDecimals {
+		return referenceBalance.Mul(referenceBalance, pow10(referenceDecimals-lpDecimals)), nil
+	}
+
+	return referenceBalance.Quo(referenceBalance, pow10(lpDecimals-referenceDecimals)), nil
+}
@@ -0,0 +1,97 @@
+package balance_test
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"strings"
+
+	"github.com/jrh3k5/cryptonabber-sync/v3/config"
+	"github.com/jrh3k5/cryptonabber-sync/v3/http/json/rpc"
+	"github.com/jrh3k5/cryptonabber-sync/v3/token/balance"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("VirtualPriceLPFetcher", func() {
+	var erc20Fetcher *testERC20Fetcher
+	var decimalsResolver *testDecimalsResolver
+	var fetcher *balance.VirtualPriceLPFetcher
+
+	var ctx context.Context
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		erc20Fetcher = newTestERC20Fetcher()
+		decimalsResolver = &testDecimalsResolver{
+			decimals: make(map[string]int),
+		}
+		fetcher = balance.NewVirtualPriceLPFetcher(erc20Fetcher, decimalsResolver, rpcConfigurationResolver, rpc.NewDirectEthCaller(http.DefaultClient, rpc.NewLatestBlockResolver()))
+	})
+
+	It("expresses the LP token balance in the reference asset's decimals", func() {
+		lpTokenAddress := "0x6c3F90f043a72FA612cbac8115EE7e52BDe6E490"
+		poolAddress := "0xbEbc44782C7dB0a1A60Cb6fe97d0b483032FF1C7"
+		referenceTokenAddress := "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"
+
+		erc20Fetcher.setBalance(lpTokenAddress, big.NewInt(3000000000000000000))
+		decimalsResolver.decimals[strings.ToLower(lpTokenAddress)] = 18
+		decimalsResolver.decimals[strings.ToLower(referenceTokenAddress)] = 6
+		evmNode.RegisterETHCallCall("get_virtual_price", poolAddress, nil, func(_ string, _ []string) (rpc.MockEVMNodeRPCResult, *rpc.MockEVMNodeRPCError, error) {
+			return rpc.NewMockEVMNodeRPCNumericResult(big.NewInt(1035000000000000000)), nil, nil
+		})
+
+		referenceBalance, err := fetcher.FetchBalance(ctx, &config.VirtualPriceLPAccount{
+			ERC20Account: config.ERC20Account{
+				OnchainAsset: config.OnchainAsset{
+					ChainName: chainName,
+				},
+				TokenAddress: lpTokenAddress,
+			},
+			PoolAddress:           poolAddress,
+			RateFunction:          "get_virtual_price",
+			RateDecimals:          18,
+			ReferenceTokenAddress: &referenceTokenAddress,
+		})
+		Expect(err).ToNot(HaveOccurred(), "fetching the balance should not fail")
+		Expect(referenceBalance).To(Equal(big.NewInt(3105000)), "the balance should be expressed in the reference asset")
+	})
+
+	It("values a balance in the native coin", func() {
+		bptAddress := "0x32296969Ef14EB0c6d29669C550D4a0449130230"
+
+		erc20Fetcher.setBalance(bptAddress, big.NewInt(2000000000000000000))
+		decimalsResolver.decimals[strings.ToLower(bptAddress)] = 18
+		evmNode.RegisterETHCallCall("getRate", bptAddress, nil, func(_ string, _ []string) (rpc.MockEVMNodeRPCResult, *rpc.MockEVMNodeRPCError, error) {
+			return rpc.NewMockEVMNodeRPCNumericResult(big.NewInt(1020000000000000000)), nil, nil
+		})
+
+		referenceBalance, err := fetcher.FetchBalance(ctx, &config.VirtualPriceLPAccount{
+			ERC20Account: config.ERC20Account{
+				OnchainAsset: config.OnchainAsset{
+					ChainName: chainName,
+				},
+				TokenAddress: bptAddress,
+			},
+			PoolAddress:  bptAddress,
+			RateFunction: "getRate",
+			RateDecimals: 18,
+		})
+		Expect(err).ToNot(HaveOccurred(), "fetching the balance should not fail")
+		Expect(referenceBalance).To(Equal(big.NewInt(2040000000000000000)), "the balance should be expressed in the native coin")
+	})
+})
+
+// testDecimalsResolver resolves the decimals of tokens from a fixed set, treating a nil address as a native coin with 18 decimals.
+type testDecimalsResolver struct {
+	decimals map[string]int // lowercased token address -> decimals
+}
+
+func (t *testDecimalsResolver) ResolveDecimals(_ context.Context, _ config.OnchainAsset, tokenAddress *string) (int, error) {
+	if tokenAddress == nil {
+		return 18, nil
+	}
+
+	return t.decimals[strings.ToLower(*tokenAddress)], nil
+}
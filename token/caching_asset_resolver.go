@@ -0,0 +1,125 @@
+package token
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jrh3k5/cryptonabber-sync/v3/cache"
+	"github.com/jrh3k5/cryptonabber-sync/v3/config"
+	"github.com/jrh3k5/cryptonabber-sync/v3/evm"
+)
+
+// AssetCacheKeyFunc identifies the contract from which an asset address is resolved for an onchain account.
+// It returns the onchain asset of the account and a key identifying the contract and the means by which the address is read from it;
+// the returned bool is false if the address is not read onchain, and so is not worth caching.
+type AssetCacheKeyFunc[M config.OnchainAccount] func(onchainAccount M) (config.OnchainAsset, string, bool)
+
+// ERC4626AssetCacheKey identifies the vault from which the address of an ERC4626 vault's asset is read.
+func ERC4626AssetCacheKey(onchainAccount *config.ERC4626Account) (config.OnchainAsset, string, bool) {
+	if onchainAccount == nil || onchainAccount.BackingAsset != nil || onchainAccount.VaultAddress == "" {
+		return config.OnchainAsset{}, "", false
+	}
+
+	cacheKey := "erc4626/" + strings.ToLower(onchainAccount.VaultAddress)
+	if onchainAccount.AssetFunction != "" {
+		cacheKey += "/" + onchainAccount.AssetFunction
+	}
+
+	return onchainAccount.OnchainAsset, cacheKey, true
+}
+
+// ERC20WrapperAssetCacheKey identifies the wrapper token, and function, from which the address of a wrapped token is read.
+func ERC20WrapperAssetCacheKey(onchainAccount *config.ERC20WrapperAccount) (config.OnchainAsset, string, bool) {
+	if onchainAccount == nil {
+		return config.OnchainAsset{}, "", false
+	}
+
+	return onchainAccount.OnchainAsset, "erc20_wrapper/" + strings.ToLower(onchainAccount.TokenAddress) + "/" + onchainAccount.BaseTokenAddressFunction, true
+}
+
+// AaveSupplyAssetCacheKey identifies the aToken from which the address of the asset supplied to an Aave v3 market is read.
+func AaveSupplyAssetCacheKey(onchainAccount *config.AaveSupplyAccount) (config.OnchainAsset, string, bool) {
+	if onchainAccount == nil {
+		return config.OnchainAsset{}, "", false
+	}
+
+	return onchainAccount.OnchainAsset, "aave_supply/" + strings.ToLower(onchainAccount.ATokenAddress), true
+}
+
+// AaveDebtAssetCacheKey identifies the debt token from which the address of the asset borrowed from an Aave v3 market is read.
+func AaveDebtAssetCacheKey(onchainAccount *config.AaveDebtAccount) (config.OnchainAsset, string, bool) {
+	if onchainAccount == nil {
+		return config.OnchainAsset{}, "", false
+	}
+
+	return onchainAccount.OnchainAsset, "aave_debt/" + strings.ToLower(onchainAccount.DebtTokenAddress), true
+}
+
+// CometSupplyAssetCacheKey identifies the Comet contract from which the address of the base asset of a Compound v3 market is read.
+func CometSupplyAssetCacheKey(onchainAccount *config.CometSupplyAccount) (config.OnchainAsset, string, bool) {
+	if onchainAccount == nil {
+		return config.OnchainAsset{}, "", false
+	}
+
+	return onchainAccount.OnchainAsset, "comet/" + strings.ToLower(onchainAccount.CometAddress), true
+}
+
+// CometDebtAssetCacheKey identifies the Comet contract from which the address of the base asset of a Compound v3 market is read.
+func CometDebtAssetCacheKey(onchainAccount *config.CometDebtAccount) (config.OnchainAsset, string, bool) {
+	if onchainAccount == nil {
+		return config.OnchainAsset{}, "", false
+	}
+
+	return onchainAccount.OnchainAsset, "comet/" + strings.ToLower(onchainAccount.CometAddress), true
+}
+
+// CachingAssetResolver is an AssetResolver that caches the asset addresses resolved by another AssetResolver.
+// The asset underlying a vault, wrapper, or lending market never changes, so addresses are cached indefinitely.
+type CachingAssetResolver[M config.OnchainAccount] struct {
+	delegate       AssetResolver[M]
+	chainIDFetcher evm.ChainIDFetcher
+	cache          *cache.File
+	cacheKey       AssetCacheKeyFunc[M]
+}
+
+// NewCachingAssetResolver builds a CachingAssetResolver that caches the addresses resolved by the given resolver in the given cache,
+// keyed by the given function.
+func NewCachingAssetResolver[M config.OnchainAccount](delegate AssetResolver[M], chainIDFetcher evm.ChainIDFetcher, cache *cache.File, cacheKey AssetCacheKeyFunc[M]) *CachingAssetResolver[M] {
+	return &CachingAssetResolver[M]{
+		delegate:       delegate,
+		chainIDFetcher: chainIDFetcher,
+		cache:          cache,
+		cacheKey:       cacheKey,
+	}
+}
+
+func (c *CachingAssetResolver[M]) ResolveAssetAddress(ctx context.Context, onchainAccount M) (*string, error) {
+	onchainAsset, contractKey, isCacheable := c.cacheKey(onchainAccount)
+	if !isCacheable {
+		return c.delegate.ResolveAssetAddress(ctx, onchainAccount)
+	}
+
+	// Contracts are cached by chain ID, not chain name
+	chainID, err := c.chainIDFetcher.GetChainID(ctx, onchainAsset.ChainName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve chain ID: %w", err)
+	}
+
+	cacheKey := fmt.Sprintf("asset_address/%v/%s", chainID, contractKey)
+
+	var assetAddress string
+	if c.cache.Get(cacheKey, &assetAddress) {
+		return &assetAddress, nil
+	}
+
+	resolvedAddress, err := c.delegate.ResolveAssetAddress(ctx, onchainAccount)
+	if err != nil || resolvedAddress == nil {
+		return resolvedAddress, err
+	}
+
+	// The address is returned even if it cannot be cached
+	_ = c.cache.Set(cacheKey, *resolvedAddress, 0)
+
+	return resolvedAddress, nil
+}
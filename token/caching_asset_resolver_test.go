@@ -0,0 +1,68 @@
+package token_test
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/jrh3k5/cryptonabber-sync/v3/cache"
+	"github.com/jrh3k5/cryptonabber-sync/v3/config"
+	"github.com/jrh3k5/cryptonabber-sync/v3/token"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CachingAssetResolver", func() {
+	var ctx context.Context
+	var fileCache *cache.File
+	var delegate *stubAssetResolver
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		fileCache = cache.LoadFile(filepath.Join(GinkgoT().TempDir(), "cache.json"))
+		delegate = &stubAssetResolver{assetAddress: "0xasset"}
+	})
+
+	It("resolves the asset of a vault only once", func() {
+		resolver := token.NewCachingAssetResolver(delegate, &stubChainIDFetcher{}, fileCache, token.ERC4626AssetCacheKey)
+		account := &config.ERC4626Account{
+			OnchainAsset: config.OnchainAsset{ChainName: chainName},
+			VaultAddress: "0xvault",
+		}
+
+		for range 2 {
+			assetAddress, err := resolver.ResolveAssetAddress(ctx, account)
+			Expect(err).ToNot(HaveOccurred(), "resolving the asset address should not fail")
+			Expect(assetAddress).To(HaveValue(Equal("0xasset")), "the asset address should be resolved")
+		}
+
+		Expect(delegate.resolveCount).To(Equal(1), "the asset address should only be resolved once")
+	})
+
+	It("does not cache the configured backing asset of a vault", func() {
+		resolver := token.NewCachingAssetResolver(delegate, &stubChainIDFetcher{}, fileCache, token.ERC4626AssetCacheKey)
+		backingAddress := "0xbacking"
+		account := &config.ERC4626Account{
+			OnchainAsset: config.OnchainAsset{ChainName: chainName},
+			VaultAddress: "0xvault",
+			BackingAsset: &config.ERC4626BackingAsset{ContractAddress: &backingAddress},
+		}
+
+		for range 2 {
+			_, err := resolver.ResolveAssetAddress(ctx, account)
+			Expect(err).ToNot(HaveOccurred(), "resolving the asset address should not fail")
+		}
+
+		Expect(delegate.resolveCount).To(Equal(2), "the delegate should be consulted each time")
+	})
+})
+
+type stubAssetResolver struct {
+	assetAddress string
+	resolveCount int
+}
+
+func (s *stubAssetResolver) ResolveAssetAddress(_ context.Context, _ *config.ERC4626Account) (*string, error) {
+	s.resolveCount++
+	assetAddress := s.assetAddress
+	return &assetAddress, nil
+}
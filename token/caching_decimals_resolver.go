@@ -0,0 +1,57 @@
+package token
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jrh3k5/cryptonabber-sync/v3/cache"
+	"github.com/jrh3k5/cryptonabber-sync/v3/config"
+	"github.com/jrh3k5/cryptonabber-sync/v3/evm"
+)
+
+// CachingDecimalsResolver is a DecimalsResolver that caches the decimals resolved by another DecimalsResolver.
+// The decimals of a token never change, so they are cached indefinitely.
+type CachingDecimalsResolver struct {
+	delegate       DecimalsResolver
+	chainIDFetcher evm.ChainIDFetcher
+	cache          *cache.File
+}
+
+// NewCachingDecimalsResolver builds a CachingDecimalsResolver that caches the decimals resolved by the given resolver in the given cache.
+func NewCachingDecimalsResolver(delegate DecimalsResolver, chainIDFetcher evm.ChainIDFetcher, cache *cache.File) *CachingDecimalsResolver {
+	return &CachingDecimalsResolver{
+		delegate:       delegate,
+		chainIDFetcher: chainIDFetcher,
+		cache:          cache,
+	}
+}
+
+func (c *CachingDecimalsResolver) ResolveDecimals(ctx context.Context, onchainAsset config.OnchainAsset, tokenAddress *string) (int, error) {
+	if tokenAddress == nil {
+		return c.delegate.ResolveDecimals(ctx, onchainAsset, tokenAddress)
+	}
+
+	// Tokens are cached by chain ID, not chain name
+	chainID, err := c.chainIDFetcher.GetChainID(ctx, onchainAsset.ChainName)
+	if err != nil {
+		return 0, fmt.Errorf("failed to retrieve chain ID: %w", err)
+	}
+
+	cacheKey := fmt.Sprintf("decimals/%v/%s", chainID, strings.ToLower(*tokenAddress))
+
+	var decimals int
+	if c.cache.Get(cacheKey, &decimals) {
+		return decimals, nil
+	}
+
+	decimals, err = c.delegate.ResolveDecimals(ctx, onchainAsset, tokenAddress)
+	if err != nil {
+		return 0, err
+	}
+
+	// The decimals are returned even if they cannot be cached
+	_ = c.cache.Set(cacheKey, decimals, 0)
+
+	return decimals, nil
+}
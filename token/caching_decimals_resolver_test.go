@@ -0,0 +1,68 @@
+package token_test
+
+import (
+	"context"
+	"math/big"
+	"path/filepath"
+
+	"github.com/jrh3k5/cryptonabber-sync/v3/cache"
+	"github.com/jrh3k5/cryptonabber-sync/v3/config"
+	"github.com/jrh3k5/cryptonabber-sync/v3/token"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CachingDecimalsResolver", func() {
+	var ctx context.Context
+	var cacheFile string
+	var delegate *stubDecimalsResolver
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		cacheFile = filepath.Join(GinkgoT().TempDir(), "cache.json")
+		delegate = &stubDecimalsResolver{decimals: 6}
+	})
+
+	It("resolves the decimals of a token only once across runs", func() {
+		tokenAddress := "0xAAAA"
+		onchainAsset := config.OnchainAsset{ChainName: chainName}
+
+		firstCache := cache.LoadFile(cacheFile)
+		decimals, err := token.NewCachingDecimalsResolver(delegate, &stubChainIDFetcher{}, firstCache).ResolveDecimals(ctx, onchainAsset, &tokenAddress)
+		Expect(err).ToNot(HaveOccurred(), "resolving the decimals should not fail")
+		Expect(decimals).To(Equal(6), "the decimals should be resolved by the delegate")
+		Expect(firstCache.Save()).To(Succeed(), "saving the cache should not fail")
+
+		lowercasedAddress := "0xaaaa"
+		decimals, err = token.NewCachingDecimalsResolver(delegate, &stubChainIDFetcher{}, cache.LoadFile(cacheFile)).ResolveDecimals(ctx, onchainAsset, &lowercasedAddress)
+		Expect(err).ToNot(HaveOccurred(), "resolving the cached decimals should not fail")
+		Expect(decimals).To(Equal(6), "the cached decimals should be used")
+		Expect(delegate.resolveCount).To(Equal(1), "the decimals should only be resolved once")
+	})
+
+	It("does not cache the decimals of native coins", func() {
+		resolver := token.NewCachingDecimalsResolver(delegate, &stubChainIDFetcher{}, cache.LoadFile(cacheFile))
+		for range 2 {
+			_, err := resolver.ResolveDecimals(ctx, config.OnchainAsset{ChainName: chainName}, nil)
+			Expect(err).ToNot(HaveOccurred(), "resolving the decimals should not fail")
+		}
+
+		Expect(delegate.resolveCount).To(Equal(2), "the delegate should be consulted each time")
+	})
+})
+
+type stubDecimalsResolver struct {
+	decimals     int
+	resolveCount int
+}
+
+func (s *stubDecimalsResolver) ResolveDecimals(_ context.Context, _ config.OnchainAsset, _ *string) (int, error) {
+	s.resolveCount++
+	return s.decimals, nil
+}
+
+type stubChainIDFetcher struct{}
+
+func (*stubChainIDFetcher) GetChainID(_ context.Context, _ string) (*big.Int, error) {
+	return big.NewInt(1), nil
+}
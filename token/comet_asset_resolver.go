@@ -0,0 +1,46 @@
+package token
+
+import (
+	"context"
+
+	"github.com/jrh3k5/cryptonabber-sync/v3/config"
+	rpcconfig "github.com/jrh3k5/cryptonabber-sync/v3/config/rpc"
+	"github.com/jrh3k5/cryptonabber-sync/v3/http/json/rpc"
+)
+
+// baseTokenFunction is the function by which a Compound v3 (Comet) market expresses the asset that is supplied to and borrowed from it.
+const baseTokenFunction = "baseToken"
+
+// CometSupplyAssetResolver resolves the base asset supplied to a Compound v3 (Comet) market.
+type CometSupplyAssetResolver struct {
+	rpcConfigurationResolver rpcconfig.ConfigurationResolver
+	ethCaller                rpc.EthCaller
+}
+
+func NewCometSupplyAssetResolver(rpcConfigurationResolver rpcconfig.ConfigurationResolver, ethCaller rpc.EthCaller) *CometSupplyAssetResolver {
+	return &CometSupplyAssetResolver{
+		rpcConfigurationResolver: rpcConfigurationResolver,
+		ethCaller:                ethCaller,
+	}
+}
+
+func (c *CometSupplyAssetResolver) ResolveAssetAddress(ctx context.Context, onchainAccount *config.CometSupplyAccount) (*string, error) {
+	return resolveAddressFunction(ctx, c.rpcConfigurationResolver, c.ethCaller, onchainAccount.OnchainAsset, onchainAccount.CometAddress, baseTokenFunction)
+}
+
+// CometDebtAssetResolver resolves the base asset borrowed from a Compound v3 (Comet) market.
+type CometDebtAssetResolver struct {
+	rpcConfigurationResolver rpcconfig.ConfigurationResolver
+	ethCaller                rpc.EthCaller
+}
+
+func NewCometDebtAssetResolver(rpcConfigurationResolver rpcconfig.ConfigurationResolver, ethCaller rpc.EthCaller) *CometDebtAssetResolver {
+	return &CometDebtAssetResolver{
+		rpcConfigurationResolver: rpcConfigurationResolver,
+		ethCaller:                ethCaller,
+	}
+}
+
+func (c *CometDebtAssetResolver) ResolveAssetAddress(ctx context.Context, onchainAccount *config.CometDebtAccount) (*string, error) {
+	return resolveAddressFunction(ctx, c.rpcConfigurationResolver, c.ethCaller, onchainAccount.OnchainAsset, onchainAccount.CometAddress, baseTokenFunction)
+}
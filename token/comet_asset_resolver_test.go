@@ -0,0 +1,83 @@
+package token_test
+
+import (
+	"context"
+	"net/http"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jrh3k5/cryptonabber-sync/v3/config"
+	"github.com/jrh3k5/cryptonabber-sync/v3/http/json/rpc"
+	"github.com/jrh3k5/cryptonabber-sync/v3/token"
+)
+
+var _ = Describe("CometSupplyAssetResolver", func() {
+	var cometSupplyAssetResolver *token.CometSupplyAssetResolver
+
+	var ctx context.Context
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		cometSupplyAssetResolver = token.NewCometSupplyAssetResolver(rpcConfigurationResolver, rpc.NewDirectEthCaller(http.DefaultClient, rpc.NewLatestBlockResolver()))
+	})
+
+	Describe("ResolveAssetAddress", func() {
+		It("returns the address of the base asset of the market", func() {
+			cometAddress := "0xc3d688B66703497DAA19211EEdff47f25384cdc3"
+			baseTokenAddress := "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"
+
+			evmNode.RegisterETHCallCall("baseToken", cometAddress, nil, func(_ string, _ []string) (rpc.MockEVMNodeRPCResult, *rpc.MockEVMNodeRPCError, error) {
+				return rpc.NewMockEVMNodeRPCAddressResult(baseTokenAddress), nil, nil
+			})
+
+			address, err := cometSupplyAssetResolver.ResolveAssetAddress(ctx, &config.CometSupplyAccount{
+				CometAccount: config.CometAccount{
+					OnchainAsset: config.OnchainAsset{
+						ChainName: chainName,
+					},
+					CometAddress: cometAddress,
+				},
+			})
+
+			Expect(err).To(BeNil(), "resolving the asset address should not fail")
+			Expect(*address).To(Equal(baseTokenAddress), "the asset address should be the base token address")
+		})
+	})
+})
+
+var _ = Describe("CometDebtAssetResolver", func() {
+	var cometDebtAssetResolver *token.CometDebtAssetResolver
+
+	var ctx context.Context
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		cometDebtAssetResolver = token.NewCometDebtAssetResolver(rpcConfigurationResolver, rpc.NewDirectEthCaller(http.DefaultClient, rpc.NewLatestBlockResolver()))
+	})
+
+	Describe("ResolveAssetAddress", func() {
+		It("returns the address of the base asset of the market", func() {
+			cometAddress := "0xb125E6687d4313864e53df431d5425969c15Eb2F"
+			baseTokenAddress := "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913"
+
+			evmNode.RegisterETHCallCall("baseToken", cometAddress, nil, func(_ string, _ []string) (rpc.MockEVMNodeRPCResult, *rpc.MockEVMNodeRPCError, error) {
+				return rpc.NewMockEVMNodeRPCAddressResult(baseTokenAddress), nil, nil
+			})
+
+			address, err := cometDebtAssetResolver.ResolveAssetAddress(ctx, &config.CometDebtAccount{
+				CometAccount: config.CometAccount{
+					OnchainAsset: config.OnchainAsset{
+						ChainName: chainName,
+					},
+					CometAddress: cometAddress,
+				},
+			})
+
+			Expect(err).To(BeNil(), "resolving the asset address should not fail")
+			Expect(*address).To(Equal(baseTokenAddress), "the asset address should be the base token address")
+		})
+	})
+})
@@ -3,24 +3,23 @@ package token
 import (
 	"context"
 	"fmt"
-	"strings"
 
 	"github.com/jrh3k5/cryptonabber-sync/v3/config"
 	"github.com/jrh3k5/cryptonabber-sync/v3/config/chain"
 	rpcconfig "github.com/jrh3k5/cryptonabber-sync/v3/config/rpc"
-	synchttp "github.com/jrh3k5/cryptonabber-sync/v3/http"
+	"github.com/jrh3k5/cryptonabber-sync/v3/evm/abi"
 	"github.com/jrh3k5/cryptonabber-sync/v3/http/json/rpc"
 )
 
 type ERC20WrapperAssetResolver struct {
 	rpcConfigurationResolver rpcconfig.ConfigurationResolver
-	doer                     synchttp.Doer
+	ethCaller                rpc.EthCaller
 }
 
-func NewERC20WrapperAssetResolver(rpcConfigurationResolver rpcconfig.ConfigurationResolver, doer synchttp.Doer) *ERC20WrapperAssetResolver {
+func NewERC20WrapperAssetResolver(rpcConfigurationResolver rpcconfig.ConfigurationResolver, ethCaller rpc.EthCaller) *ERC20WrapperAssetResolver {
 	return &ERC20WrapperAssetResolver{
 		rpcConfigurationResolver: rpcConfigurationResolver,
-		doer:                     doer,
+		ethCaller:                ethCaller,
 	}
 }
 
@@ -30,12 +29,17 @@ func (e *ERC20WrapperAssetResolver) ResolveAssetAddress(ctx context.Context, onc
 		return nil, fmt.Errorf("failed to resolve RPC URL: %w", err)
 	}
 
-	assetAddress, err := rpc.ExecuteEthCall(ctx, e.doer, rpcURL, onchainAccount.BaseTokenAddressFunction, onchainAccount.TokenAddress)
+	assetResult, err := e.ethCaller.EthCall(ctx, rpcURL, onchainAccount.BaseTokenAddressFunction, onchainAccount.TokenAddress)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute %s: %w", onchainAccount.BaseTokenAddressFunction, err)
 	}
 
-	substringAddress := strings.ReplaceAll(assetAddress, "000000000000000000000000", "")
+	decoded, err := abi.DecodeHex([]string{"address"}, assetResult)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode result of %s: %w", onchainAccount.BaseTokenAddressFunction, err)
+	}
+
+	assetAddress := decoded[0].(string)
 
-	return &substringAddress, nil
+	return &assetAddress, nil
 }
@@ -20,7 +20,7 @@ var _ = Describe("ERC20WrapperAssetResolver", func() {
 	BeforeEach(func() {
 		ctx = context.Background()
 
-		erc4626AssetResolver = token.NewERC20WrapperAssetResolver(rpcConfigurationResolver, http.DefaultClient)
+		erc4626AssetResolver = token.NewERC20WrapperAssetResolver(rpcConfigurationResolver, rpc.NewDirectEthCaller(http.DefaultClient, rpc.NewLatestBlockResolver()))
 	})
 
 	Describe("ResolveAssetAddress", func() {
@@ -4,24 +4,23 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"strings"
 
 	"github.com/jrh3k5/cryptonabber-sync/v3/config"
 	"github.com/jrh3k5/cryptonabber-sync/v3/config/chain"
 	rpcconfig "github.com/jrh3k5/cryptonabber-sync/v3/config/rpc"
-	synchttp "github.com/jrh3k5/cryptonabber-sync/v3/http"
+	"github.com/jrh3k5/cryptonabber-sync/v3/evm/abi"
 	"github.com/jrh3k5/cryptonabber-sync/v3/http/json/rpc"
 )
 
 type ERC4626AssetResolver struct {
 	rpcConfigurationResolver rpcconfig.ConfigurationResolver
-	doer                     synchttp.Doer
+	ethCaller                rpc.EthCaller
 }
 
-func NewERC4626AssetResolver(rpcConfigurationResolver rpcconfig.ConfigurationResolver, doer synchttp.Doer) *ERC4626AssetResolver {
+func NewERC4626AssetResolver(rpcConfigurationResolver rpcconfig.ConfigurationResolver, ethCaller rpc.EthCaller) *ERC4626AssetResolver {
 	return &ERC4626AssetResolver{
 		rpcConfigurationResolver: rpcConfigurationResolver,
-		doer:                     doer,
+		ethCaller:                ethCaller,
 	}
 }
 
@@ -43,12 +42,22 @@ func (r *ERC4626AssetResolver) ResolveAssetAddress(ctx context.Context, onchainA
 		return nil, fmt.Errorf("failed to resolve node URL for asset %s: %w", onchainAccount.OnchainAsset, err)
 	}
 
-	assetAddress, err := rpc.ExecuteEthCall(ctx, r.doer, nodeURL, "asset", onchainAccount.VaultAddress)
+	assetFunction := onchainAccount.AssetFunction
+	if assetFunction == "" {
+		assetFunction = "asset"
+	}
+
+	assetResult, err := r.ethCaller.EthCall(ctx, nodeURL, assetFunction, onchainAccount.VaultAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve asset for ERC4626 account via %s: %w", assetFunction, err)
+	}
+
+	decoded, err := abi.DecodeHex([]string{"address"}, assetResult)
 	if err != nil {
-		return nil, fmt.Errorf("failed to resolve asset for ERC4626 account: %w", err)
+		return nil, fmt.Errorf("failed to decode asset for ERC4626 account: %w", err)
 	}
 
-	substringAddress := strings.ReplaceAll(assetAddress, "000000000000000000000000", "")
+	assetAddress := decoded[0].(string)
 
-	return &substringAddress, nil
+	return &assetAddress, nil
 }
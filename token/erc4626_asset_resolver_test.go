@@ -20,7 +20,7 @@ var _ = Describe("ERC4626AssetResolver", func() {
 	BeforeEach(func() {
 		ctx = context.Background()
 
-		erc4626AssetResolver = token.NewERC4626AssetResolver(rpcConfigurationResolver, http.DefaultClient)
+		erc4626AssetResolver = token.NewERC4626AssetResolver(rpcConfigurationResolver, rpc.NewDirectEthCaller(http.DefaultClient, rpc.NewLatestBlockResolver()))
 	})
 
 	When("the contract has an asset function on it", func() {
@@ -44,6 +44,28 @@ var _ = Describe("ERC4626AssetResolver", func() {
 		})
 	})
 
+	When("the account has an asset function configured", func() {
+		It("calls that function to get the address of the asset", func() {
+			vaultAddress := "0xdA816459F1AB5631232FE5e97a05BBBb94970c95"
+			assetAddress := "0x6B175474E89094C44Da98b954EedeAC495271d0F"
+			evmNode.RegisterETHCallCall("token", vaultAddress, nil, func(_ string, _ []string) (rpc.MockEVMNodeRPCResult, *rpc.MockEVMNodeRPCError, error) {
+				return rpc.NewMockEVMNodeRPCAddressResult(assetAddress), nil, nil
+			})
+
+			address, err := erc4626AssetResolver.ResolveAssetAddress(ctx, &config.ERC4626Account{
+				OnchainAsset: config.OnchainAsset{
+					ChainName: chainName,
+				},
+				VaultAddress:  vaultAddress,
+				AssetFunction: "token",
+			})
+
+			Expect(err).ToNot(HaveOccurred(), "resolving the asset address should not fail")
+			Expect(address).ToNot(BeNil(), "the asset address should be returned")
+			Expect(*address).To(Equal(assetAddress), "the asset address should be returned")
+		})
+	})
+
 	When("the account has no vault address", func() {
 		It("rejects the request", func() {
 			_, err := erc4626AssetResolver.ResolveAssetAddress(ctx, &config.ERC4626Account{
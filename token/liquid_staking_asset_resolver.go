@@ -0,0 +1,20 @@
+package token
+
+import (
+	"context"
+
+	"github.com/jrh3k5/cryptonabber-sync/v3/config"
+)
+
+// LiquidStakingAssetResolver resolves the asset underlying a liquid staking token, which is configured rather than read onchain,
+// as the contracts of liquid staking tokens do not share a means of exposing it.
+type LiquidStakingAssetResolver struct {
+}
+
+func NewLiquidStakingAssetResolver() *LiquidStakingAssetResolver {
+	return &LiquidStakingAssetResolver{}
+}
+
+func (r *LiquidStakingAssetResolver) ResolveAssetAddress(_ context.Context, onchainAccount *config.LiquidStakingAccount) (*string, error) {
+	return onchainAccount.UnderlyingTokenAddress, nil
+}
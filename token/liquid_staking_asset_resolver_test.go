@@ -0,0 +1,39 @@
+package token_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jrh3k5/cryptonabber-sync/v3/config"
+	"github.com/jrh3k5/cryptonabber-sync/v3/token"
+)
+
+var _ = Describe("LiquidStakingAssetResolver", func() {
+	var liquidStakingAssetResolver *token.LiquidStakingAssetResolver
+	var ctx context.Context
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		liquidStakingAssetResolver = token.NewLiquidStakingAssetResolver()
+	})
+
+	Describe("ResolveAssetAddress", func() {
+		It("should return the underlying token address", func() {
+			underlyingTokenAddress := "0xae7ab96520DE3A18E5e111B5EaAb095312D7fE84"
+			address, err := liquidStakingAssetResolver.ResolveAssetAddress(ctx, &config.LiquidStakingAccount{
+				UnderlyingTokenAddress: &underlyingTokenAddress,
+			})
+			Expect(err).To(BeNil())
+			Expect(*address).To(Equal(underlyingTokenAddress))
+		})
+
+		It("should return nil for a token whose underlying asset is the native coin", func() {
+			address, err := liquidStakingAssetResolver.ResolveAssetAddress(ctx, &config.LiquidStakingAccount{})
+			Expect(err).To(BeNil())
+			Expect(address).To(BeNil())
+		})
+	})
+})
@@ -0,0 +1,20 @@
+package token
+
+import (
+	"context"
+
+	"github.com/jrh3k5/cryptonabber-sync/v3/config"
+)
+
+// NativeAssetResolver resolves the asset address of a chain's native coin.
+// Native coins have no contract address, so this always resolves to nil.
+type NativeAssetResolver struct {
+}
+
+func NewNativeAssetResolver() *NativeAssetResolver {
+	return &NativeAssetResolver{}
+}
+
+func (r *NativeAssetResolver) ResolveAssetAddress(_ context.Context, _ *config.NativeAccount) (*string, error) {
+	return nil, nil
+}
@@ -24,5 +24,10 @@ func ResolveRPCURL(ctx context.Context, configurationResolver rpc.ConfigurationR
 		return "", fmt.Errorf("RPC configuration for chain '%s' is not the required chain type of '%s'", onchainAsset.ChainName, requiredChainType)
 	}
 
-	return rpcConfig.RPCURL, nil
+	rpcURL := rpcConfig.PrimaryURL()
+	if rpcURL == "" {
+		return "", fmt.Errorf("no RPC URL configured for chain '%s'", onchainAsset.ChainName)
+	}
+
+	return rpcURL, nil
 }
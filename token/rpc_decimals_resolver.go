@@ -8,21 +8,21 @@ import (
 	"github.com/jrh3k5/cryptonabber-sync/v3/config"
 	"github.com/jrh3k5/cryptonabber-sync/v3/config/chain"
 	rpcconfig "github.com/jrh3k5/cryptonabber-sync/v3/config/rpc"
-	synchttp "github.com/jrh3k5/cryptonabber-sync/v3/http"
+	"github.com/jrh3k5/cryptonabber-sync/v3/evm/abi"
 	"github.com/jrh3k5/cryptonabber-sync/v3/http/json/rpc"
 )
 
 // RPCDecimalsResolver resolves the decimals of tokens using RPC calls.
 type RPCDecimalsResolver struct {
 	rpcConfigurationResolver rpcconfig.ConfigurationResolver
-	doer                     synchttp.Doer
+	ethCaller                rpc.EthCaller
 }
 
 // NewRPCDecimalsResolver builds an RPCDecimalsResolver.
-func NewRPCDecimalsResolver(rpcConfigurationResolver rpcconfig.ConfigurationResolver, doer synchttp.Doer) *RPCDecimalsResolver {
+func NewRPCDecimalsResolver(rpcConfigurationResolver rpcconfig.ConfigurationResolver, ethCaller rpc.EthCaller) *RPCDecimalsResolver {
 	return &RPCDecimalsResolver{
 		rpcConfigurationResolver: rpcConfigurationResolver,
-		doer:                     doer,
+		ethCaller:                ethCaller,
 	}
 }
 
@@ -37,13 +37,15 @@ func (r *RPCDecimalsResolver) ResolveDecimals(ctx context.Context, onchainAsset
 		return 0, fmt.Errorf("failed to resolve RPC URL: %w", err)
 	}
 
-	result, err := rpc.ExecuteEthCall(ctx, r.doer, rpcURL, "decimals", *tokenAddress)
+	result, err := r.ethCaller.EthCall(ctx, rpcURL, "decimals", *tokenAddress)
 	if err != nil {
 		return 0, fmt.Errorf("failed to resolve decimals: %w", err)
 	}
 
-	decimalsBigInt := new(big.Int)
-	decimalsBigInt.SetString(result[2:], 16)
+	decoded, err := abi.DecodeHex([]string{"uint8"}, result)
+	if err != nil {
+		return 0, fmt.Errorf("failed to decode decimals: %w", err)
+	}
 
-	return int(decimalsBigInt.Int64()), nil
+	return int(decoded[0].(*big.Int).Int64()), nil
 }
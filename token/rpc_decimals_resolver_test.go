@@ -20,7 +20,7 @@ var _ = Describe("RPCDecimalsResolver", func() {
 	BeforeEach(func() {
 		ctx = context.Background()
 
-		resolver = token.NewRPCDecimalsResolver(rpcConfigurationResolver, http.DefaultClient)
+		resolver = token.NewRPCDecimalsResolver(rpcConfigurationResolver, rpc.NewDirectEthCaller(http.DefaultClient, rpc.NewLatestBlockResolver()))
 	})
 
 	Describe("ResolveDecimals", func() {
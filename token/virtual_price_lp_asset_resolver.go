@@ -0,0 +1,20 @@
+package token
+
+import (
+	"context"
+
+	"github.com/jrh3k5/cryptonabber-sync/v3/config"
+)
+
+// VirtualPriceLPAssetResolver resolves the reference asset in which a pool reports the value of its LP token, which is configured
+// rather than read onchain, as a pool's virtual price is not tied to any one of the pool's assets.
+type VirtualPriceLPAssetResolver struct {
+}
+
+func NewVirtualPriceLPAssetResolver() *VirtualPriceLPAssetResolver {
+	return &VirtualPriceLPAssetResolver{}
+}
+
+func (r *VirtualPriceLPAssetResolver) ResolveAssetAddress(_ context.Context, onchainAccount *config.VirtualPriceLPAccount) (*string, error) {
+	return onchainAccount.ReferenceTokenAddress, nil
+}
@@ -0,0 +1,33 @@
+package token_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jrh3k5/cryptonabber-sync/v3/config"
+	"github.com/jrh3k5/cryptonabber-sync/v3/token"
+)
+
+var _ = Describe("VirtualPriceLPAssetResolver", func() {
+	var virtualPriceLPAssetResolver *token.VirtualPriceLPAssetResolver
+	var ctx context.Context
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		virtualPriceLPAssetResolver = token.NewVirtualPriceLPAssetResolver()
+	})
+
+	Describe("ResolveAssetAddress", func() {
+		It("should return the reference token address", func() {
+			referenceTokenAddress := "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"
+			address, err := virtualPriceLPAssetResolver.ResolveAssetAddress(ctx, &config.VirtualPriceLPAccount{
+				ReferenceTokenAddress: &referenceTokenAddress,
+			})
+			Expect(err).To(BeNil())
+			Expect(*address).To(Equal(referenceTokenAddress))
+		})
+	})
+})
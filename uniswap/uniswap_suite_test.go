@@ -0,0 +1,13 @@
+package uniswap_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestUniswap(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Uniswap Suite")
+}
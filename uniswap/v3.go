@@ -0,0 +1,153 @@
+// Package uniswap implements the fixed-point math by which Uniswap V3 (and its forks) derive the assets held by a position
+// from the position's liquidity, so that the results match those of the pool contracts themselves.
+package uniswap
+
+import (
+	"fmt"
+	"math/big"
+)
+
+const (
+	MinTick = -887272 // the lowest tick that can be used by a Uniswap V3 pool
+	MaxTick = 887272  // the highest tick that can be used by a Uniswap V3 pool
+)
+
+var (
+	q96  = new(big.Int).Lsh(big.NewInt(1), 96)
+	q128 = new(big.Int).Lsh(big.NewInt(1), 128)
+	q256 = new(big.Int).Lsh(big.NewInt(1), 256)
+
+	maxUint256 = new(big.Int).Sub(q256, big.NewInt(1))
+
+	// tickRatios are the Q128.128 values of 1/sqrt(1.0001)^(2^i), by which the ratio of a tick is built bit by bit, as in TickMath.getSqrtRatioAtTick.
+	tickRatios = []*big.Int{
+		mustParseHex("fffcb933bd6fad37aa2d162d1a594001"),
+		mustParseHex("fff97272373d413259a46990580e213a"),
+		mustParseHex("fff2e50f5f656932ef12357cf3c7fdcc"),
+		mustParseHex("ffe5caca7e10e4e61c3624eaa0941cd0"),
+		mustParseHex("ffcb9843d60f6159c9db58835c926644"),
+		mustParseHex("ff973b41fa98c081472e6896dfb254c0"),
+		mustParseHex("ff2ea16466c96a3843ec78b326b52861"),
+		mustParseHex("fe5dee046a99a2a811c461f1969c3053"),
+		mustParseHex("fcbe86c7900a88aedcffc83b479aa3a4"),
+		mustParseHex("f987a7253ac413176f2b074cf7815e54"),
+		mustParseHex("f3392b0822b70005940c7a398e4b70f3"),
+		mustParseHex("e7159475a2c29b7443b29c7fa6e889d9"),
+		mustParseHex("d097f3bdfd2022b8845ad8f792aa5825"),
+		mustParseHex("a9f746462d870fdf8a65dc1f90e061e5"),
+		mustParseHex("70d869a156d2a1b890bb3df62baf32f7"),
+		mustParseHex("31be135f97d08fd981231505542fcfa6"),
+		mustParseHex("9aa508b5b7a84e1c677de54f3e99bc9"),
+		mustParseHex("5d6af8dedb81196699c329225ee604"),
+		mustParseHex("2216e584f5fa1ea926041bedfe98"),
+		mustParseHex("48a170391f7dc42444e8fa2"),
+	}
+)
+
+// SqrtRatioAtTick gets the square root of the price at the given tick, 1.0001^tick, as a Q64.96 value.
+func SqrtRatioAtTick(tick int64) (*big.Int, error) {
+	if tick < MinTick || tick > MaxTick {
+		return nil, fmt.Errorf("tick %d is outside of the range of valid ticks [%d, %d]", tick, MinTick, MaxTick)
+	}
+
+	absTick := tick
+	if absTick < 0 {
+		absTick = -absTick
+	}
+
+	ratio := new(big.Int).Set(q128)
+	for bit, tickRatio := range tickRatios {
+		if absTick&(1<<bit) != 0 {
+			ratio.Mul(ratio, tickRatio)
+			ratio.Rsh(ratio, 128)
+		}
+	}
+
+	if tick > 0 {
+		ratio.Quo(maxUint256, ratio)
+	}
+
+	// Round up when converting from Q128.128 to Q64.96, so that the result is never less than the true ratio
+	remainder := new(big.Int).Mod(ratio, new(big.Int).Lsh(big.NewInt(1), 32))
+	sqrtRatio := ratio.Rsh(ratio, 32)
+	if remainder.Sign() != 0 {
+		sqrtRatio.Add(sqrtRatio, big.NewInt(1))
+	}
+
+	return sqrtRatio, nil
+}
+
+// AmountsForLiquidity gets the amounts of token0 and token1 held by the given liquidity between the given bounding square root prices
+// when the pool is at the given current square root price. All square root prices are Q64.96 values.
+func AmountsForLiquidity(sqrtRatioX96 *big.Int, sqrtRatioAX96 *big.Int, sqrtRatioBX96 *big.Int, liquidity *big.Int) (*big.Int, *big.Int) {
+	if sqrtRatioAX96.Cmp(sqrtRatioBX96) > 0 {
+		sqrtRatioAX96, sqrtRatioBX96 = sqrtRatioBX96, sqrtRatioAX96
+	}
+
+	switch {
+	case sqrtRatioX96.Cmp(sqrtRatioAX96) <= 0:
+		return amount0ForLiquidity(sqrtRatioAX96, sqrtRatioBX96, liquidity), big.NewInt(0)
+	case sqrtRatioX96.Cmp(sqrtRatioBX96) < 0:
+		return amount0ForLiquidity(sqrtRatioX96, sqrtRatioBX96, liquidity), amount1ForLiquidity(sqrtRatioAX96, sqrtRatioX96, liquidity)
+	default:
+		return big.NewInt(0), amount1ForLiquidity(sqrtRatioAX96, sqrtRatioBX96, liquidity)
+	}
+}
+
+// FeeGrowthInside gets the fees earned per unit of liquidity within the given range of ticks over the life of a pool, as a Q128.128 value,
+// from the pool's global fee growth and the fee growth outside of each of the range's bounding ticks.
+// As in the pool contracts, the arithmetic wraps around at 2^256.
+func FeeGrowthInside(tickCurrent int64, tickLower int64, tickUpper int64, feeGrowthGlobalX128 *big.Int, feeGrowthOutsideLowerX128 *big.Int, feeGrowthOutsideUpperX128 *big.Int) *big.Int {
+	feeGrowthBelow := feeGrowthOutsideLowerX128
+	if tickCurrent < tickLower {
+		feeGrowthBelow = wrapUint256(new(big.Int).Sub(feeGrowthGlobalX128, feeGrowthOutsideLowerX128))
+	}
+
+	feeGrowthAbove := feeGrowthOutsideUpperX128
+	if tickCurrent >= tickUpper {
+		feeGrowthAbove = wrapUint256(new(big.Int).Sub(feeGrowthGlobalX128, feeGrowthOutsideUpperX128))
+	}
+
+	feeGrowthInside := new(big.Int).Sub(feeGrowthGlobalX128, feeGrowthBelow)
+	feeGrowthInside.Sub(feeGrowthInside, feeGrowthAbove)
+
+	return wrapUint256(feeGrowthInside)
+}
+
+// FeesEarned gets the fees earned by the given liquidity since the fee growth within its range was last the given value.
+func FeesEarned(liquidity *big.Int, feeGrowthInsideX128 *big.Int, feeGrowthInsideLastX128 *big.Int) *big.Int {
+	feeGrowth := wrapUint256(new(big.Int).Sub(feeGrowthInsideX128, feeGrowthInsideLastX128))
+
+	return feeGrowth.Mul(feeGrowth, liquidity).Rsh(feeGrowth, 128)
+}
+
+// amount0ForLiquidity gets the amount of token0 held by the given liquidity between the given square root prices, rounding down.
+func amount0ForLiquidity(sqrtRatioAX96 *big.Int, sqrtRatioBX96 *big.Int, liquidity *big.Int) *big.Int {
+	amount0 := new(big.Int).Lsh(liquidity, 96)
+	amount0.Mul(amount0, new(big.Int).Sub(sqrtRatioBX96, sqrtRatioAX96))
+	amount0.Quo(amount0, sqrtRatioBX96)
+
+	return amount0.Quo(amount0, sqrtRatioAX96)
+}
+
+// amount1ForLiquidity gets the amount of token1 held by the given liquidity between the given square root prices, rounding down.
+func amount1ForLiquidity(sqrtRatioAX96 *big.Int, sqrtRatioBX96 *big.Int, liquidity *big.Int) *big.Int {
+	amount1 := new(big.Int).Sub(sqrtRatioBX96, sqrtRatioAX96)
+	amount1.Mul(amount1, liquidity)
+
+	return amount1.Quo(amount1, q96)
+}
+
+// wrapUint256 wraps the given value into the range of a uint256, as overflowing and underflowing arithmetic does onchain.
+func wrapUint256(value *big.Int) *big.Int {
+	return value.Mod(value, q256)
+}
+
+func mustParseHex(hex string) *big.Int {
+	value, ok := new(big.Int).SetString(hex, 16)
+	if !ok {
+		panic(fmt.Sprintf("invalid hex constant '%s'", hex))
+	}
+
+	return value
+}
@@ -0,0 +1,122 @@
+package uniswap_test
+
+import (
+	"math/big"
+
+	"github.com/jrh3k5/cryptonabber-sync/v3/uniswap"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SqrtRatioAtTick", func() {
+	q96 := new(big.Int).Lsh(big.NewInt(1), 96)
+
+	DescribeTable("matches the ratios of the pool contracts", func(tick int64, expectedRatio string) {
+		sqrtRatio, err := uniswap.SqrtRatioAtTick(tick)
+		Expect(err).ToNot(HaveOccurred(), "getting the ratio should not fail")
+		Expect(sqrtRatio.String()).To(Equal(expectedRatio), "the ratio should match that of TickMath")
+	},
+		Entry("the lowest tick", int64(uniswap.MinTick), "4295128739"),
+		Entry("tick zero", int64(0), q96.String()),
+		Entry("the highest tick", int64(uniswap.MaxTick), "1461446703485210103287273052203988822378723970342"))
+
+	DescribeTable("approximates the square root of 1.0001^tick", func(tick int64) {
+		sqrtRatio, err := uniswap.SqrtRatioAtTick(tick)
+		Expect(err).ToNot(HaveOccurred(), "getting the ratio should not fail")
+
+		expectedRatio := new(big.Float).SetPrec(256).SetInt(q96)
+		expectedRatio.Mul(expectedRatio, sqrtPowerOfBase(tick))
+
+		relativeError := new(big.Float).SetPrec(256).SetInt(sqrtRatio)
+		relativeError.Sub(relativeError, expectedRatio)
+		relativeError.Quo(relativeError, expectedRatio)
+		relativeError.Abs(relativeError)
+
+		Expect(relativeError.Cmp(big.NewFloat(1e-15))).To(BeNumerically("<", 0), "the ratio should be within rounding of the true ratio; the error is %v", relativeError)
+	},
+		Entry("a positive tick that uses every bit", int64(0x7ffff)),
+		Entry("a negative tick that uses every bit", int64(-0x7ffff)),
+		Entry("a typical positive tick", int64(201240)),
+		Entry("a typical negative tick", int64(-76020)),
+		Entry("a small tick", int64(1)))
+
+	It("rejects ticks outside of the valid range", func() {
+		_, err := uniswap.SqrtRatioAtTick(uniswap.MaxTick + 1)
+		Expect(err).To(HaveOccurred(), "a tick above the range should be rejected")
+
+		_, err = uniswap.SqrtRatioAtTick(uniswap.MinTick - 1)
+		Expect(err).To(HaveOccurred(), "a tick below the range should be rejected")
+	})
+})
+
+var _ = Describe("AmountsForLiquidity", func() {
+	q96 := new(big.Int).Lsh(big.NewInt(1), 96)
+	sqrtRatioA := new(big.Int).Set(q96)
+	sqrtRatioB := new(big.Int).Mul(q96, big.NewInt(2))
+	liquidity := big.NewInt(1000000)
+
+	It("holds only token0 when the price is below the range", func() {
+		amount0, amount1 := uniswap.AmountsForLiquidity(new(big.Int).Quo(q96, big.NewInt(2)), sqrtRatioA, sqrtRatioB, liquidity)
+		Expect(amount0).To(Equal(big.NewInt(500000)), "the liquidity should be held entirely in token0")
+		Expect(amount1.Sign()).To(BeZero(), "no token1 should be held")
+	})
+
+	It("holds only token1 when the price is above the range", func() {
+		amount0, amount1 := uniswap.AmountsForLiquidity(new(big.Int).Mul(q96, big.NewInt(3)), sqrtRatioA, sqrtRatioB, liquidity)
+		Expect(amount0.Sign()).To(BeZero(), "no token0 should be held")
+		Expect(amount1).To(Equal(big.NewInt(1000000)), "the liquidity should be held entirely in token1")
+	})
+
+	It("holds both tokens when the price is within the range", func() {
+		sqrtRatio := new(big.Int).Quo(new(big.Int).Mul(q96, big.NewInt(3)), big.NewInt(2))
+		amount0, amount1 := uniswap.AmountsForLiquidity(sqrtRatio, sqrtRatioB, sqrtRatioA, liquidity)
+		Expect(amount0).To(Equal(big.NewInt(166666)), "the token0 above the price should be held")
+		Expect(amount1).To(Equal(big.NewInt(500000)), "the token1 below the price should be held")
+	})
+})
+
+var _ = Describe("fees", func() {
+	q256 := new(big.Int).Lsh(big.NewInt(1), 256)
+
+	It("measures the fee growth within a range that contains the current tick", func() {
+		feeGrowthInside := uniswap.FeeGrowthInside(0, -60, 60, big.NewInt(100), big.NewInt(10), big.NewInt(20))
+		Expect(feeGrowthInside).To(Equal(big.NewInt(70)), "the growth outside of the range should be excluded")
+	})
+
+	It("measures the fee growth within a range above the current tick", func() {
+		feeGrowthInside := uniswap.FeeGrowthInside(-120, -60, 60, big.NewInt(100), big.NewInt(60), big.NewInt(20))
+		Expect(feeGrowthInside).To(Equal(big.NewInt(40)), "the growth below the lower tick should be measured from the other side")
+	})
+
+	It("earns fees across wrapped fee growth", func() {
+		feeGrowthInsideLast := new(big.Int).Sub(q256, big.NewInt(5))
+		liquidity := new(big.Int).Lsh(big.NewInt(1), 128)
+
+		Expect(uniswap.FeesEarned(liquidity, big.NewInt(5), feeGrowthInsideLast)).To(Equal(big.NewInt(10)), "the fee growth should wrap around as it does onchain")
+	})
+})
+
+// sqrtPowerOfBase computes sqrt(1.0001)^tick independently of the fixed-point math of the pool contracts.
+func sqrtPowerOfBase(tick int64) *big.Float {
+	base, _ := new(big.Float).SetPrec(256).SetString("1.0001")
+	base.Sqrt(base)
+
+	exponent := tick
+	if exponent < 0 {
+		exponent = -exponent
+	}
+
+	result := new(big.Float).SetPrec(256).SetInt64(1)
+	for ; exponent > 0; exponent >>= 1 {
+		if exponent&1 != 0 {
+			result.Mul(result, base)
+		}
+		base.Mul(base, base)
+	}
+
+	if tick < 0 {
+		result.Quo(new(big.Float).SetPrec(256).SetInt64(1), result)
+	}
+
+	return result
+}